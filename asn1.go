@@ -2,45 +2,14 @@ package asn1
 
 import (
 	"bytes"
-	"fmt"
+	"encoding/binary"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"strconv"
 )
 
-// ParseError is returned by the package to indicate that the given data is
-// invalid.
-type ParseError struct {
-	Msg string
-}
-
-// Error returns the error message of a ParseError.
-func (e *ParseError) Error() string {
-	return e.Msg
-}
-
-// parseError allocates a new ParseError.
-func parseError(msg string, args ...interface{}) *ParseError {
-	return &ParseError{fmt.Sprintf(msg, args...)}
-}
-
-// SyntaxError is returned by the package to indicate that the given value or
-// struct is invalid.
-type SyntaxError struct {
-	Msg string
-}
-
-// Error returns the error message of a SyntaxError.
-func (e *SyntaxError) Error() string {
-	return e.Msg
-}
-
-// syntaxError allocates a new ParseError,
-func syntaxError(msg string, args ...interface{}) *SyntaxError {
-	return &SyntaxError{fmt.Sprintf(msg, args...)}
-}
-
 // ASN.1 class tags.
 const (
 	ClassUniversal       ASNClass = 0x00
@@ -71,11 +40,31 @@ var (
 	TagExternal         ASNValue = 0x08
 	TagSequence         ASNValue = 0x10
 	TagSet              ASNValue = 0x11
+	TagNumericString    ASNValue = 0x12
 	TagPrintableString  ASNValue = 0x13
 	TagT61String        ASNValue = 0x14
 	TagIA5String        ASNValue = 0x16
 	TagVisibleString    ASNValue = 26
 	TagUTCTime          ASNValue = 0x17
+	TagReal             ASNValue = 0x09
+	TagUTF8String       ASNValue = 0x0c
+	TagGeneralizedTime  ASNValue = 0x18
+	TagGraphicString    ASNValue = 0x19
+	TagGeneralString    ASNValue = 0x1b
+	TagUniversalString  ASNValue = 0x1c
+	TagBMPString        ASNValue = 0x1e
+	TagEnumerated       ASNValue = 0x0a
+	TagEmbeddedPDV      ASNValue = 0x0b
+	TagRelativeOid      ASNValue = 0x0d
+	TagTime             ASNValue = 0x0e
+	TagVideotexString   ASNValue = 0x15
+	TagCharacterString  ASNValue = 0x1d
+	TagDate             ASNValue = 0x1f
+	TagTimeOfDay        ASNValue = 0x20
+	TagDateTime         ASNValue = 0x21
+	TagDuration         ASNValue = 0x22
+	TagOidIri           ASNValue = 0x23
+	TagRelativeOidIri   ASNValue = 0x24
 )
 
 // Internal consts
@@ -84,13 +73,28 @@ const (
 	intBytes = intBits / 8
 )
 
+// RawValue represents an undecoded ASN.1 value.
 type RawValue struct {
 	Tag         ASNTag
 	Constructed bool
 	Indefinite  bool
 	Content     []byte
+
+	// Offset of the identifier octets in the decoded input.
+	Offset int64
+	// HeaderLength is the number of identifier and length octets, zero for
+	// values that have not been decoded.
+	HeaderLength int
+}
+
+// ContentOffset returns the offset of the content octets in the decoded
+// input.
+func (raw *RawValue) ContentOffset() int64 {
+	return raw.Offset + int64(raw.HeaderLength)
 }
 
+// parseBigInt decodes the two's complement integer data, which must not be
+// empty.
 func parseBigInt(data []byte) *big.Int {
 	data = append([]byte{}, data...)
 	neg := false
@@ -107,7 +111,78 @@ func parseBigInt(data []byte) *big.Int {
 	return i
 }
 
-func (raw *RawValue) encode() ([]byte, error) {
+// encodeInt64 returns the minimal two's complement representation of n.
+func encodeInt64(n int64) []byte {
+	length := 1
+	for i := n; i > 127 || i < -128; i >>= 8 {
+		length++
+	}
+
+	data := make([]byte, length)
+	for i := range data {
+		shift := uint((length - i - 1) * 8)
+		data[i] = byte(n >> shift)
+	}
+	return data
+}
+
+// encodeUint64 returns the minimal two's complement representation of n.
+func encodeUint64(n uint64) []byte {
+	if n <= math.MaxInt64 {
+		return encodeInt64(int64(n))
+	}
+
+	data := make([]byte, 9)
+	binary.BigEndian.PutUint64(data[1:], n)
+	return data
+}
+
+// encodeBigInt returns the minimal two's complement representation of n.
+func encodeBigInt(n *big.Int) []byte {
+	if n.Sign() == 0 {
+		return []byte{0x00}
+	}
+
+	if n.Sign() > 0 {
+		data := n.Bytes()
+		if data[0]&0x80 != 0 {
+			data = append([]byte{0x00}, data...)
+		}
+		return data
+	}
+
+	// Negative numbers are encoded as the complement of |n| - 1
+	nMinus1 := new(big.Int).Neg(n)
+	nMinus1.Sub(nMinus1, big.NewInt(1))
+	data := nMinus1.Bytes()
+	for i := range data {
+		data[i] ^= 0xff
+	}
+	if len(data) == 0 || data[0]&0x80 == 0 {
+		data = append([]byte{0xff}, data...)
+	}
+	return data
+}
+
+// ParseInteger returns the value of data, the two's complement content
+// octets of an INTEGER or ENUMERATED value.
+func ParseInteger(data []byte) (*big.Int, error) {
+	if len(data) == 0 {
+		return nil, kindError(ErrInvalidLength, "empty integer")
+	}
+
+	return parseBigInt(data), nil
+}
+
+// EncodeInteger returns the minimal two's complement content octets of n.
+func EncodeInteger(n *big.Int) []byte {
+	return encodeBigInt(n)
+}
+
+// Encode returns the BER encoding of the raw value. The Content of a
+// constructed value must contain the encoded child values, indefinite length
+// values are terminated with an end-of-contents marker.
+func (raw *RawValue) Encode() ([]byte, error) {
 
 	if raw == nil {
 		return []byte{}, nil
@@ -220,48 +295,59 @@ func removeLeadingBytes(buf []byte, target byte) []byte {
 }
 
 func DecodeRawValue(reader io.Reader) (*RawValue, error) {
-	tag, constructed, err := decodeIdentifier(reader)
-	if err != nil {
-		return nil, err
+	return (&limiter{}).decode(reader)
+}
+
+// contentChunk is the size up to which content octets are read into a
+// buffer allocated up front.
+const contentChunk = 64 * 1024
+
+// readContent reads length content octets. Larger contents are read into a
+// growing buffer, so a bogus length does not allocate memory before the
+// octets are actually available.
+func readContent(reader io.Reader, length uint) ([]byte, error) {
+	if length <= contentChunk {
+		content := make([]byte, length)
+		if _, err := io.ReadFull(reader, content); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		return content, nil
 	}
 
-	length, indefinite, err := decodeLength(reader)
-	if err != nil {
-		return nil, err
+	if uint64(length) > math.MaxInt64 {
+		return nil, kindError(ErrInvalidLength, "length %d too big", length)
 	}
-	if indefinite && !constructed {
-		return nil, parseError("primitive node with indefinite length")
+
+	buffer := bytes.NewBuffer(make([]byte, 0, contentChunk))
+	if _, err := io.CopyN(buffer, reader, int64(length)); err != nil {
+		return nil, unexpectedEOF(err)
 	}
 
-	// Indefinite form
-	var content []byte
-	if !indefinite {
-		content = make([]byte, length)
-		_, err = io.ReadFull(reader, content)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		buffer := bytes.NewBuffer([]byte{})
-		childrenReader := io.TeeReader(reader, buffer)
-		err := readEoc(childrenReader)
-		if err != nil {
-			return nil, err
-		}
-		// At this point, buffer also contains the EoC bytes
-		content = buffer.Bytes()
-		content = content[:len(content)-2]
+	return buffer.Bytes(), nil
+}
+
+// readIndefinite reads the content octets of an indefinite length value,
+// without the end-of-contents octets.
+func readIndefinite(reader io.Reader) ([]byte, error) {
+	buffer := bytes.NewBuffer([]byte{})
+	if err := readEoc(io.TeeReader(reader, buffer)); err != nil {
+		return nil, err
 	}
 
-	raw := RawValue{tag, constructed, indefinite, content}
-	return &raw, nil
+	// At this point, buffer also contains the EoC bytes
+	content := buffer.Bytes()
+	return content[:len(content)-2], nil
 }
 
+// readEoc reads up to and including the end-of-contents octets of an
+// indefinite length value. Nested indefinite length values are counted
+// instead of recursed into, so deep nesting cannot exhaust the stack.
 func readEoc(reader io.Reader) error {
-	for {
+	for open := 1; open > 0; {
 		tag, constructed, err := decodeIdentifier(reader)
 		if err != nil {
-			return err
+			return unexpectedEOF(err)
 		}
 
 		length, indefinite, err := decodeLength(reader)
@@ -270,25 +356,63 @@ func readEoc(reader io.Reader) error {
 		}
 
 		if indefinite && !constructed {
-			return parseError("primitive node with indefinite length")
+			return kindError(ErrInvalidLength, "primitive node with indefinite length")
 		}
 
 		if tag.Class == 0 && tag.Value == 0 && indefinite == false && length == 0 {
-			break
+			open--
+			continue
 		}
 
 		if indefinite {
-			err = readEoc(reader)
-		} else {
-			err = skipBytes(reader, int64(length))
+			open++
+			continue
 		}
-		if err != nil {
-			return err
+
+		if uint64(length) > math.MaxInt64 {
+			return kindError(ErrInvalidLength, "length %d too big", length)
+		}
+
+		if err := skipBytes(reader, int64(length)); err != nil {
+			return unexpectedEOF(err)
 		}
 	}
+
 	return nil
 }
 
+// headerCounter counts the identifier and length octets read.
+type headerCounter struct {
+	r io.Reader
+	n int
+}
+
+func (c *headerCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func (c *headerCounter) ReadByte() (byte, error) {
+	b, err := readByte(c.r)
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
+
+// decodeHeader reads the identifier and length octets of a value, n is the
+// number of octets read.
+func decodeHeader(reader io.Reader) (tag ASNTag, constructed bool, length uint, indefinite bool, n int, err error) {
+	counter := &headerCounter{r: reader}
+
+	if tag, constructed, err = decodeIdentifier(counter); err == nil {
+		length, indefinite, err = decodeLength(counter)
+	}
+
+	return tag, constructed, length, indefinite, counter.n, err
+}
+
 func decodeMultiByteTag(reader io.Reader) (uint, error) {
 	// Tag is encoded in one or more following bytes
 	tag := uint(0)
@@ -296,7 +420,7 @@ func decodeMultiByteTag(reader io.Reader) (uint, error) {
 		// Read a byte
 		b, err := readByte(reader)
 		if err != nil {
-			return 0, err
+			return 0, unexpectedEOF(err)
 		}
 		// if we need to shift out non zeros bits, so the tag is too big for an uint
 		msb := uint64(0xfe) << (intBits - 8) // 7 most significant bits
@@ -347,6 +471,7 @@ func decodeLength(reader io.Reader) (length uint, indefinite bool, err error) {
 	// Read length
 	b, err := readByte(reader)
 	if err != nil {
+		err = unexpectedEOF(err)
 		return
 	}
 
@@ -364,18 +489,19 @@ func decodeLength(reader io.Reader) (length uint, indefinite bool, err error) {
 
 	// Long form
 	if b == 0xff {
-		err = parseError("invalid number of length octets: %x", b)
+		err = kindError(ErrInvalidLength, "invalid number of length octets: %x", b)
 		return
 	}
 	octets := make([]byte, int(b&0x7f))
 	_, err = io.ReadFull(reader, octets)
 	if err != nil {
+		err = unexpectedEOF(err)
 		return
 	}
 	for _, b = range octets {
 		msb := uint64(0xff) << (intBits - 8)
 		if uint64(length)&msb != 0 {
-			err = parseError("multi byte length too big")
+			err = kindError(ErrInvalidLength, "multi byte length too big")
 			return
 		}
 		length = (length << 8) | uint(b)
@@ -384,7 +510,79 @@ func decodeLength(reader io.Reader) (length uint, indefinite bool, err error) {
 	return
 }
 
+// joinSegments returns the content octets of a primitive or constructed
+// string value. The segments of a constructed value must have the universal
+// tag segment and are concatenated.
+func joinSegments(raw *RawValue, segment ASNValue) ([]byte, error) {
+	if !raw.Constructed {
+		return raw.Content, nil
+	}
+
+	children, err := raw.ChildValues()
+	if err != nil {
+		return nil, err
+	}
+
+	content := []byte{}
+	for _, child := range children {
+		if child.Tag != Tag(ClassUniversal, segment) {
+			return nil, kindError(ErrTagMismatch, "unexpected segment %s in constructed string %s", child.Tag, raw.Tag)
+		}
+
+		data, err := joinSegments(child, segment)
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, data...)
+	}
+
+	return content, nil
+}
+
+// joinBitStringSegments returns the content octets of a primitive or
+// constructed BIT STRING. Only the last segment may contain unused bits.
+func joinBitStringSegments(raw *RawValue) ([]byte, error) {
+	if !raw.Constructed {
+		return raw.Content, nil
+	}
+
+	children, err := raw.ChildValues()
+	if err != nil {
+		return nil, err
+	}
+
+	content := []byte{0x00}
+	for i, child := range children {
+		if child.Tag != Tag(ClassUniversal, TagBitString) {
+			return nil, kindError(ErrTagMismatch, "unexpected segment %s in constructed BIT STRING", child.Tag)
+		}
+
+		data, err := joinBitStringSegments(child)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) == 0 {
+			return nil, kindError(ErrInvalidLength, "zero length BIT STRING segment")
+		}
+
+		if data[0] != 0 && i != len(children)-1 {
+			return nil, parseError("unused bits in BIT STRING segment other than the last")
+		}
+
+		content[0] = data[0]
+		content = append(content, data[1:]...)
+	}
+
+	return content, nil
+}
+
 func readByte(reader io.Reader) (byte, error) {
+	if r, ok := reader.(io.ByteReader); ok {
+		return r.ReadByte()
+	}
+
 	buf := []byte{0x00}
 	_, err := io.ReadFull(reader, buf)
 	return buf[0], err
@@ -0,0 +1,321 @@
+package asn1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// cerSegmentSize is the maximum number of content octets of a primitive
+// string value in CER, longer strings are encoded in segments of this size.
+const cerSegmentSize = 1000
+
+// CERError is returned when the data is valid BER, but violates one of the
+// restrictions of the Canonical Encoding Rules.
+type CERError struct {
+	// Rule is the clause of X.690 that has been violated, eg. "9.1".
+	Rule string
+	Tag  ASNTag
+	Msg  string
+}
+
+// Error returns the error message of a CERError.
+func (e *CERError) Error() string {
+	return fmt.Sprintf("cer: %s %s (X.690 %s)", e.Tag, e.Msg, e.Rule)
+}
+
+// cerError allocates a new CERError.
+func cerError(rule string, tag ASNTag, msg string, args ...interface{}) error {
+	return &CERError{
+		Rule: rule,
+		Tag:  tag,
+		Msg:  fmt.Sprintf(msg, args...),
+	}
+}
+
+// DecodeCER reads a value like DecodeRawValue, but verifies that the value
+// and all values contained in it are CER encoded. A *CERError is returned
+// when a CER restriction has been violated.
+func DecodeCER(reader io.Reader) (*RawValue, error) {
+	return decodeCERValue(reader, &limiter{})
+}
+
+// decodeCERValue reads and verifies a top level CER encoded value, the value
+// and the values nested in it are accounted in l.
+func decodeCERValue(reader io.Reader, l *limiter) (*RawValue, error) {
+	raw, eoc, err := decodeCER(reader, 0, l, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if eoc {
+		return nil, errorAt(parseError("unexpected end-of-contents"), 0)
+	}
+
+	return raw, nil
+}
+
+// decodeCER reads and verifies a single CER encoded value at offset of the
+// decoded input, accounting for the values in l. The eoc flag is set when the
+// end-of-contents marker has been read.
+func decodeCER(reader io.Reader, offset int64, l *limiter, depth int) (raw *RawValue, eoc bool, err error) {
+	header := bytes.NewBuffer([]byte{})
+	headerReader := io.TeeReader(reader, header)
+
+	tag, constructed, err := decodeIdentifier(headerReader)
+	if err != nil {
+		return nil, false, errorAt(err, offset)
+	}
+
+	identifierLength := header.Len()
+
+	length, indefinite, err := decodeLength(headerReader)
+	if err != nil {
+		return nil, false, errorAt(err, offset)
+	}
+
+	if tag == Tag(ClassUniversal, TagEoc) && !constructed && !indefinite && length == 0 {
+		return nil, true, nil
+	}
+
+	if err := l.element(depth, length); err != nil {
+		return nil, false, err
+	}
+
+	raw = &RawValue{
+		Tag:          tag,
+		Constructed:  constructed,
+		Indefinite:   indefinite,
+		Offset:       offset,
+		HeaderLength: header.Len(),
+	}
+
+	identifier, err := encodeIdentifier(raw)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !bytes.Equal(identifier, header.Bytes()[:identifierLength]) {
+		return nil, false, cerError("8.1.2", tag, "tag number is not encoded in the minimum number of octets")
+	}
+
+	if constructed && !indefinite {
+		return nil, false, cerError("9.1", tag, "constructed value does not use the indefinite length form")
+	}
+
+	if !constructed {
+		if indefinite {
+			return nil, false, errorAt(kindError(ErrInvalidLength, "primitive node with indefinite length"), offset)
+		}
+
+		if !bytes.Equal(encodeLength(length), header.Bytes()[identifierLength:]) {
+			return nil, false, cerError("9.1", tag, "length is not encoded in the minimum number of octets")
+		}
+
+		if raw.Content, err = readContent(reader, length); err != nil {
+			return nil, false, errorAt(err, offset)
+		}
+	} else {
+		raw.Content = []byte{}
+
+		for {
+			next := raw.ContentOffset() + int64(len(raw.Content))
+
+			child, eoc, err := decodeCER(reader, next, l, depth+1)
+			if err != nil {
+				return nil, false, errorAt(unexpectedEOF(err), next)
+			}
+
+			if eoc {
+				break
+			}
+
+			data, err := child.Encode()
+			if err != nil {
+				return nil, false, err
+			}
+
+			raw.Content = append(raw.Content, data...)
+		}
+	}
+
+	if raw.Tag.Class != ClassUniversal {
+		return raw, false, nil
+	}
+
+	if err := checkCER(raw.Tag.Value, raw); err != nil {
+		return nil, false, err
+	}
+
+	return raw, false, nil
+}
+
+// checkCER verifies that the content of raw is a CER encoded value of the
+// universal type tag. The identifier and length octets and the children of
+// constructed values have already been verified.
+func checkCER(tag ASNValue, raw *RawValue) error {
+	if !isStringTag(tag) {
+		return checkCanonical(tag, raw, cerError)
+	}
+
+	if !raw.Constructed {
+		if len(raw.Content) > cerSegmentSize {
+			return cerError("9.2", raw.Tag, "string of %d octets is not segmented", len(raw.Content))
+		}
+
+		return checkCanonical(tag, raw, cerError)
+	}
+
+	segment := TagOctetString
+	if tag == TagBitString {
+		segment = TagBitString
+	}
+
+	children, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	if len(children) < 2 {
+		return cerError("9.2", raw.Tag, "string of at most %d octets is segmented", cerSegmentSize)
+	}
+
+	for i, child := range children {
+		if child.Tag != Tag(ClassUniversal, segment) || child.Constructed {
+			return cerError("9.2", raw.Tag, "segment %s is not a primitive %s", child.Tag, Tag(ClassUniversal, segment))
+		}
+
+		if i < len(children)-1 && len(child.Content) != cerSegmentSize {
+			return cerError("9.2", raw.Tag, "segment %d does not contain %d octets", i, cerSegmentSize)
+		}
+
+		if segment == TagBitString && i < len(children)-1 && child.Content[0] != 0 {
+			return cerError("8.6.4", raw.Tag, "segment %d other than the last has unused bits", i)
+		}
+
+		if err := checkCanonical(segment, child, cerError); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ToCER converts a BER encoded value into its canonical CER form. In
+// addition to the canonicalization of ToDER, constructed values use the
+// indefinite length form and strings longer than 1000 octets are segmented.
+func ToCER(raw *RawValue) (*RawValue, error) {
+	der, err := ToDER(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return derToCER(der)
+}
+
+// derToCER converts a DER encoded value into its CER form.
+func derToCER(raw *RawValue) (*RawValue, error) {
+	universal := raw.Tag.Class == ClassUniversal
+
+	if !raw.Constructed {
+		if !universal || !isStringTag(raw.Tag.Value) || len(raw.Content) <= cerSegmentSize {
+			return raw, nil
+		}
+
+		return segmentString(raw)
+	}
+
+	children, err := raw.ChildValues()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, child := range children {
+		if children[i], err = derToCER(child); err != nil {
+			return nil, err
+		}
+	}
+
+	// The order of SET OF components depends on the CER encoding
+	if universal && raw.Tag.Value == TagSet {
+		sortSet(children)
+	}
+
+	content := []byte{}
+	for _, child := range children {
+		data, err := child.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, data...)
+	}
+
+	return &RawValue{
+		Tag:         raw.Tag,
+		Constructed: true,
+		Indefinite:  true,
+		Content:     content,
+	}, nil
+}
+
+// segmentString encodes a primitive string value as a constructed value
+// with segments of 1000 octets.
+func segmentString(raw *RawValue) (*RawValue, error) {
+	segment := TagOctetString
+	data := raw.Content
+	size := cerSegmentSize
+
+	var paddingBits byte
+	if raw.Tag.Value == TagBitString {
+		// The initial octet is part of each segment
+		segment = TagBitString
+		paddingBits = data[0]
+		data = data[1:]
+		size--
+	}
+
+	content := []byte{}
+	for len(data) > 0 {
+		n := size
+		if n > len(data) {
+			n = len(data)
+		}
+
+		child := &RawValue{
+			Tag:     Tag(ClassUniversal, segment),
+			Content: data[:n],
+		}
+
+		if segment == TagBitString {
+			initial := byte(0)
+			if n == len(data) {
+				initial = paddingBits
+			}
+
+			child.Content = append([]byte{initial}, data[:n]...)
+		}
+
+		encoded, err := child.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, encoded...)
+		data = data[n:]
+	}
+
+	return &RawValue{
+		Tag:         raw.Tag,
+		Constructed: true,
+		Indefinite:  true,
+		Content:     content,
+	}, nil
+}
+
+// MarshalCER returns the CER encoding of v, see Marshal.
+func MarshalCER(v interface{}) ([]byte, error) {
+	ctx := NewContext()
+	ctx.SetRules(CER, BER)
+	return ctx.Marshal(v)
+}
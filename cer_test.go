@@ -0,0 +1,113 @@
+package asn1_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestDecodeCER(t *testing.T) {
+	var tests = []struct {
+		in   string
+		rule string
+	}{
+		{in: "3080020101 0101ff 0000", rule: ""},
+		{in: "3080 3080 0000 0000", rule: ""},
+		{in: "3003020101", rule: "9.1"},
+		{in: "0481020102", rule: "9.1"},
+		{in: "3080 0101 01 0000", rule: "11.1"},
+		{in: "3080 02020001 0000", rule: "8.3.2"},
+		{in: "2480 0401 01 0000", rule: "9.2"},
+		{in: "3180 0201ff 020101 0000", rule: "11.6"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		_, err := asn1.DecodeCER(bytes.NewReader(data))
+		if tt.rule == "" {
+			if err != nil {
+				t.Errorf("%d. %s: unexpected error: %s", i, tt.in, err)
+			}
+			continue
+		}
+
+		cerErr, ok := err.(*asn1.CERError)
+		if !ok {
+			t.Errorf("%d. %s: expected CERError, got %v", i, tt.in, err)
+		} else if cerErr.Rule != tt.rule {
+			t.Errorf("%d. %s: rule mismatch: exp=%s got=%s", i, tt.in, tt.rule, cerErr.Rule)
+		}
+	}
+}
+
+func TestToCER(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{in: "3003020101", out: "3080020101 0000"},
+		{in: "a0053003 0101ff", out: "a080 3080 0101ff 0000 0000"},
+		{in: "0403 010203", out: "0403 010203"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		raw, err := asn1.DecodeRawValue(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		cer, err := asn1.ToCER(raw)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		out, err := cer.Encode()
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if hex.EncodeToString(out) != stripSpaces(tt.out) {
+			t.Errorf("%d. output mismatch: exp=%s got=%x", i, stripSpaces(tt.out), out)
+		}
+	}
+}
+
+func TestMarshalCER_Segments(t *testing.T) {
+	var tests = []interface{}{
+		[]byte(strings.Repeat("a", 2500)),
+		asn1.BitString{Bytes: bytes.Repeat([]byte{0xf0}, 1500), BitLength: 1500*8 - 4},
+		asn1.NewPrintableString(strings.Repeat("b", 1000)),
+	}
+
+	for i, v := range tests {
+		data, err := asn1.MarshalCER(v)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if _, err := asn1.DecodeCER(bytes.NewReader(data)); err != nil {
+			t.Errorf("%d. output is not CER: %s", i, err)
+		}
+
+		raw, err := asn1.DecodeRawValue(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		der, err := asn1.ToDER(raw)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		expected, _ := asn1.Marshal(v)
+		if out, _ := der.Encode(); !bytes.Equal(out, expected) {
+			t.Errorf("%d. segments do not reassemble to the original value", i)
+		}
+	}
+}
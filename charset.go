@@ -0,0 +1,192 @@
+package asn1
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// stringType describes the character set of a restricted character string
+// type (X.680 41).
+type stringType struct {
+	name string
+	// valid returns true for the characters of the permitted alphabet, nil
+	// when the type is not restricted to single octet characters.
+	valid func(r rune) bool
+}
+
+// stringTypes contains the restricted character string types that are
+// validated or transcoded. GraphicString, GeneralString and
+// ObjectDescriptor use ISO 2022 escape sequences and are not validated.
+var stringTypes = map[ASNValue]stringType{
+	TagNumericString:   {"NumericString", isNumeric},
+	TagPrintableString: {"PrintableString", isPrintable},
+	TagIA5String:       {"IA5String", func(r rune) bool { return r < 0x80 }},
+	TagVisibleString:   {"VisibleString", func(r rune) bool { return r >= 0x20 && r < 0x7f }},
+	TagUTF8String:      {"UTF8String", nil},
+	TagT61String:       {"T61String", nil},
+	TagBMPString:       {"BMPString", nil},
+	TagUniversalString: {"UniversalString", nil},
+}
+
+// isNumeric returns true for the characters of NumericString (X.680 41.2).
+func isNumeric(r rune) bool {
+	return r >= '0' && r <= '9' || r == ' '
+}
+
+// isPrintable returns true for the characters of PrintableString (X.680
+// 41.4).
+func isPrintable(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+
+	return strings.ContainsRune(" '()+,-./:=?", r)
+}
+
+// decodeString returns the Go string of the content octets of a value of the
+// universal character string type tag. A *ParseError of kind ErrConstraint
+// is returned for characters outside the alphabet of the type, unless lax is
+// set: octets of single octet types are then kept as they are and characters
+// that cannot be decoded are replaced by U+FFFD.
+func decodeString(tag ASNValue, data []byte, lax bool) (string, error) {
+	st, ok := stringTypes[tag]
+	if !ok {
+		return string(data), nil
+	}
+
+	switch tag {
+	case TagUTF8String:
+		if !lax && !utf8.Valid(data) {
+			return "", kindError(ErrConstraint, "invalid UTF-8 in UTF8String")
+		}
+
+		return string(data), nil
+	case TagT61String:
+		return decodeT61(data, lax)
+	case TagBMPString:
+		if len(data)%2 != 0 {
+			return "", kindError(ErrInvalidLength, "invalid BMPString length: %d", len(data))
+		}
+
+		runes := make([]rune, len(data)/2)
+		for i := range runes {
+			r := rune(binary.BigEndian.Uint16(data[2*i:]))
+			if r >= 0xd800 && r < 0xe000 {
+				if !lax {
+					return "", kindError(ErrConstraint, "invalid character %U in BMPString", r)
+				}
+
+				r = utf8.RuneError
+			}
+
+			runes[i] = r
+		}
+
+		return string(runes), nil
+	case TagUniversalString:
+		if len(data)%4 != 0 {
+			return "", kindError(ErrInvalidLength, "invalid UniversalString length: %d", len(data))
+		}
+
+		runes := make([]rune, len(data)/4)
+		for i := range runes {
+			r := rune(binary.BigEndian.Uint32(data[4*i:]))
+			if !utf8.ValidRune(r) {
+				if !lax {
+					return "", kindError(ErrConstraint, "invalid character %U in UniversalString", r)
+				}
+
+				r = utf8.RuneError
+			}
+
+			runes[i] = r
+		}
+
+		return string(runes), nil
+	}
+
+	for _, b := range data {
+		if !lax && !st.valid(rune(b)) {
+			return "", kindError(ErrConstraint, "invalid character %q in %s", b, st.name)
+		}
+	}
+
+	return string(data), nil
+}
+
+// encodeString returns the content octets of s as a value of the universal
+// character string type tag. A *SyntaxError of kind ErrConstraint is
+// returned for characters outside the alphabet of the type.
+func encodeString(tag ASNValue, s string) ([]byte, error) {
+	st, ok := stringTypes[tag]
+	if !ok {
+		return []byte(s), nil
+	}
+
+	invalid := func(r rune) error {
+		return &SyntaxError{
+			Msg: fmt.Sprintf("invalid character %q for %s", r, st.name),
+			Err: ErrConstraint,
+		}
+	}
+
+	if !utf8.ValidString(s) {
+		return nil, &SyntaxError{Msg: "invalid UTF-8 for " + st.name, Err: ErrConstraint}
+	}
+
+	switch tag {
+	case TagUTF8String:
+		return []byte(s), nil
+	case TagT61String:
+		data, r, ok := encodeT61(s)
+		if !ok {
+			return nil, invalid(r)
+		}
+
+		return data, nil
+	case TagBMPString:
+		data := make([]byte, 0, 2*len(s))
+		for _, r := range s {
+			if r > 0xffff {
+				return nil, invalid(r)
+			}
+
+			data = append(data, byte(r>>8), byte(r))
+		}
+
+		return data, nil
+	case TagUniversalString:
+		data := make([]byte, 0, 4*len(s))
+		for _, r := range s {
+			data = append(data, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+		}
+
+		return data, nil
+	}
+
+	for _, r := range s {
+		if !st.valid(r) {
+			return nil, invalid(r)
+		}
+	}
+
+	return []byte(s), nil
+}
+
+// stringValue is implemented by the character string types that are
+// validated, so that a Context can decode them with its string validation.
+type stringValue interface {
+	setString(s string)
+}
+
+func (s *NumericString) setString(v string)   { s.string = v }
+func (s *PrintableString) setString(v string) { s.string = v }
+func (s *IA5String) setString(v string)       { s.string = v }
+func (s *VisibleString) setString(v string)   { s.string = v }
+func (s *UTF8String) setString(v string)      { s.string = v }
+func (s *T61String) setString(v string)       { s.string = v }
+func (s *BMPString) setString(v string)       { s.string = v }
+func (s *UniversalString) setString(v string) { s.string = v }
@@ -0,0 +1,104 @@
+package asn1_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestStrings(t *testing.T) {
+	var tests = []struct {
+		in  string
+		v   interface{}
+		out string
+	}{
+		{in: "130c 48656c6c6f2c20576f726c64", v: new(asn1.PrintableString), out: "Hello, World"},
+		{in: "1603 612a40", v: new(asn1.IA5String), out: "a*@"},
+		{in: "1a03 617e7a", v: new(asn1.VisibleString), out: "a~z"},
+		{in: "0c04 c3a96162", v: new(asn1.UTF8String), out: "éab"},
+		{in: "1405 4d c2 65 a4 31", v: new(asn1.T61String), out: "Mé$1"},
+		{in: "1406 c3 20 cf 73 f8 fb", v: new(asn1.T61String), out: "^šłß"},
+		{in: "1e06 00 41 00 e9 20 ac", v: new(asn1.BMPString), out: "Aé€"},
+		{in: "1c08 00000041 0001f600", v: new(asn1.UniversalString), out: "A😀"},
+		{in: "1e04 0041 00e9", v: new(string), out: "Aé"},
+		{in: "1403 c26561", v: new(string), out: "éa"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		if err := asn1.Unmarshal(data, tt.v); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		var s string
+		switch v := tt.v.(type) {
+		case *string:
+			if *v != tt.out {
+				t.Errorf("%d. value mismatch: exp=%q got=%q", i, tt.out, *v)
+			}
+			continue
+		case interface{ String() string }:
+			s = v.String()
+		}
+
+		if s != tt.out {
+			t.Errorf("%d. value mismatch: exp=%q got=%q", i, tt.out, s)
+		}
+
+		out, err := asn1.Marshal(tt.v)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+			t.Errorf("%d. encoding mismatch: exp=%x got=%x", i, data, out)
+		}
+	}
+}
+
+func TestStrings_Errors(t *testing.T) {
+	var tests = []struct {
+		in   string
+		v    interface{}
+		kind error
+	}{
+		{in: "1301 2a", v: new(asn1.PrintableString), kind: asn1.ErrConstraint},
+		{in: "1301 40", v: new(string), kind: asn1.ErrConstraint},
+		{in: "1601 80", v: new(asn1.IA5String), kind: asn1.ErrConstraint},
+		{in: "1a01 0a", v: new(asn1.VisibleString), kind: asn1.ErrConstraint},
+		{in: "0c01 ff", v: new(asn1.UTF8String), kind: asn1.ErrConstraint},
+		{in: "1401 24", v: new(asn1.T61String), kind: asn1.ErrConstraint},
+		{in: "1401 c2", v: new(asn1.T61String), kind: asn1.ErrConstraint},
+		{in: "1402 c2 78", v: new(asn1.T61String), kind: asn1.ErrConstraint},
+		{in: "1e03 004100", v: new(asn1.BMPString), kind: asn1.ErrInvalidLength},
+		{in: "1e02 d800", v: new(asn1.BMPString), kind: asn1.ErrConstraint},
+		{in: "1c04 00110000", v: new(asn1.UniversalString), kind: asn1.ErrConstraint},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		if err := asn1.Unmarshal(data, tt.v); !errors.Is(err, tt.kind) {
+			t.Errorf("%d. expected error of kind %v, got %v", i, tt.kind, err)
+		}
+	}
+
+	for i, v := range []interface{}{
+		asn1.NewPrintableString("a@b"),
+		asn1.NewIA5String("é"),
+		asn1.NewVisibleString("a\tb"),
+		asn1.NewT61String("{}"),
+		asn1.NewT61String("€"),
+		asn1.NewBMPString("😀"),
+		asn1.NewUTF8String("\xff"),
+	} {
+		_, err := asn1.Marshal(v)
+
+		var se *asn1.SyntaxError
+		if !errors.As(err, &se) || !errors.Is(err, asn1.ErrConstraint) {
+			t.Errorf("%d. expected *SyntaxError of kind ErrConstraint, got %v", i, err)
+		}
+	}
+}
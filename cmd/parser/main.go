@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strings"
 
+	asn1 "github.com/dutchsec/asn1"
 	asn1parser "github.com/dutchsec/asn1/parser"
 	"github.com/fatih/color"
 
@@ -48,6 +49,72 @@ func VersionAction(c *cli.Context) {
 	fmt.Println(color.YellowString(fmt.Sprintf("asn1 scheme parser")))
 }
 
+func DERAction(c *cli.Context) error {
+	if c.NArg() == 0 {
+		return cli.NewExitError(color.RedString("[!] No input file set"), 1)
+	}
+
+	r, err := os.Open(c.Args().First())
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	defer r.Close()
+
+	raw, err := asn1.DecodeRawValue(r)
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	var der *asn1.RawValue
+
+	if scheme := c.String("scheme"); scheme == "" {
+		der, err = asn1.ToDER(raw)
+	} else {
+		der, err = schemeToDER(scheme, c.String("type"), raw)
+	}
+
+	if err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	w := os.Stdout
+	if output := c.String("output"); output != "" {
+		w, err = os.Create(output)
+		if err != nil {
+			return cli.NewExitError(err.Error(), 1)
+		}
+
+		defer w.Close()
+	}
+
+	if err := asn1.NewEncoder(w).Encode(der); err != nil {
+		return cli.NewExitError(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func schemeToDER(scheme string, typeName string, raw *asn1.RawValue) (*asn1.RawValue, error) {
+	if typeName == "" {
+		return nil, fmt.Errorf("no type set for scheme %s", scheme)
+	}
+
+	f, err := os.Open(scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	definition, err := asn1parser.NewParser(f).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	return definition.ToDER(typeName, raw)
+}
+
 func New() *cmd {
 	app := cli.NewApp()
 	app.Name = "asn1 scheme parser"
@@ -61,6 +128,26 @@ func New() *cmd {
 			Name:   "version",
 			Action: VersionAction,
 		},
+		{
+			Name:      "der",
+			Usage:     "convert BER encoded data to DER",
+			ArgsUsage: "input",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "scheme",
+					Usage: "asn1 scheme used to omit DEFAULT values",
+				},
+				cli.StringFlag{
+					Name:  "type",
+					Usage: "type of the input in the asn1 scheme",
+				},
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "output file, defaults to stdout",
+				},
+			},
+			Action: DERAction,
+		},
 	}
 
 	app.Before = func(c *cli.Context) error {
@@ -0,0 +1,227 @@
+package asn1
+
+import (
+	"bytes"
+	"reflect"
+	"time"
+)
+
+// EncodingRules selects the encoding rules used by a Context.
+type EncodingRules int
+
+// Encoding rules of X.690.
+const (
+	// BER are the Basic Encoding Rules.
+	BER EncodingRules = iota
+	// CER are the Canonical Encoding Rules, see ToCER.
+	CER
+	// DER are the Distinguished Encoding Rules, see ToDER.
+	DER
+)
+
+// ExtensionMode selects how a Context unmarshals the components of a
+// SEQUENCE or SET that have no matching struct field, eg. the extension
+// additions of a newer version of the type.
+type ExtensionMode int
+
+// Extension modes.
+const (
+	// RejectExtensions fails with ErrUnparsedObjects.
+	RejectExtensions ExtensionMode = iota
+	// IgnoreExtensions drops the components.
+	IgnoreExtensions
+	// KeepExtensions stores the components in the []RawValue field of the
+	// struct with the extensions option, they are dropped when the struct
+	// has no such field. The field is marshaled after the other fields.
+	KeepExtensions
+)
+
+// Context keeps the state used while marshaling and unmarshaling Go values.
+// The zero value is not valid, use NewContext.
+type Context struct {
+	rules struct {
+		encoding EncodingRules
+		decoding EncodingRules
+	}
+
+	limits     Limits
+	types      *TypeRegistry
+	extensions ExtensionMode
+
+	// pivot is the first year of the century of two-digit UTCTime years.
+	pivot int
+	// location of decoded times, nil keeps the time difference of the value.
+	location *time.Location
+
+	// laxStrings accepts characters outside the alphabet of string types.
+	laxStrings bool
+}
+
+// NewContext allocates a new Context with the default settings: BER,
+// DefaultTypeRegistry, no limits, rejected extensions, DefaultUTCTimePivot
+// and strict string validation.
+func NewContext() *Context {
+	return &Context{
+		types: DefaultTypeRegistry,
+		pivot: DefaultUTCTimePivot,
+	}
+}
+
+// SetRules selects the encoding rules for encoding and decoding. When
+// encoding with CER or DER, the encoding is converted to its canonical form.
+// When decoding, a *CERError or *DERError is returned for data that violates
+// the rules.
+func (ctx *Context) SetRules(encoding, decoding EncodingRules) {
+	ctx.rules.encoding = encoding
+	ctx.rules.decoding = decoding
+}
+
+// SetDER enables the Distinguished Encoding Rules for encoding and decoding,
+// see SetRules.
+func (ctx *Context) SetDER(encoding bool, decoding bool) {
+	rules := func(der bool) EncodingRules {
+		if der {
+			return DER
+		}
+
+		return BER
+	}
+
+	ctx.SetRules(rules(encoding), rules(decoding))
+}
+
+// SetLimits bounds the resources used to unmarshal untrusted data. The data
+// is checked against limits while it is read, before the content octets of a
+// value are read, a *LimitError is returned when it exceeds one of the
+// limits.
+func (ctx *Context) SetLimits(limits Limits) {
+	ctx.limits = limits
+}
+
+// SetTypeRegistry sets the registry of the Go types that values are
+// unmarshaled into when the Go type is not known, nil disables the lookups.
+func (ctx *Context) SetTypeRegistry(types *TypeRegistry) {
+	ctx.types = types
+}
+
+// SetExtensions selects how components without matching struct field are
+// unmarshaled.
+func (ctx *Context) SetExtensions(mode ExtensionMode) {
+	ctx.extensions = mode
+}
+
+// SetUTCTimePivot sets the first year of the century that the two-digit
+// years of UTCTime values are mapped onto when unmarshaling into a
+// time.Time. Times in this century are marshaled as UTCTime, other times as
+// GeneralizedTime.
+func (ctx *Context) SetUTCTimePivot(pivot int) {
+	ctx.pivot = pivot
+}
+
+// SetTimeLocation sets the location that unmarshaled times are converted to,
+// and the location of GeneralizedTime values without time difference. With
+// a nil location (the default) times keep the time difference of the value
+// and GeneralizedTime values without time difference are local time.
+func (ctx *Context) SetTimeLocation(loc *time.Location) {
+	ctx.location = loc
+}
+
+// SetStrictStrings selects whether unmarshaling fails on characters outside
+// the alphabet of a string type (the default). Without strict validation,
+// octets of restricted strings are kept as they are, and characters that
+// cannot be decoded are replaced by U+FFFD. Marshaling always validates.
+func (ctx *Context) SetStrictStrings(strict bool) {
+	ctx.laxStrings = !strict
+}
+
+// Marshal returns the BER encoding of v.
+//
+// Structs are encoded as a SEQUENCE, slices as SEQUENCE OF and []byte as an
+// OCTET STRING. The types of this package (Integer, BitString, ObjectIdentifier, ...) are
+// encoded as their respective ASN.1 type. The tagging of struct fields is
+// controlled with the `asn1:"..."` struct tag, see fieldOptions.
+func Marshal(v interface{}) ([]byte, error) {
+	return NewContext().Marshal(v)
+}
+
+// Unmarshal parses the BER encoded data and stores the result in the value
+// pointed to by v. ErrUnparsedObjects is returned when data contains more
+// than one value. The decoded values, like []byte fields and RawValues, do
+// not share memory with data, use ParseBytes to walk data without copying.
+func Unmarshal(data []byte, v interface{}) error {
+	return NewContext().Unmarshal(data, v)
+}
+
+// Marshal returns the encoding of v using the encoding rules of ctx.
+func (ctx *Context) Marshal(v interface{}) ([]byte, error) {
+	value := reflect.ValueOf(v)
+
+	raw, err := ctx.encodeValue(value, &fieldOptions{})
+	if err != nil {
+		return nil, typePath(err, value)
+	}
+
+	if raw == nil {
+		return nil, syntaxError("cannot marshal nil value")
+	}
+
+	switch ctx.rules.encoding {
+	case CER:
+		raw, err = ToCER(raw)
+	case DER:
+		raw, err = ToDER(raw)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return raw.Encode()
+}
+
+// Unmarshal parses the data, encoded using the encoding rules of ctx, and
+// stores the result in the value pointed to by v. The decoded values do not
+// share memory with data.
+func (ctx *Context) Unmarshal(data []byte, v interface{}) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return syntaxError("unmarshal requires a non-nil pointer, got %T", v)
+	}
+
+	reader := bytes.NewReader(data)
+	l, limited := newLimiter(reader, ctx.limits)
+
+	var raw *RawValue
+	var err error
+
+	switch ctx.rules.decoding {
+	case CER:
+		raw, err = decodeCERValue(limited, l)
+	case DER:
+		raw, err = decodeDER(limited, l)
+	default:
+		raw, err = l.decode(limited)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.decodeValue(raw, value.Elem(), &fieldOptions{}); err != nil {
+		return typePath(err, value.Elem())
+	}
+
+	if reader.Len() > 0 {
+		return ErrUnparsedObjects
+	}
+
+	return nil
+}
+
+// UnmarshalDER parses the DER encoded data and stores the result in the value
+// pointed to by v. A *DERError is returned when data is not DER encoded.
+func UnmarshalDER(data []byte, v interface{}) error {
+	ctx := NewContext()
+	ctx.SetDER(false, true)
+	return ctx.Unmarshal(data, v)
+}
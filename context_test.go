@@ -0,0 +1,260 @@
+package asn1_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestContext_Rules(t *testing.T) {
+	ctx := asn1.NewContext()
+
+	boolean := asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagBoolean), Content: []byte{0x01}}
+	long := bytes.Repeat([]byte{0xaa}, 1001)
+
+	ctx.SetRules(asn1.DER, asn1.BER)
+	if out, err := ctx.Marshal(boolean); err != nil || hex.EncodeToString(out) != "0101ff" {
+		t.Errorf("unexpected DER encoding: %x %v", out, err)
+	}
+
+	ctx.SetRules(asn1.CER, asn1.BER)
+	if out, err := ctx.Marshal(long); err != nil || !bytes.HasPrefix(out, []byte{0x24, 0x80, 0x04, 0x82, 0x03, 0xe8}) {
+		t.Errorf("unexpected CER encoding: %x %v", out[:6], err)
+	}
+
+	data, _ := hex.DecodeString("010101")
+
+	var b bool
+	ctx.SetRules(asn1.BER, asn1.BER)
+	if err := ctx.Unmarshal(data, &b); err != nil || !b {
+		t.Errorf("unexpected BER decoding: %v %v", b, err)
+	}
+
+	ctx.SetRules(asn1.BER, asn1.DER)
+	if err := ctx.Unmarshal(data, &b); err == nil {
+		t.Errorf("expected DER error")
+	} else if _, ok := err.(*asn1.DERError); !ok {
+		t.Errorf("expected *DERError, got %T: %v", err, err)
+	}
+
+	ctx.SetRules(asn1.BER, asn1.CER)
+	if err := ctx.Unmarshal(data, &b); err == nil {
+		t.Errorf("expected CER error")
+	} else if _, ok := err.(*asn1.CERError); !ok {
+		t.Errorf("expected *CERError, got %T: %v", err, err)
+	}
+
+	// implicitly tagged values are checked against their universal type
+	var tagged struct {
+		B bool `asn1:"tag:0"`
+	}
+
+	data, _ = hex.DecodeString("3003800101")
+	if err := ctx.Unmarshal(data, &tagged); err == nil {
+		t.Errorf("expected CER error for implicitly tagged value")
+	}
+}
+
+func TestContext_Time(t *testing.T) {
+	type record struct {
+		T time.Time
+	}
+
+	var tests = []struct {
+		t   time.Time
+		out string
+	}{
+		{t: time.Date(2024, 1, 15, 12, 30, 45, 0, time.UTC), out: "17" + "0d" + hex.EncodeToString([]byte("240115123045Z"))},
+		{t: time.Date(2050, 1, 15, 12, 30, 45, 0, time.UTC), out: "18" + "0f" + hex.EncodeToString([]byte("20500115123045Z"))},
+		{t: time.Date(1949, 12, 31, 23, 0, 0, 500000000, time.UTC), out: "18" + "11" + hex.EncodeToString([]byte("19491231230000.5Z"))},
+	}
+
+	ctx := asn1.NewContext()
+
+	for i, tt := range tests {
+		out, err := ctx.Marshal(record{tt.t})
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if exp := "30" + hex.EncodeToString([]byte{byte(len(tt.out) / 2)}) + tt.out; hex.EncodeToString(out) != exp {
+			t.Errorf("%d. encoding mismatch: exp=%s got=%x", i, exp, out)
+		}
+
+		var r record
+		if err := ctx.Unmarshal(out, &r); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if !r.T.Equal(tt.t) {
+			t.Errorf("%d. time mismatch: exp=%s got=%s", i, tt.t, r.T)
+		}
+	}
+
+	// the pivot maps two-digit years onto another century
+	data, _ := hex.DecodeString("170d" + hex.EncodeToString([]byte("500101000000Z")))
+
+	var v time.Time
+	ctx.SetUTCTimePivot(2000)
+	if err := ctx.Unmarshal(data, &v); err != nil || v.Year() != 2050 {
+		t.Errorf("unexpected time with pivot 2000: %s %v", v, err)
+	}
+
+	// local GeneralizedTime is in the location of the context
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	ctx.SetTimeLocation(loc)
+
+	data, _ = hex.DecodeString("180e" + hex.EncodeToString([]byte("20240115123045")))
+	if err := ctx.Unmarshal(data, &v); err != nil || !v.Equal(time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC)) {
+		t.Errorf("unexpected local time: %s %v", v, err)
+	}
+
+	data, _ = hex.DecodeString("180f" + hex.EncodeToString([]byte("20240115123045Z")))
+	if err := ctx.Unmarshal(data, &v); err != nil || v.Location() != loc || v.Hour() != 14 {
+		t.Errorf("unexpected time in location: %s %v", v, err)
+	}
+
+	// implicitly tagged times are GeneralizedTime
+	var tagged struct {
+		T time.Time `asn1:"tag:0"`
+	}
+
+	tagged.T = time.Date(2024, 1, 15, 12, 30, 45, 0, time.UTC)
+	out, err := asn1.Marshal(tagged)
+	if exp := "3011800f" + hex.EncodeToString([]byte("20240115123045Z")); err != nil || hex.EncodeToString(out) != exp {
+		t.Errorf("unexpected implicitly tagged encoding: exp=%s got=%x %v", exp, out, err)
+	}
+}
+
+func TestContext_Extensions(t *testing.T) {
+	type v1 struct {
+		A int
+	}
+
+	type v1Extended struct {
+		A          int
+		Extensions []asn1.RawValue `asn1:"extensions"`
+	}
+
+	data, _ := hex.DecodeString(stripSpaces("3009 020101 020102 0c0161"))
+
+	ctx := asn1.NewContext()
+
+	var v v1
+	if err := ctx.Unmarshal(data, &v); err != asn1.ErrUnparsedObjects {
+		t.Errorf("expected ErrUnparsedObjects, got %v", err)
+	}
+
+	ctx.SetExtensions(asn1.IgnoreExtensions)
+	if err := ctx.Unmarshal(data, &v); err != nil || v.A != 1 {
+		t.Errorf("unexpected value %+v: %v", v, err)
+	}
+
+	var ext v1Extended
+	if err := ctx.Unmarshal(data, &ext); err != nil || ext.Extensions != nil {
+		t.Errorf("expected ignored extensions, got %+v: %v", ext, err)
+	}
+
+	ctx.SetExtensions(asn1.KeepExtensions)
+	if err := ctx.Unmarshal(data, &ext); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ext.A != 1 || len(ext.Extensions) != 2 || ext.Extensions[1].Tag != asn1.Tag(asn1.ClassUniversal, asn1.TagUTF8String) {
+		t.Errorf("unexpected value: %+v", ext)
+	}
+
+	out, err := ctx.Marshal(ext)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+		t.Errorf("encoding mismatch: exp=%x got=%x", data, out)
+	}
+
+	var invalid struct {
+		A          int
+		Extensions []int `asn1:"extensions"`
+	}
+
+	if err := ctx.Unmarshal(data, &invalid); err == nil {
+		t.Errorf("expected error for invalid extensions field")
+	}
+}
+
+func TestContext_StrictStrings(t *testing.T) {
+	var tests = []struct {
+		in  string
+		v   interface{ String() string }
+		out string
+	}{
+		{in: "1303612a62", v: new(asn1.PrintableString), out: "a*b"},
+		{in: "1603618062", v: new(asn1.IA5String), out: "a\x80b"},
+		{in: "1e04d8000061", v: new(asn1.BMPString), out: "�a"},
+		{in: "140361c962", v: new(asn1.T61String), out: "a�b"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(tt.in)
+
+		ctx := asn1.NewContext()
+		if err := ctx.Unmarshal(data, tt.v); !errors.Is(err, asn1.ErrConstraint) {
+			t.Errorf("%d. expected error of kind ErrConstraint, got %v", i, err)
+		}
+
+		ctx.SetStrictStrings(false)
+		if err := ctx.Unmarshal(data, tt.v); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if tt.v.String() != tt.out {
+			t.Errorf("%d. value mismatch: exp=%q got=%q", i, tt.out, tt.v.String())
+		}
+
+		var s string
+		if err := ctx.Unmarshal(data, &s); err != nil || s != tt.out {
+			t.Errorf("%d. unexpected string %q: %v", i, s, err)
+		}
+	}
+}
+
+func TestContext_UnmarshalCopies(t *testing.T) {
+	type record struct {
+		A          []byte
+		B          asn1.RawValue
+		C          asn1.OctetString
+		Extensions []asn1.RawValue `asn1:"extensions"`
+	}
+
+	var tests = []struct {
+		rules asn1.EncodingRules
+		in    string
+	}{
+		{rules: asn1.BER, in: "300c 040161 040162 040163 040164"},
+		{rules: asn1.CER, in: "3080 040161 040162 040163 040164 0000"},
+		{rules: asn1.DER, in: "300c 040161 040162 040163 040164"},
+	}
+
+	for _, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		ctx := asn1.NewContext()
+		ctx.SetRules(asn1.BER, tt.rules)
+		ctx.SetExtensions(asn1.KeepExtensions)
+		ctx.SetLimits(asn1.Limits{MaxDepth: 4})
+
+		var v record
+		if err := ctx.Unmarshal(data, &v); err != nil {
+			t.Fatalf("%d. unexpected error: %s", tt.rules, err)
+		}
+
+		// the decoded values must not change with the input
+		for i := range data {
+			data[i] = 0x00
+		}
+
+		if string(v.A) != "a" || string(v.B.Content) != "b" || v.C.String() != "c" || len(v.Extensions) != 1 || string(v.Extensions[0].Content) != "d" {
+			t.Errorf("%d. decoded values share memory with the input: %+v", tt.rules, v)
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package asn1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The time types of X.680 (2008) 38.4 are encoded as the characters of their
+// ISO 8601 value (X.690 8.26). DATE, TIME-OF-DAY and DATE-TIME are subtypes
+// of TIME in the basic settings, the year has four digits and no time zone
+// is present. Their values are written in the extended format, eg.
+// "2008-12-05", but encoded without hyphens and colons, eg. "20081205"
+// (X.690 8.26.2).
+const (
+	dateLayout      = "2006-01-02"
+	timeOfDayLayout = "15:04:05"
+	dateTimeLayout  = "2006-01-02T15:04:05"
+
+	dateEncoding      = "20060102"
+	timeOfDayEncoding = "150405"
+	dateTimeEncoding  = "20060102150405"
+)
+
+// Date is a DATE value, eg. "2008-12-05".
+type Date struct {
+	string
+}
+
+// NewDate returns the Date of s. The value is validated when it is
+// marshaled.
+func NewDate(s string) Date {
+	return Date{s}
+}
+
+// NewDateFrom returns the Date of the day of t in the location of t.
+func NewDateFrom(t time.Time) (Date, error) {
+	if t.Year() < 1582 || t.Year() > 9999 {
+		return Date{}, kindError(ErrConstraint, "year %d cannot be represented as DATE", t.Year())
+	}
+
+	return Date{t.Format(dateLayout)}, nil
+}
+
+// Time returns the start of the day of the Date in UTC.
+func (s Date) Time() (time.Time, error) {
+	return parseTimeValue("DATE", dateLayout, s.string)
+}
+
+func (s Date) String() string {
+	return s.string
+}
+
+func (s *Date) UnmarshalRawValue(rv *RawValue) error {
+	str, err := decodeTimeValue(rv, "DATE", dateLayout, dateEncoding)
+	if err != nil {
+		return err
+	}
+
+	*s = Date{str}
+	return nil
+}
+
+func (s Date) MarshalRawValue() (*RawValue, error) {
+	return encodeTimeValue(TagDate, "DATE", dateLayout, dateEncoding, s.string)
+}
+
+// TimeOfDay is a TIME-OF-DAY value, eg. "12:30:45".
+type TimeOfDay struct {
+	string
+}
+
+// NewTimeOfDay returns the TimeOfDay of s. The value is validated when it is
+// marshaled.
+func NewTimeOfDay(s string) TimeOfDay {
+	return TimeOfDay{s}
+}
+
+// NewTimeOfDayFrom returns the TimeOfDay of t in the location of t, the
+// fraction of the second is truncated.
+func NewTimeOfDayFrom(t time.Time) TimeOfDay {
+	return TimeOfDay{t.Format(timeOfDayLayout)}
+}
+
+// Duration returns the time since midnight of the TimeOfDay.
+func (s TimeOfDay) Duration() (time.Duration, error) {
+	t, err := parseTimeValue("TIME-OF-DAY", timeOfDayLayout, s.string)
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second, nil
+}
+
+func (s TimeOfDay) String() string {
+	return s.string
+}
+
+func (s *TimeOfDay) UnmarshalRawValue(rv *RawValue) error {
+	str, err := decodeTimeValue(rv, "TIME-OF-DAY", timeOfDayLayout, timeOfDayEncoding)
+	if err != nil {
+		return err
+	}
+
+	*s = TimeOfDay{str}
+	return nil
+}
+
+func (s TimeOfDay) MarshalRawValue() (*RawValue, error) {
+	return encodeTimeValue(TagTimeOfDay, "TIME-OF-DAY", timeOfDayLayout, timeOfDayEncoding, s.string)
+}
+
+// DateTime is a DATE-TIME value, eg. "2008-12-05T12:30:45".
+type DateTime struct {
+	string
+}
+
+// NewDateTime returns the DateTime of s. The value is validated when it is
+// marshaled.
+func NewDateTime(s string) DateTime {
+	return DateTime{s}
+}
+
+// NewDateTimeFrom returns the DateTime of t in the location of t, the
+// fraction of the second is truncated.
+func NewDateTimeFrom(t time.Time) (DateTime, error) {
+	if t.Year() < 1582 || t.Year() > 9999 {
+		return DateTime{}, kindError(ErrConstraint, "year %d cannot be represented as DATE-TIME", t.Year())
+	}
+
+	return DateTime{t.Format(dateTimeLayout)}, nil
+}
+
+// Time returns the time of the DateTime. DATE-TIME has no time zone, the
+// time is returned in UTC.
+func (s DateTime) Time() (time.Time, error) {
+	return parseTimeValue("DATE-TIME", dateTimeLayout, s.string)
+}
+
+func (s DateTime) String() string {
+	return s.string
+}
+
+func (s *DateTime) UnmarshalRawValue(rv *RawValue) error {
+	str, err := decodeTimeValue(rv, "DATE-TIME", dateTimeLayout, dateTimeEncoding)
+	if err != nil {
+		return err
+	}
+
+	*s = DateTime{str}
+	return nil
+}
+
+func (s DateTime) MarshalRawValue() (*RawValue, error) {
+	return encodeTimeValue(TagDateTime, "DATE-TIME", dateTimeLayout, dateTimeEncoding, s.string)
+}
+
+// parseTimeValue parses the value s of the time type name with layout. The
+// year must be in the range of the basic settings (X.680 38.4.3).
+func parseTimeValue(name, layout, s string) (time.Time, error) {
+	t, err := time.Parse(layout, s)
+	if err != nil || t.Format(layout) != s {
+		return time.Time{}, kindError(ErrConstraint, "invalid %s %q", name, s)
+	}
+
+	if strings.HasPrefix(layout, "2006") && t.Year() < 1582 {
+		return time.Time{}, kindError(ErrConstraint, "year %d of %s is before 1582", t.Year(), name)
+	}
+
+	return t, nil
+}
+
+// decodeTimeValue returns the value with layout of the time type name, which
+// is encoded in rv with the layout encoding.
+func decodeTimeValue(rv *RawValue, name, layout, encoding string) (string, error) {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return "", err
+	}
+
+	t, err := parseTimeValue(name, encoding, string(data))
+	if err != nil {
+		return "", err
+	}
+
+	return t.Format(layout), nil
+}
+
+// encodeTimeValue returns the universal value tag of the time type name
+// after validating s with layout, the content has the layout encoding.
+func encodeTimeValue(tag ASNValue, name, layout, encoding, s string) (*RawValue, error) {
+	t, err := parseTimeValue(name, layout, s)
+	if err != nil {
+		return nil, &SyntaxError{Msg: fmt.Sprintf("invalid %s %q", name, s), Err: ErrConstraint}
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, tag),
+		Content: []byte(t.Format(encoding)),
+	}, nil
+}
+
+// Duration is a DURATION value, an ISO 8601 duration such as "P1Y2M10DT2H30M"
+// or "P3W". The last component may have a fraction.
+type Duration struct {
+	string
+}
+
+// NewDuration returns the Duration of s. The value is validated when it is
+// marshaled.
+func NewDuration(s string) Duration {
+	return Duration{s}
+}
+
+// NewDurationFrom returns the Duration of d in hours, minutes and seconds,
+// eg. "PT1H2M3.5S". Negative durations cannot be represented.
+func NewDurationFrom(d time.Duration) (Duration, error) {
+	if d < 0 {
+		return Duration{}, kindError(ErrConstraint, "negative duration %s cannot be represented as DURATION", d)
+	}
+
+	s := "PT"
+	if h := d / time.Hour; h > 0 {
+		s += strconv.FormatInt(int64(h), 10) + "H"
+		d -= h * time.Hour
+	}
+
+	if m := d / time.Minute; m > 0 {
+		s += strconv.FormatInt(int64(m), 10) + "M"
+		d -= m * time.Minute
+	}
+
+	if d > 0 || s == "PT" {
+		s += strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+	}
+
+	return Duration{s}, nil
+}
+
+func (s Duration) String() string {
+	return s.string
+}
+
+func (s *Duration) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
+	if !isDuration(string(data)) {
+		return kindError(ErrConstraint, "invalid DURATION %q", data)
+	}
+
+	*s = Duration{string(data)}
+	return nil
+}
+
+func (s Duration) MarshalRawValue() (*RawValue, error) {
+	if !isDuration(s.string) {
+		return nil, &SyntaxError{Msg: fmt.Sprintf("invalid DURATION %q", s.string), Err: ErrConstraint}
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagDuration),
+		Content: []byte(s.string),
+	}, nil
+}
+
+// isDuration returns true when s is an ISO 8601 duration of the form
+// PnYnMnDTnHnMnS, with at least one component and a T only before time
+// components, or PnW. Only the last component may have a fraction.
+func isDuration(s string) bool {
+	if !strings.HasPrefix(s, "P") {
+		return false
+	}
+
+	s = s[1:]
+	if strings.HasSuffix(s, "W") {
+		return isDurationNumber(s[:len(s)-1])
+	}
+
+	designators, inTime := "YMD", false
+	components, fraction := 0, false
+
+	for len(s) > 0 {
+		if s[0] == 'T' {
+			if inTime || len(s) == 1 {
+				return false
+			}
+
+			designators, inTime, s = "HMS", true, s[1:]
+			continue
+		}
+
+		n := strings.IndexAny(s, "YMDHS")
+		if n <= 0 || fraction {
+			return false
+		}
+
+		i := strings.IndexByte(designators, s[n])
+		if i < 0 || !isDurationNumber(s[:n]) {
+			return false
+		}
+
+		fraction = strings.ContainsAny(s[:n], ".,")
+		designators, s = designators[i+1:], s[n+1:]
+		components++
+	}
+
+	return components > 0
+}
+
+// isDurationNumber returns true when s is a number of digits with an
+// optional fraction.
+func isDurationNumber(s string) bool {
+	if i := strings.IndexAny(s, ".,"); i >= 0 {
+		return isDigits(s[:i]) && isDigits(s[i+1:])
+	}
+
+	return isDigits(s)
+}
+
+// Time is a TIME value (X.680 38.1), any ISO 8601 time, date, interval or
+// recurrence, eg. "2008-12-05T12:30:45Z" or "R5/2008-03-01T13:00:00Z/P1Y".
+// Only the characters of the value are validated.
+type Time struct {
+	string
+}
+
+// NewTime returns the Time of s. The value is validated when it is
+// marshaled.
+func NewTime(s string) Time {
+	return Time{s}
+}
+
+func (s Time) String() string {
+	return s.string
+}
+
+func (s *Time) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
+	if err := checkTimeChars(string(data)); err != nil {
+		return kindError(ErrConstraint, "%s", err.Msg)
+	}
+
+	*s = Time{string(data)}
+	return nil
+}
+
+func (s Time) MarshalRawValue() (*RawValue, error) {
+	if err := checkTimeChars(s.string); err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagTime),
+		Content: []byte(s.string),
+	}, nil
+}
+
+// checkTimeChars validates that s is a non-empty string of the characters
+// used by ISO 8601.
+func checkTimeChars(s string) *SyntaxError {
+	if s == "" {
+		return &SyntaxError{Msg: "empty TIME", Err: ErrConstraint}
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			if !strings.ContainsRune("+-:.,/CDHMPRSTWYZ", r) {
+				return &SyntaxError{Msg: fmt.Sprintf("invalid character %q in TIME", r), Err: ErrConstraint}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package asn1_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestDateTimeTypes(t *testing.T) {
+	// content is the encoding of v, when it differs from the value of v
+	var tests = []struct {
+		v       interface{}
+		tag     asn1.ASNValue
+		content string
+		valid   bool
+	}{
+		{v: asn1.NewDate("2008-12-05"), tag: asn1.TagDate, content: "20081205", valid: true},
+		{v: asn1.NewDate("2008-02-29"), tag: asn1.TagDate, content: "20080229", valid: true},
+		{v: asn1.NewDate("2007-02-29"), tag: asn1.TagDate, content: "20070229"},
+		{v: asn1.NewDate("20081205"), tag: asn1.TagDate, content: "2008-12-05"},
+		{v: asn1.NewDate("1500-01-01"), tag: asn1.TagDate, content: "15000101"},
+		{v: asn1.NewTimeOfDay("12:30:45"), tag: asn1.TagTimeOfDay, content: "123045", valid: true},
+		{v: asn1.NewTimeOfDay("24:00:00"), tag: asn1.TagTimeOfDay, content: "240000"},
+		{v: asn1.NewTimeOfDay("12:30:45Z"), tag: asn1.TagTimeOfDay, content: "123045Z"},
+		{v: asn1.NewTimeOfDay("123045"), tag: asn1.TagTimeOfDay, content: "12:30:45"},
+		{v: asn1.NewDateTime("2008-12-05T12:30:45"), tag: asn1.TagDateTime, content: "20081205123045", valid: true},
+		{v: asn1.NewDateTime("2008-12-05 12:30:45"), tag: asn1.TagDateTime, content: "2008-12-05T12:30:45"},
+		{v: asn1.NewDuration("P1Y2M10DT2H30M"), tag: asn1.TagDuration, valid: true},
+		{v: asn1.NewDuration("P3W"), tag: asn1.TagDuration, valid: true},
+		{v: asn1.NewDuration("PT0.5S"), tag: asn1.TagDuration, valid: true},
+		{v: asn1.NewDuration("P1M"), tag: asn1.TagDuration, valid: true},
+		{v: asn1.NewDuration("PT1M"), tag: asn1.TagDuration, valid: true},
+		{v: asn1.NewDuration("P"), tag: asn1.TagDuration},
+		{v: asn1.NewDuration("PT"), tag: asn1.TagDuration},
+		{v: asn1.NewDuration("P1DT"), tag: asn1.TagDuration},
+		{v: asn1.NewDuration("P1D2Y"), tag: asn1.TagDuration},
+		{v: asn1.NewDuration("P1.5Y2M"), tag: asn1.TagDuration},
+		{v: asn1.NewDuration("PT1HT2M"), tag: asn1.TagDuration},
+		{v: asn1.NewDuration("P1H"), tag: asn1.TagDuration},
+		{v: asn1.NewTime("2008-12-05T12:30:45Z"), tag: asn1.TagTime, valid: true},
+		{v: asn1.NewTime("R5/2008-03-01T13:00:00Z/P1Y"), tag: asn1.TagTime, valid: true},
+		{v: asn1.NewTime(""), tag: asn1.TagTime},
+		{v: asn1.NewTime("2008-12-05 12:30"), tag: asn1.TagTime},
+	}
+
+	for i, tt := range tests {
+		data, err := asn1.Marshal(tt.v)
+		if tt.valid != (err == nil) {
+			t.Errorf("%d. %v: unexpected marshal result: %v", i, tt.v, err)
+		} else if err != nil && !errors.Is(err, asn1.ErrConstraint) {
+			t.Errorf("%d. %v: expected error of kind ErrConstraint, got %v", i, tt.v, err)
+		}
+
+		value := tt.v.(interface{ String() string }).String()
+
+		in := tt.content
+		if in == "" {
+			in = value
+		}
+
+		if tt.valid {
+			if raw, err := asn1.DecodeRawValue(bytes.NewReader(data)); err != nil || string(raw.Content) != in {
+				t.Errorf("%d. encoding mismatch: exp=%s got=%x %v", i, in, data, err)
+			}
+		}
+
+		raw := asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, tt.tag), Content: []byte(in)}
+		data, _ = raw.Encode()
+
+		var v interface{ String() string }
+		switch tt.tag {
+		case asn1.TagDate:
+			v = new(asn1.Date)
+		case asn1.TagTimeOfDay:
+			v = new(asn1.TimeOfDay)
+		case asn1.TagDateTime:
+			v = new(asn1.DateTime)
+		case asn1.TagDuration:
+			v = new(asn1.Duration)
+		case asn1.TagTime:
+			v = new(asn1.Time)
+		}
+
+		err = asn1.Unmarshal(data, v)
+		if tt.valid != (err == nil) {
+			t.Errorf("%d. %v: unexpected unmarshal result: %v", i, tt.v, err)
+		} else if tt.valid && v.String() != value {
+			t.Errorf("%d. value mismatch: exp=%s got=%s", i, value, v)
+		}
+	}
+}
+
+func TestDateTimeTypes_Time(t *testing.T) {
+	ts := time.Date(2008, 12, 5, 12, 30, 45, 500, time.UTC)
+
+	date, err := asn1.NewDateFrom(ts)
+	if err != nil || date.String() != "2008-12-05" {
+		t.Errorf("unexpected date: %s %v", date, err)
+	}
+
+	if v, err := date.Time(); err != nil || !v.Equal(time.Date(2008, 12, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected time of date: %s %v", v, err)
+	}
+
+	tod := asn1.NewTimeOfDayFrom(ts)
+	if d, err := tod.Duration(); err != nil || d != 12*time.Hour+30*time.Minute+45*time.Second {
+		t.Errorf("unexpected duration of time of day %s: %s %v", tod, d, err)
+	}
+
+	dt, err := asn1.NewDateTimeFrom(ts)
+	if err != nil || dt.String() != "2008-12-05T12:30:45" {
+		t.Errorf("unexpected date time: %s %v", dt, err)
+	}
+
+	if v, err := dt.Time(); err != nil || !v.Equal(ts.Truncate(time.Second)) {
+		t.Errorf("unexpected time of date time: %s %v", v, err)
+	}
+
+	if _, err := asn1.NewDateFrom(time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, asn1.ErrConstraint) {
+		t.Errorf("expected error of kind ErrConstraint, got %v", err)
+	}
+
+	var tests = []struct {
+		d   time.Duration
+		out string
+	}{
+		{d: 0, out: "PT0S"},
+		{d: 90 * time.Minute, out: "PT1H30M"},
+		{d: 3*time.Hour + 1500*time.Millisecond, out: "PT3H1.5S"},
+	}
+
+	for i, tt := range tests {
+		d, err := asn1.NewDurationFrom(tt.d)
+		if err != nil || d.String() != tt.out {
+			t.Errorf("%d. unexpected duration: exp=%s got=%s %v", i, tt.out, d, err)
+		}
+	}
+}
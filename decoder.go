@@ -0,0 +1,227 @@
+package asn1
+
+import (
+	"bufio"
+	"io"
+)
+
+// A Token is an interface holding one of the token types: StartConstructed,
+// Primitive or EndConstructed.
+type Token interface{}
+
+// StartConstructed is returned by Token for the start of a constructed value.
+// The children of the value are returned as separate tokens, followed by an
+// EndConstructed token.
+type StartConstructed struct {
+	Tag        ASNTag
+	Indefinite bool
+	// Length of the content, only valid for definite length values.
+	Length uint
+	// Offset of the identifier octets in the input stream.
+	Offset int64
+}
+
+// Primitive is returned by Token for a primitive value.
+type Primitive struct {
+	Tag     ASNTag
+	Content []byte
+	// Offset of the identifier octets in the input stream.
+	Offset int64
+}
+
+// EndConstructed is returned by Token for the end of a constructed value.
+type EndConstructed struct {
+	Tag ASNTag
+	// Offset just after the last octet of the constructed value.
+	Offset int64
+}
+
+// frame is a constructed value of which the children are being decoded.
+type frame struct {
+	tag        ASNTag
+	indefinite bool
+	end        int64
+}
+
+// countingReader keeps track of the number of bytes read.
+type countingReader struct {
+	r      *bufio.Reader
+	offset int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.offset += int64(n)
+	return n, err
+}
+
+// A Decoder reads a stream of BER encoded values token by token. Only the
+// content of a single primitive value is kept in memory, so arbitrarily large
+// structures can be walked.
+type Decoder struct {
+	r      *countingReader
+	stack  []frame
+	limits limiter
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: &countingReader{r: bufio.NewReader(r)},
+	}
+}
+
+// SetLimits bounds the resources used by the decoder, a *LimitError is
+// returned by Token when the input exceeds limits. The values within the
+// constructed values passed over by Skip are not accounted for.
+func (d *Decoder) SetLimits(limits Limits) {
+	d.limits = limiter{
+		Limits: limits,
+	}
+}
+
+// InputOffset returns the offset of the next unread byte in the input stream.
+func (d *Decoder) InputOffset() int64 {
+	return d.r.offset
+}
+
+// Depth returns the number of constructed values that have been started but
+// not ended yet.
+func (d *Decoder) Depth() int {
+	return len(d.stack)
+}
+
+// Token returns the next token in the input stream. At the end of the input
+// stream, Token returns nil, io.EOF.
+func (d *Decoder) Token() (Token, error) {
+	if n := len(d.stack); n > 0 {
+		top := d.stack[n-1]
+		if !top.indefinite && d.r.offset == top.end {
+			d.stack = d.stack[:n-1]
+			return EndConstructed{Tag: top.tag, Offset: d.r.offset}, nil
+		}
+	} else if _, err := d.r.r.Peek(1); err == io.EOF {
+		return nil, io.EOF
+	}
+
+	offset := d.r.offset
+
+	tag, constructed, err := decodeIdentifier(d.r)
+	if err != nil {
+		return nil, d.errorAt(unexpectedEOF(err), offset)
+	}
+
+	length, indefinite, err := decodeLength(d.r)
+	if err != nil {
+		return nil, d.errorAt(unexpectedEOF(err), offset)
+	}
+
+	if tag.Class == ClassUniversal && tag.Value == TagEoc && !constructed && !indefinite && length == 0 {
+		n := len(d.stack)
+		if n == 0 || !d.stack[n-1].indefinite {
+			return nil, d.errorAt(parseError("unexpected end-of-contents"), offset)
+		}
+
+		top := d.stack[n-1]
+		d.stack = d.stack[:n-1]
+		return EndConstructed{Tag: top.tag, Offset: d.r.offset}, nil
+	}
+
+	if indefinite && !constructed {
+		return nil, d.errorAt(parseError("primitive node with indefinite length"), offset)
+	}
+
+	if err := d.limits.element(len(d.stack), length); err != nil {
+		return nil, err
+	}
+
+	if max := d.limits.MaxSize; max > 0 && (d.r.offset > max || !indefinite && uint64(length) > uint64(max-d.r.offset)) {
+		return nil, &LimitError{"MaxSize", max}
+	}
+
+	end := d.r.offset + int64(length)
+	if !indefinite {
+		if err := d.checkBounds(end); err != nil {
+			return nil, d.errorAt(err, offset)
+		}
+	}
+
+	if constructed {
+		d.stack = append(d.stack, frame{
+			tag:        tag,
+			indefinite: indefinite,
+			end:        end,
+		})
+
+		return StartConstructed{
+			Tag:        tag,
+			Indefinite: indefinite,
+			Length:     length,
+			Offset:     offset,
+		}, nil
+	}
+
+	content, err := readContent(d.r, length)
+	if err != nil {
+		return nil, d.errorAt(err, offset)
+	}
+
+	return Primitive{
+		Tag:     tag,
+		Content: content,
+		Offset:  offset,
+	}, nil
+}
+
+// Skip reads tokens until it has consumed the end of the constructed value
+// most recently started. The content of definite length values is discarded
+// without decoding it.
+func (d *Decoder) Skip() error {
+	depth := len(d.stack)
+	if depth == 0 {
+		return syntaxError("skip outside of constructed value")
+	}
+
+	if top := d.stack[depth-1]; !top.indefinite {
+		if err := skipBytes(d.r, top.end-d.r.offset); err != nil {
+			return d.errorAt(unexpectedEOF(err), d.r.offset)
+		}
+
+		d.stack = d.stack[:depth-1]
+		return nil
+	}
+
+	for len(d.stack) >= depth {
+		if _, err := d.Token(); err == io.EOF {
+			return d.errorAt(io.ErrUnexpectedEOF, d.r.offset)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkBounds verifies that a value ending at end fits in all definite
+// length values that are being decoded.
+func (d *Decoder) checkBounds(end int64) error {
+	for i := len(d.stack) - 1; i >= 0; i-- {
+		if d.stack[i].indefinite {
+			continue
+		}
+
+		if end > d.stack[i].end {
+			return parseError("value exceeds length of enclosing value %s", d.stack[i].tag)
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// errorAt records offset and the tags of the constructed values being decoded
+// in err, see errorIn.
+func (d *Decoder) errorAt(err error, offset int64) error {
+	return errorIn(err, offset, d.stack)
+}
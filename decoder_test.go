@@ -0,0 +1,116 @@
+package asn1_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestDecoder_Token(t *testing.T) {
+	// SEQUENCE { INTEGER 1, [0] (indefinite) { BOOLEAN TRUE }, NULL }
+	data, _ := hex.DecodeString("300c020101a0800101ff00000500")
+
+	sequence := asn1.Tag(asn1.ClassUniversal, asn1.TagSequence)
+	context := asn1.Tag(asn1.ClassContextSpecific, 0)
+
+	exp := []asn1.Token{
+		asn1.StartConstructed{Tag: sequence, Length: 12, Offset: 0},
+		asn1.Primitive{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagInteger), Content: []byte{0x01}, Offset: 2},
+		asn1.StartConstructed{Tag: context, Indefinite: true, Offset: 5},
+		asn1.Primitive{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagBoolean), Content: []byte{0xff}, Offset: 7},
+		asn1.EndConstructed{Tag: context, Offset: 12},
+		asn1.Primitive{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagNull), Content: []byte{}, Offset: 12},
+		asn1.EndConstructed{Tag: sequence, Offset: 14},
+	}
+
+	d := asn1.NewDecoder(bytes.NewReader(data))
+	for i, e := range exp {
+		tok, err := d.Token()
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if !reflect.DeepEqual(tok, e) {
+			t.Errorf("%d. token mismatch:\n  exp=%#v\n  got=%#v", i, e, tok)
+		}
+	}
+
+	if _, err := d.Token(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestDecoder_Skip(t *testing.T) {
+	// SEQUENCE { SEQUENCE { INTEGER 1 }, [0] (indefinite) { SEQUENCE {} }, INTEGER 2 }
+	data, _ := hex.DecodeString("300e3003020101a080300000000201020101ff")
+
+	d := asn1.NewDecoder(bytes.NewReader(data))
+
+	for _, skip := range []bool{false, true, true} {
+		if _, err := d.Token(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !skip {
+			continue
+		}
+
+		if err := d.Skip(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	tok, err := d.Token()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if p, ok := tok.(asn1.Primitive); !ok || !bytes.Equal(p.Content, []byte{0x02}) {
+		t.Errorf("unexpected token after skip: %#v", tok)
+	}
+
+	if _, ok := mustToken(t, d).(asn1.EndConstructed); !ok {
+		t.Errorf("expected end of sequence")
+	}
+
+	if _, ok := mustToken(t, d).(asn1.Primitive); !ok {
+		t.Errorf("expected trailing boolean")
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	var tests = []string{
+		"3003020201",   // child exceeds parent
+		"30050201",     // truncated
+		"0000",         // top level end-of-contents
+		"0480",         // primitive indefinite length
+		"308002010100", // unterminated
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(tt)
+
+		d := asn1.NewDecoder(bytes.NewReader(data))
+
+		var err error
+		for err == nil {
+			_, err = d.Token()
+		}
+
+		if err == io.EOF {
+			t.Errorf("%d. %s: expected error", i, tt)
+		}
+	}
+}
+
+func mustToken(t *testing.T, d *asn1.Decoder) asn1.Token {
+	tok, err := d.Token()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return tok
+}
@@ -0,0 +1,448 @@
+package asn1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// DERError is returned when the data is valid BER, but violates one of the
+// restrictions of the Distinguished Encoding Rules.
+type DERError struct {
+	// Rule is the clause of X.690 that has been violated, eg. "10.1".
+	Rule string
+	Tag  ASNTag
+	Msg  string
+	// Offset of the identifier octets of the invalid value in the input,
+	// -1 when unknown.
+	Offset int64
+	// Path names the values leading to the invalid value, see ParseError.
+	Path []string
+}
+
+// Error returns the error message of a DERError, prefixed with the offset
+// and path when known.
+func (e *DERError) Error() string {
+	return positioned(e.Offset, e.Path, fmt.Sprintf("der: %s %s (X.690 %s)", e.Tag, e.Msg, e.Rule))
+}
+
+// Unwrap returns ErrNotDER.
+func (e *DERError) Unwrap() error {
+	return ErrNotDER
+}
+
+// derError allocates a new DERError.
+func derError(rule string, tag ASNTag, msg string, args ...interface{}) *DERError {
+	return &DERError{
+		Rule:   rule,
+		Tag:    tag,
+		Msg:    fmt.Sprintf(msg, args...),
+		Offset: -1,
+	}
+}
+
+// DecodeDER reads a value like DecodeRawValue, but verifies that the value
+// and all values contained in it are DER encoded. A *DERError is returned
+// when a DER restriction has been violated.
+func DecodeDER(reader io.Reader) (*RawValue, error) {
+	return decodeDER(reader, &limiter{})
+}
+
+// decodeDER reads and verifies a top level DER encoded value, the value and
+// the values nested in it are accounted in l.
+func decodeDER(reader io.Reader, l *limiter) (*RawValue, error) {
+	raw, length, err := decodeDERHeader(reader, 0, l, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if raw.Content, err = readContent(reader, length); err != nil {
+		return nil, errorAt(err, 0)
+	}
+
+	if err := verifyDER(raw, l); err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// decodeDERHeader reads and verifies the identifier and length octets of a
+// value at offset of the decoded input, and accounts for the value at depth
+// in l. The content of the value is not read, its length is returned.
+func decodeDERHeader(reader io.Reader, offset int64, l *limiter, depth int) (*RawValue, uint, error) {
+	header := bytes.NewBuffer([]byte{})
+	headerReader := io.TeeReader(reader, header)
+
+	tag, constructed, err := decodeIdentifier(headerReader)
+	if err != nil {
+		return nil, 0, errorAt(err, offset)
+	}
+
+	identifierLength := header.Len()
+
+	length, indefinite, err := decodeLength(headerReader)
+	if err != nil {
+		return nil, 0, errorAt(err, offset)
+	}
+
+	if indefinite {
+		return nil, 0, errorAt(derError("10.1", tag, "uses the indefinite length form"), offset)
+	}
+
+	if err := l.element(depth, length); err != nil {
+		return nil, 0, err
+	}
+
+	raw := &RawValue{
+		Tag:          tag,
+		Constructed:  constructed,
+		Offset:       offset,
+		HeaderLength: header.Len(),
+	}
+
+	// The identifier and length octets are minimal when they equal the
+	// octets we would have encoded ourselves.
+	identifier, err := encodeIdentifier(raw)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !bytes.Equal(identifier, header.Bytes()[:identifierLength]) {
+		return nil, 0, errorAt(derError("8.1.2", tag, "tag number is not encoded in the minimum number of octets"), offset)
+	}
+
+	if !bytes.Equal(encodeLength(length), header.Bytes()[identifierLength:]) {
+		return nil, 0, errorAt(derError("10.1", tag, "length is not encoded in the minimum number of octets"), offset)
+	}
+
+	return raw, length, nil
+}
+
+// verifyDER verifies that raw and all values nested in it are DER encoded.
+// The nested values are walked within the content of raw without copying
+// it, using a stack instead of recursion.
+func verifyDER(raw *RawValue, l *limiter) error {
+	// the constructed values being walked, and the remainder of their content
+	stack := []frame{}
+	parents := []*RawValue{}
+	readers := []*bytes.Reader{}
+
+	for {
+		if raw.Tag.Class == ClassUniversal {
+			if err := checkDER(raw.Tag.Value, raw); err != nil {
+				return errorIn(err, raw.Offset, stack)
+			}
+		}
+
+		if raw.Constructed {
+			stack = append(stack, frame{tag: raw.Tag})
+			parents = append(parents, raw)
+			readers = append(readers, bytes.NewReader(raw.Content))
+		}
+
+		for n := len(readers); n > 0 && readers[n-1].Len() == 0; n = len(readers) {
+			stack, parents, readers = stack[:n-1], parents[:n-1], readers[:n-1]
+		}
+
+		if len(readers) == 0 {
+			return nil
+		}
+
+		parent, reader := parents[len(parents)-1], readers[len(readers)-1]
+		offset := parent.ContentOffset() + reader.Size() - int64(reader.Len())
+
+		child, length, err := decodeDERHeader(reader, offset, l, len(stack))
+		if err != nil {
+			return errorIn(err, offset, stack)
+		}
+
+		if uint64(length) > uint64(reader.Len()) {
+			return errorIn(io.ErrUnexpectedEOF, offset, stack)
+		}
+
+		start := reader.Size() - int64(reader.Len())
+		child.Content = parent.Content[start : start+int64(length)]
+
+		if _, err := reader.Seek(int64(length), io.SeekCurrent); err != nil {
+			return err
+		}
+
+		raw = child
+	}
+}
+
+// ruleErrorFunc allocates the error for a violated rule of X.690.
+type ruleErrorFunc func(rule string, tag ASNTag, msg string, args ...interface{}) error
+
+// checkDER verifies that the content of raw is a DER encoded value of the
+// universal type tag. The identifier and length octets and the children of
+// constructed values have already been verified.
+func checkDER(tag ASNValue, raw *RawValue) error {
+	errorf := func(rule string, tag ASNTag, msg string, args ...interface{}) error {
+		return derError(rule, tag, msg, args...)
+	}
+
+	if isStringTag(tag) && raw.Constructed {
+		return errorf("10.2", raw.Tag, "uses the constructed form")
+	}
+
+	return checkCanonical(tag, raw, errorf)
+}
+
+// checkCanonical verifies the restrictions on the content of values of the
+// universal type tag that are common to CER and DER.
+func checkCanonical(tag ASNValue, raw *RawValue, errorf ruleErrorFunc) error {
+	data := raw.Content
+
+	switch tag {
+	case TagBoolean:
+		if len(data) != 1 {
+			return errorf("8.2.1", raw.Tag, "does not consist of a single octet")
+		}
+
+		if data[0] != 0x00 && data[0] != 0xff {
+			return errorf("11.1", raw.Tag, "TRUE is not encoded as 0xff")
+		}
+	case TagInteger, TagEnumerated:
+		if len(data) == 0 {
+			return errorf("8.3.1", raw.Tag, "has no content octets")
+		}
+
+		if len(data) > 1 && (data[0] == 0x00 && data[1]&0x80 == 0 || data[0] == 0xff && data[1]&0x80 != 0) {
+			return errorf("8.3.2", raw.Tag, "is not encoded in the minimum number of octets")
+		}
+	case TagBitString:
+		if raw.Constructed {
+			// the segments have been checked already
+			return nil
+		}
+
+		if len(data) == 0 {
+			return errorf("8.6.2", raw.Tag, "has no initial octet")
+		}
+
+		paddingBits := data[0]
+		if paddingBits > 7 {
+			return errorf("8.6.2.2", raw.Tag, "has %d unused bits", paddingBits)
+		}
+
+		if len(data) == 1 && paddingBits > 0 {
+			return errorf("8.6.2.3", raw.Tag, "is empty but has unused bits")
+		}
+
+		if data[len(data)-1]&((1<<paddingBits)-1) != 0 {
+			return errorf("11.2.1", raw.Tag, "has unused bits that are not zero")
+		}
+	case TagReal:
+		if rule, msg := checkReal(data); rule != "" {
+			return errorf(rule, raw.Tag, msg)
+		}
+	case TagUTCTime, TagGeneralizedTime:
+		if rule, msg := checkTime(tag, data); rule != "" {
+			return errorf(rule, raw.Tag, msg)
+		}
+	case TagSet:
+		children, err := raw.ChildValues()
+		if err != nil {
+			return err
+		}
+
+		// Without a schema a SET and a SET OF can not be told apart, so
+		// both the ordering of SET and of SET OF components is accepted.
+		if !sortedByTag(children) && !sortedByEncoding(children) {
+			return errorf("11.6", raw.Tag, "components are not sorted")
+		}
+	}
+
+	return nil
+}
+
+// isStringTag returns true for the universal tags of the string types, these
+// can be encoded in segments.
+func isStringTag(tag ASNValue) bool {
+	return tag == TagBitString || tag == TagOctetString || stringTags[tag]
+}
+
+// lessTag returns true when a precedes b in the canonical order of tags,
+// as defined in X.680 8.6.
+func lessTag(a, b ASNTag) bool {
+	if a.Class != b.Class {
+		return a.Class < b.Class
+	}
+	return a.Value < b.Value
+}
+
+// sortedByTag returns true when the values are in the canonical order of
+// their tags, as required for SET components by X.690 10.3. The components
+// of a SET have distinct tags.
+func sortedByTag(values []*RawValue) bool {
+	for i := 1; i < len(values); i++ {
+		if !lessTag(values[i-1].Tag, values[i].Tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedByEncoding returns true when the values are in ascending order of
+// their encodings, as required for SET OF components by X.690 11.6.
+func sortedByEncoding(values []*RawValue) bool {
+	encodings := make([][]byte, len(values))
+	for i, value := range values {
+		data, err := value.Encode()
+		if err != nil {
+			return false
+		}
+		encodings[i] = data
+	}
+
+	return sort.SliceIsSorted(encodings, func(i, j int) bool {
+		return compareEncodings(encodings[i], encodings[j]) < 0
+	})
+}
+
+// compareEncodings compares two encodings as octet strings, the shorter
+// encoding is padded at its trailing end with zero octets.
+func compareEncodings(a, b []byte) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y byte
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+
+		if x != y {
+			return int(x) - int(y)
+		}
+	}
+
+	return 0
+}
+
+// ToDER converts a BER encoded value into its canonical DER form. Lengths
+// are made definite and minimal, constructed strings are reassembled,
+// booleans, integers and bit strings are made canonical and the components
+// of SET values are sorted.
+//
+// DEFAULT values can only be omitted with knowledge of the schema, see the
+// asn1parser package.
+func ToDER(raw *RawValue) (*RawValue, error) {
+	out := &RawValue{
+		Tag:         raw.Tag,
+		Constructed: raw.Constructed,
+		Content:     raw.Content,
+	}
+
+	universal := raw.Tag.Class == ClassUniversal
+
+	if universal && raw.Constructed {
+		var err error
+
+		switch {
+		case raw.Tag.Value == TagBitString:
+			out.Content, err = joinBitStringSegments(raw)
+			out.Constructed = false
+		case isStringTag(raw.Tag.Value):
+			out.Content, err = joinSegments(raw, TagOctetString)
+			out.Constructed = false
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if out.Constructed {
+		children, err := raw.ChildValues()
+		if err != nil {
+			return nil, err
+		}
+
+		for i, child := range children {
+			if children[i], err = ToDER(child); err != nil {
+				return nil, err
+			}
+		}
+
+		if universal && raw.Tag.Value == TagSet {
+			sortSet(children)
+		}
+
+		out.Content = []byte{}
+		for _, child := range children {
+			data, err := child.Encode()
+			if err != nil {
+				return nil, err
+			}
+
+			out.Content = append(out.Content, data...)
+		}
+
+		return out, nil
+	}
+
+	if !universal {
+		return out, nil
+	}
+
+	data := append([]byte{}, out.Content...)
+
+	switch raw.Tag.Value {
+	case TagBoolean:
+		if len(data) != 1 {
+			return nil, kindError(ErrInvalidLength, "invalid BOOLEAN length: %d", len(data))
+		}
+
+		if data[0] != 0x00 {
+			data[0] = 0xff
+		}
+	case TagInteger, TagEnumerated:
+		if len(data) == 0 {
+			return nil, kindError(ErrInvalidLength, "empty integer")
+		}
+
+		data = encodeBigInt(parseBigInt(data))
+	case TagBitString:
+		if len(data) == 0 {
+			return nil, kindError(ErrInvalidLength, "zero length BIT STRING")
+		}
+
+		if data[0] > 7 || len(data) == 1 && data[0] > 0 {
+			return nil, parseError("invalid padding bits in BIT STRING")
+		}
+
+		data[len(data)-1] &^= (1 << data[0]) - 1
+	}
+
+	out.Content = data
+	return out, nil
+}
+
+// sortSet sorts the components of a SET or SET OF. Components with distinct
+// tags are sorted by tag, other components by their encoding.
+func sortSet(values []*RawValue) {
+	distinct := map[ASNTag]bool{}
+	for _, value := range values {
+		distinct[value.Tag] = true
+	}
+
+	if len(distinct) == len(values) {
+		sort.SliceStable(values, func(i, j int) bool {
+			return lessTag(values[i].Tag, values[j].Tag)
+		})
+		return
+	}
+
+	encodings := map[*RawValue][]byte{}
+	for _, value := range values {
+		encodings[value], _ = value.Encode()
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		return compareEncodings(encodings[values[i]], encodings[values[j]]) < 0
+	})
+}
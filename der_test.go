@@ -0,0 +1,179 @@
+package asn1_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestDecodeDER(t *testing.T) {
+	var tests = []struct {
+		in   string
+		rule string
+	}{
+		{in: "0101ff", rule: ""},
+		{in: "3006020101020100", rule: ""},
+		{in: "31060201010201ff", rule: ""},
+		{in: "3108800100a1030201ff", rule: ""},
+		{in: "0101aa", rule: "11.1"},
+		{in: "010200ff", rule: "8.2.1"},
+		{in: "02020001", rule: "8.3.2"},
+		{in: "0202ff80", rule: "8.3.2"},
+		{in: "0200", rule: "8.3.1"},
+		{in: "0a020001", rule: "8.3.2"},
+		{in: "03020701", rule: "11.2.1"},
+		{in: "0481020102", rule: "10.1"},
+		{in: "3080020101 0000", rule: "10.1"},
+		{in: "1f0200", rule: "8.1.2"},
+		{in: "2403040101", rule: "10.2"},
+		{in: "31060201ff020101", rule: "11.6"},
+		{in: "30050481020102", rule: "10.1"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		_, err := asn1.DecodeDER(bytes.NewReader(data))
+		if tt.rule == "" {
+			if err != nil {
+				t.Errorf("%d. %s: unexpected error: %s", i, tt.in, err)
+			}
+			continue
+		}
+
+		derErr, ok := err.(*asn1.DERError)
+		if !ok {
+			t.Errorf("%d. %s: expected DERError, got %v", i, tt.in, err)
+		} else if derErr.Rule != tt.rule {
+			t.Errorf("%d. %s: rule mismatch: exp=%s got=%s", i, tt.in, tt.rule, derErr.Rule)
+		} else if !errors.Is(err, asn1.ErrNotDER) {
+			t.Errorf("%d. %s: expected error of kind %v", i, tt.in, asn1.ErrNotDER)
+		}
+	}
+}
+
+func TestDecodeDER_ErrorPosition(t *testing.T) {
+	data, _ := hex.DecodeString(stripSpaces("3009 020101 3004 0481020102"))
+
+	_, err := asn1.DecodeDER(bytes.NewReader(data))
+
+	exp := "offset 0x7: [UNIVERSAL 16][UNIVERSAL 16]: der: [UNIVERSAL 4] length is not encoded in the minimum number of octets (X.690 10.1)"
+	if err == nil || err.Error() != exp {
+		t.Errorf("error mismatch:\n  exp=%s\n  got=%v", exp, err)
+	}
+}
+
+func TestDecodeDER_Nested(t *testing.T) {
+	raw := &asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagNull)}
+
+	for i := 0; i < 5000; i++ {
+		content, err := raw.Encode()
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		raw = &asn1.RawValue{
+			Tag:         asn1.Tag(asn1.ClassUniversal, asn1.TagSequence),
+			Constructed: true,
+			Content:     content,
+		}
+	}
+
+	data, err := raw.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	out, err := asn1.DecodeDER(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(out.Content, raw.Content) {
+		t.Errorf("content mismatch")
+	}
+}
+
+func TestUnmarshalDER(t *testing.T) {
+	var v struct {
+		Flag bool `asn1:"tag:0"`
+	}
+
+	data, _ := hex.DecodeString("3003800101")
+	if err := asn1.UnmarshalDER(data, &v); err == nil {
+		t.Errorf("expected error for non canonical implicit boolean")
+	}
+
+	if err := asn1.Unmarshal(data, &v); err != nil || !v.Flag {
+		t.Errorf("unexpected BER result: %v %v", v.Flag, err)
+	}
+}
+
+func TestMarshalDER(t *testing.T) {
+	ctx := asn1.NewContext()
+	ctx.SetDER(true, true)
+
+	data, err := ctx.Marshal(struct {
+		Values []int `asn1:"set"`
+	}{[]int{256, 3, -1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if exp := "300c310a0201030201ff02020100"; hex.EncodeToString(data) != exp {
+		t.Errorf("output mismatch: exp=%s got=%x", exp, data)
+	}
+}
+
+func stripSpaces(s string) string {
+	return string(bytes.Replace([]byte(s), []byte(" "), nil, -1))
+}
+
+func TestToDER(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{in: "01017f", out: "0101ff"},
+		{in: "0203000001", out: "020101"},
+		{in: "0203ffff80", out: "020180"},
+		{in: "030207ff", out: "03020780"},
+		{in: "0481020102", out: "04020102"},
+		{in: "3080020101 0000", out: "3003020101"},
+		{in: "2480 0402 0102 0401 03 0000", out: "0403010203"},
+		{in: "2380 0302 00ff 0302 0380 0000", out: "030303ff80"},
+		{in: "310a 020200ff 020101 0201 00", out: "310a020100020101020200ff"},
+		{in: "3106 8101ff 800100", out: "3106800100810 1ff"},
+		{in: "a080 3080 0101 01 0000 0000", out: "a0053003 0101ff"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		raw, err := asn1.DecodeRawValue(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%d. %s: unexpected error: %s", i, tt.in, err)
+		}
+
+		der, err := asn1.ToDER(raw)
+		if err != nil {
+			t.Fatalf("%d. %s: unexpected error: %s", i, tt.in, err)
+		}
+
+		out, err := der.Encode()
+		if err != nil {
+			t.Fatalf("%d. %s: unexpected error: %s", i, tt.in, err)
+		}
+
+		if hex.EncodeToString(out) != stripSpaces(tt.out) {
+			t.Errorf("%d. %s: output mismatch: exp=%s got=%x", i, tt.in, stripSpaces(tt.out), out)
+		}
+
+		if _, err := asn1.DecodeDER(bytes.NewReader(out)); err != nil {
+			t.Errorf("%d. %s: output is not DER: %s", i, tt.in, err)
+		}
+	}
+}
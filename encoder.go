@@ -0,0 +1,179 @@
+package asn1
+
+import (
+	"io"
+)
+
+// An Encoder writes BER encoded values to an output stream.
+type Encoder struct {
+	w   io.Writer
+	ctx *Context
+
+	// open contains the tags of the indefinite length values started with
+	// Start that have not been ended yet.
+	open []ASNTag
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w:   w,
+		ctx: NewContext(),
+	}
+}
+
+// Encode writes the BER encoding of v to the stream. RawValues are written
+// as is, other values are marshaled first, see Marshal.
+func (e *Encoder) Encode(v interface{}) error {
+	switch raw := v.(type) {
+	case *RawValue:
+		return e.EncodeRawValue(raw)
+	case RawValue:
+		return e.EncodeRawValue(&raw)
+	}
+
+	data, err := e.ctx.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+// EncodeRawValue writes the BER encoding of raw to the stream.
+func (e *Encoder) EncodeRawValue(raw *RawValue) error {
+	data, err := raw.Encode()
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(data)
+	return err
+}
+
+// Start writes the identifier and length octets of an indefinite length
+// constructed value with the given tag. All values written until the
+// matching call to End are the children of this value.
+func (e *Encoder) Start(tag ASNTag) error {
+	identifier, err := encodeIdentifier(&RawValue{
+		Tag:         tag,
+		Constructed: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := e.w.Write(append(identifier, 0x80)); err != nil {
+		return err
+	}
+
+	e.open = append(e.open, tag)
+	return nil
+}
+
+// End writes the end-of-contents octets of the value started last.
+func (e *Encoder) End() error {
+	if len(e.open) == 0 {
+		return syntaxError("end without matching start")
+	}
+
+	if _, err := e.w.Write([]byte{0x00, 0x00}); err != nil {
+		return err
+	}
+
+	e.open = e.open[:len(e.open)-1]
+	return nil
+}
+
+// Close checks that all values started with Start have been ended.
+func (e *Encoder) Close() error {
+	if len(e.open) > 0 {
+		return syntaxError("unterminated constructed value %s", e.open[len(e.open)-1])
+	}
+
+	return nil
+}
+
+// A Builder assembles a constructed value from child values, without having
+// to concatenate the encoded children manually.
+//
+//	seq, err := asn1.NewBuilder(asn1.Tag(asn1.ClassUniversal, asn1.TagSequence)).
+//		Add(asn1.NewInteger(1)).
+//		Add(asn1.NewBuilder(asn1.Tag(asn1.ClassContextSpecific, 0)).Add(true)).
+//		RawValue()
+type Builder struct {
+	raw RawValue
+	ctx *Context
+	err error
+}
+
+// NewBuilder returns a Builder for a constructed value with the given tag.
+func NewBuilder(tag ASNTag) *Builder {
+	return &Builder{
+		raw: RawValue{
+			Tag:         tag,
+			Constructed: true,
+			Content:     []byte{},
+		},
+		ctx: NewContext(),
+	}
+}
+
+// Indefinite marks the value to be encoded using the indefinite length form.
+func (b *Builder) Indefinite() *Builder {
+	b.raw.Indefinite = true
+	return b
+}
+
+// Add appends a child value. The child can be a *RawValue, a *Builder or any
+// value that can be marshaled. The first error encountered is returned by
+// RawValue.
+func (b *Builder) Add(v interface{}) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	var data []byte
+
+	switch child := v.(type) {
+	case *Builder:
+		raw, err := child.RawValue()
+		if err != nil {
+			b.err = err
+			return b
+		}
+
+		data, b.err = raw.Encode()
+	case *RawValue:
+		data, b.err = child.Encode()
+	case RawValue:
+		data, b.err = child.Encode()
+	default:
+		data, b.err = b.ctx.Marshal(v)
+	}
+
+	b.raw.Content = append(b.raw.Content, data...)
+	return b
+}
+
+// RawValue returns the assembled constructed value.
+func (b *Builder) RawValue() (*RawValue, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	raw := b.raw
+	raw.Content = append([]byte{}, b.raw.Content...)
+	return &raw, nil
+}
+
+// Encode returns the BER encoding of the assembled constructed value.
+func (b *Builder) Encode() ([]byte, error) {
+	raw, err := b.RawValue()
+	if err != nil {
+		return nil, err
+	}
+
+	return raw.Encode()
+}
@@ -0,0 +1,62 @@
+package asn1_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestBuilder(t *testing.T) {
+	data, err := asn1.NewBuilder(asn1.Tag(asn1.ClassUniversal, asn1.TagSequence)).
+		Add(asn1.NewInteger(1)).
+		Add(asn1.NewBuilder(asn1.Tag(asn1.ClassContextSpecific, 0)).Indefinite().Add(true)).
+		Add(&asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagNull), Content: []byte{}}).
+		Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if exp := "300c020101a0800101ff00000500"; hex.EncodeToString(data) != exp {
+		t.Errorf("output mismatch: exp=%s got=%x", exp, data)
+	}
+
+	raw, err := asn1.DecodeRawValue(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(raw.Content) != 12 {
+		t.Errorf("content length mismatch: got %d", len(raw.Content))
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	e := asn1.NewEncoder(buf)
+	if err := e.Start(asn1.Tag(asn1.ClassUniversal, asn1.TagSequence)); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := e.Encode(5); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := e.Encode("a"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := e.End(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if exp := "30800201050c01610000"; hex.EncodeToString(buf.Bytes()) != exp {
+		t.Errorf("output mismatch: exp=%s got=%x", exp, buf.Bytes())
+	}
+
+	if err := e.End(); err == nil {
+		t.Errorf("expected error for unmatched end")
+	}
+}
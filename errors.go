@@ -0,0 +1,225 @@
+package asn1
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Kinds of errors. The errors returned by the package match their kind with
+// errors.Is, eg. errors.Is(err, ErrTruncated).
+var (
+	// ErrTruncated is the kind of error for input that ends within a value,
+	// it also matches io.ErrUnexpectedEOF.
+	ErrTruncated = fmt.Errorf("truncated input: %w", io.ErrUnexpectedEOF)
+	// ErrInvalidLength is the kind of error for invalid length octets, or a
+	// number of content octets that is invalid for the type of the value.
+	ErrInvalidLength = errors.New("invalid length")
+	// ErrTagMismatch is the kind of error for values with another tag than
+	// expected.
+	ErrTagMismatch = errors.New("tag mismatch")
+	// ErrConstraint is the kind of error for values that are outside the
+	// range of the type they are decoded into, or characters outside the
+	// alphabet of a character string type.
+	ErrConstraint = errors.New("constraint violation")
+	// ErrUnsupportedType is the kind of error for Go types that can not be
+	// marshaled or unmarshaled.
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrNotDER is the kind of error for values that are valid BER, but
+	// violate a restriction of the Distinguished Encoding Rules, see
+	// DERError.
+	ErrNotDER = errors.New("not DER encoded")
+)
+
+// ParseError is returned by the package to indicate that the given data is
+// invalid.
+type ParseError struct {
+	Msg string
+	// Err is the kind of error, nil when the error is of no specific kind.
+	Err error
+	// Offset of the identifier octets of the invalid value in the input,
+	// -1 when unknown.
+	Offset int64
+	// Path names the values leading to the invalid value, starting with the
+	// top level value. Values are named by their Go type, struct field or
+	// slice index, or by their tag, eg. "[CONTEXT 0]", when they are decoded
+	// without Go types. Struct fields are named by the name option of their
+	// struct tag, eg. `asn1:"name:tbsCertificate"`, or else by their Go name.
+	Path []string
+}
+
+// Error returns the error message of a ParseError, prefixed with the offset
+// and path when known, eg. "offset 0x1a3: Certificate.tbsCertificate.validity:
+// multi byte length too big".
+func (e *ParseError) Error() string {
+	return positioned(e.Offset, e.Path, message(e.Msg, e.Err))
+}
+
+// Unwrap returns the kind of error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseError allocates a new ParseError.
+func parseError(msg string, args ...interface{}) *ParseError {
+	return kindError(nil, msg, args...)
+}
+
+// kindError allocates a new ParseError of the given kind.
+func kindError(kind error, msg string, args ...interface{}) *ParseError {
+	return &ParseError{
+		Msg:    fmt.Sprintf(msg, args...),
+		Err:    kind,
+		Offset: -1,
+	}
+}
+
+// SyntaxError is returned by the package to indicate that the given value or
+// struct is invalid.
+type SyntaxError struct {
+	Msg string
+	// Err is the kind of error, nil when the error is of no specific kind.
+	Err error
+	// Path names the values leading to the invalid value, see ParseError.
+	Path []string
+}
+
+// Error returns the error message of a SyntaxError, prefixed with the path
+// when known.
+func (e *SyntaxError) Error() string {
+	return positioned(-1, e.Path, message(e.Msg, e.Err))
+}
+
+// Unwrap returns the kind of error.
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// syntaxError allocates a new SyntaxError.
+func syntaxError(msg string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		Msg: fmt.Sprintf(msg, args...),
+	}
+}
+
+// unsupportedType allocates a new SyntaxError for a type that can not be
+// marshaled or unmarshaled.
+func unsupportedType(t reflect.Type) *SyntaxError {
+	return &SyntaxError{
+		Msg: fmt.Sprintf("unsupported type: %s", t),
+		Err: ErrUnsupportedType,
+	}
+}
+
+// message returns msg, or the message of the kind of error when msg is empty.
+func message(msg string, kind error) string {
+	if msg == "" && kind != nil {
+		return kind.Error()
+	}
+
+	return msg
+}
+
+// positioned prefixes msg with the offset, when not negative, and path.
+func positioned(offset int64, path []string, msg string) string {
+	if len(path) > 0 {
+		var sb strings.Builder
+		for i, name := range path {
+			if i > 0 && !strings.HasPrefix(name, "[") {
+				sb.WriteByte('.')
+			}
+
+			sb.WriteString(name)
+		}
+
+		msg = fmt.Sprintf("%s: %s", sb.String(), msg)
+	}
+
+	if offset >= 0 {
+		msg = fmt.Sprintf("offset %#x: %s", offset, msg)
+	}
+
+	return msg
+}
+
+// unexpectedEOF converts io.EOF into io.ErrUnexpectedEOF, for reads that
+// may not end the input.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// errorAt records offset in err when err is a *ParseError or *DERError
+// without offset. An io.ErrUnexpectedEOF is converted into a *ParseError of
+// kind ErrTruncated.
+func errorAt(err error, offset int64) error {
+	if err == io.ErrUnexpectedEOF {
+		return &ParseError{
+			Err:    ErrTruncated,
+			Offset: offset,
+		}
+	}
+
+	switch e := err.(type) {
+	case *ParseError:
+		if e.Offset < 0 {
+			e.Offset = offset
+		}
+	case *DERError:
+		if e.Offset < 0 {
+			e.Offset = offset
+		}
+	}
+
+	return err
+}
+
+// errorIn records offset in err, see errorAt, and prepends the tags of the
+// constructed values in stack, outermost first, to the path of err.
+func errorIn(err error, offset int64, stack []frame) error {
+	err = errorAt(err, offset)
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		err = errorPath(err, stack[i].tag.String())
+	}
+
+	return err
+}
+
+// typePath prepends the name of the Go type of value to the path of err.
+// Values of unnamed types are not named.
+func typePath(err error, value reflect.Value) error {
+	if err == nil || !value.IsValid() {
+		return err
+	}
+
+	t := value.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Name() == "" {
+		return err
+	}
+
+	return errorPath(err, t.Name())
+}
+
+// errorPath prepends name to the path of err, when err is a *ParseError,
+// *SyntaxError or *DERError.
+func errorPath(err error, name string) error {
+	switch e := err.(type) {
+	case *ParseError:
+		e.Path = append([]string{name}, e.Path...)
+	case *SyntaxError:
+		e.Path = append([]string{name}, e.Path...)
+	case *DERError:
+		e.Path = append([]string{name}, e.Path...)
+	}
+
+	return err
+}
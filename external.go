@@ -0,0 +1,503 @@
+package asn1
+
+// ExternalEncoding is the encoding alternative of an External value.
+type ExternalEncoding int
+
+// Encoding alternatives of EXTERNAL (X.690 8.18.1).
+const (
+	// ExternalSingleASN1Type is a single ASN.1 value, explicitly tagged [0].
+	ExternalSingleASN1Type ExternalEncoding = iota
+	// ExternalOctetAligned is an integral number of octets, tagged [1].
+	ExternalOctetAligned
+	// ExternalArbitrary is an arbitrary number of bits, tagged [2].
+	ExternalArbitrary
+)
+
+// External is an EXTERNAL value (X.690 8.18), a value of a type that is not
+// defined in the module, eg. the user information of ACSE. At least one of
+// DirectReference and IndirectReference identifies the type.
+type External struct {
+	// DirectReference is the OBJECT IDENTIFIER of the type, it is the zero
+	// value when absent.
+	DirectReference ObjectIdentifier
+	// IndirectReference is the presentation context identifier, nil when
+	// absent.
+	IndirectReference *int64
+	// DataValueDescriptor describes the value, nil when absent.
+	DataValueDescriptor *ObjectDescriptor
+
+	// Encoding selects which of the fields below holds the value.
+	Encoding       ExternalEncoding
+	SingleASN1Type RawValue
+	OctetAligned   []byte
+	Arbitrary      BitString
+}
+
+func (s *External) UnmarshalRawValue(rv *RawValue) error {
+	if !rv.Constructed {
+		return parseError("EXTERNAL must be constructed")
+	}
+
+	var ext External
+	var encoded bool
+
+	it := rv.Children()
+	for it.Next() {
+		child := it.Value()
+
+		if encoded {
+			return parseError("unexpected value %s after encoding of EXTERNAL", child.Tag)
+		}
+
+		switch {
+		case child.Tag == Tag(ClassUniversal, TagOid) && ext.DirectReference.string == "" && ext.IndirectReference == nil && ext.DataValueDescriptor == nil:
+			if err := ext.DirectReference.UnmarshalRawValue(&child); err != nil {
+				return err
+			}
+		case child.Tag == Tag(ClassUniversal, TagInteger) && ext.IndirectReference == nil && ext.DataValueDescriptor == nil:
+			v, err := decodeInt64(&child, "indirect-reference of EXTERNAL")
+			if err != nil {
+				return err
+			}
+
+			ext.IndirectReference = &v
+		case child.Tag == Tag(ClassUniversal, TagObjectDescriptor) && ext.DataValueDescriptor == nil:
+			ext.DataValueDescriptor = new(ObjectDescriptor)
+			if err := ext.DataValueDescriptor.UnmarshalRawValue(&child); err != nil {
+				return err
+			}
+		case child.Tag == Tag(ClassContextSpecific, 0):
+			value, err := decodeExplicit(&child, "single-ASN1-type of EXTERNAL")
+			if err != nil {
+				return err
+			}
+
+			ext.Encoding, ext.SingleASN1Type, encoded = ExternalSingleASN1Type, value, true
+		case child.Tag == Tag(ClassContextSpecific, 1):
+			data, err := joinSegments(&child, TagOctetString)
+			if err != nil {
+				return err
+			}
+
+			ext.Encoding, ext.OctetAligned, encoded = ExternalOctetAligned, data, true
+		case child.Tag == Tag(ClassContextSpecific, 2):
+			if err := ext.Arbitrary.UnmarshalRawValue(&child); err != nil {
+				return err
+			}
+
+			ext.Encoding, encoded = ExternalArbitrary, true
+		default:
+			return kindError(ErrTagMismatch, "unexpected value %s in EXTERNAL", child.Tag)
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if !encoded {
+		return parseError("missing encoding of EXTERNAL")
+	}
+
+	if err := ext.check(); err != nil {
+		return kindError(ErrConstraint, "%s", err.Msg)
+	}
+
+	*s = ext
+	return nil
+}
+
+func (s External) MarshalRawValue() (*RawValue, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+
+	b := NewBuilder(Tag(ClassUniversal, TagExternal))
+
+	if s.DirectReference.string != "" {
+		b.Add(s.DirectReference)
+	}
+
+	if s.IndirectReference != nil {
+		b.Add(NewInteger(*s.IndirectReference))
+	}
+
+	if s.DataValueDescriptor != nil {
+		b.Add(*s.DataValueDescriptor)
+	}
+
+	switch s.Encoding {
+	case ExternalSingleASN1Type:
+		b.Add(NewBuilder(Tag(ClassContextSpecific, 0)).Add(&s.SingleASN1Type))
+	case ExternalOctetAligned:
+		b.Add(&RawValue{Tag: Tag(ClassContextSpecific, 1), Content: s.OctetAligned})
+	case ExternalArbitrary:
+		raw, err := s.Arbitrary.MarshalRawValue()
+		if err != nil {
+			return nil, err
+		}
+
+		raw.Tag = Tag(ClassContextSpecific, 2)
+		b.Add(raw)
+	}
+
+	return b.RawValue()
+}
+
+// check validates the references and encoding alternative of s.
+func (s External) check() *SyntaxError {
+	switch {
+	case s.DirectReference.string == "" && s.IndirectReference == nil:
+		return &SyntaxError{Msg: "EXTERNAL has neither a direct nor an indirect reference", Err: ErrConstraint}
+	case s.Encoding < ExternalSingleASN1Type || s.Encoding > ExternalArbitrary:
+		return &SyntaxError{Msg: "invalid encoding alternative of EXTERNAL", Err: ErrConstraint}
+	}
+
+	return nil
+}
+
+// Identification identifies the abstract and transfer syntax of an EMBEDDED
+// PDV or CHARACTER STRING value (X.680 36.5 and 44.5). Exactly one of the
+// alternatives is set.
+type Identification struct {
+	Syntaxes              *Syntaxes
+	Syntax                *ObjectIdentifier
+	PresentationContextID *int64
+	ContextNegotiation    *ContextNegotiation
+	TransferSyntax        *ObjectIdentifier
+	// Fixed is set when the syntaxes are fixed by the application designer.
+	Fixed bool
+}
+
+// Syntaxes are the abstract and transfer syntax of an Identification.
+type Syntaxes struct {
+	Abstract ObjectIdentifier
+	Transfer ObjectIdentifier
+}
+
+// ContextNegotiation is the presentation context and transfer syntax of an
+// Identification, used during presentation context negotiation.
+type ContextNegotiation struct {
+	PresentationContextID int64
+	TransferSyntax        ObjectIdentifier
+}
+
+// EmbeddedPDV is an EMBEDDED PDV value (X.680 36), the encoding of a value of
+// an abstract syntax identified by Identification.
+type EmbeddedPDV struct {
+	Identification Identification
+	DataValue      []byte
+}
+
+func (s *EmbeddedPDV) UnmarshalRawValue(rv *RawValue) error {
+	id, data, err := decodeIdentified(rv, "EMBEDDED PDV")
+	if err != nil {
+		return err
+	}
+
+	*s = EmbeddedPDV{id, data}
+	return nil
+}
+
+func (s EmbeddedPDV) MarshalRawValue() (*RawValue, error) {
+	return encodeIdentified(TagEmbeddedPDV, s.Identification, s.DataValue)
+}
+
+// CharacterString is an unrestricted CHARACTER STRING value (X.680 44), a
+// string of the character abstract syntax identified by Identification.
+type CharacterString struct {
+	Identification Identification
+	StringValue    []byte
+}
+
+func (s *CharacterString) UnmarshalRawValue(rv *RawValue) error {
+	id, data, err := decodeIdentified(rv, "CHARACTER STRING")
+	if err != nil {
+		return err
+	}
+
+	*s = CharacterString{id, data}
+	return nil
+}
+
+func (s CharacterString) MarshalRawValue() (*RawValue, error) {
+	return encodeIdentified(TagCharacterString, s.Identification, s.StringValue)
+}
+
+// decodeIdentified decodes the associated type of EMBEDDED PDV and
+// CHARACTER STRING, a SEQUENCE with automatic tags of the identification
+// [0] and the data value [2]. The data value descriptor [1] is always
+// absent (X.680 36.5 and 44.5).
+func decodeIdentified(rv *RawValue, name string) (Identification, []byte, error) {
+	if !rv.Constructed {
+		return Identification{}, nil, parseError("%s must be constructed", name)
+	}
+
+	var children []RawValue
+
+	it := rv.Children()
+	for it.Next() {
+		children = append(children, it.Value())
+	}
+
+	if err := it.Err(); err != nil {
+		return Identification{}, nil, err
+	}
+
+	if len(children) != 2 {
+		return Identification{}, nil, parseError("%s must have 2 components, got %d", name, len(children))
+	}
+
+	if children[0].Tag != Tag(ClassContextSpecific, 0) {
+		return Identification{}, nil, kindError(ErrTagMismatch, "unexpected identification %s in %s", children[0].Tag, name)
+	}
+
+	if children[1].Tag != Tag(ClassContextSpecific, 2) {
+		return Identification{}, nil, kindError(ErrTagMismatch, "unexpected data value %s in %s", children[1].Tag, name)
+	}
+
+	choice, err := decodeExplicit(&children[0], "identification of "+name)
+	if err != nil {
+		return Identification{}, nil, err
+	}
+
+	id, err := decodeIdentification(&choice)
+	if err != nil {
+		return Identification{}, nil, err
+	}
+
+	data, err := joinSegments(&children[1], TagOctetString)
+	if err != nil {
+		return Identification{}, nil, err
+	}
+
+	return id, data, nil
+}
+
+// decodeIdentification decodes the chosen alternative of identification.
+func decodeIdentification(rv *RawValue) (Identification, error) {
+	if rv.Tag.Class != ClassContextSpecific {
+		return Identification{}, kindError(ErrTagMismatch, "unexpected identification %s", rv.Tag)
+	}
+
+	var id Identification
+
+	switch rv.Tag.Value {
+	case 0:
+		var oids []ObjectIdentifier
+		if err := decodePair(rv, "syntaxes", func(child *RawValue) error {
+			var oid ObjectIdentifier
+			err := oid.UnmarshalRawValue(child)
+			oids = append(oids, oid)
+			return err
+		}, nil); err != nil {
+			return Identification{}, err
+		}
+
+		id.Syntaxes = &Syntaxes{Abstract: oids[0], Transfer: oids[1]}
+	case 1, 4:
+		var oid ObjectIdentifier
+		if err := oid.UnmarshalRawValue(rv); err != nil {
+			return Identification{}, err
+		}
+
+		if rv.Tag.Value == 1 {
+			id.Syntax = &oid
+		} else {
+			id.TransferSyntax = &oid
+		}
+	case 2:
+		v, err := decodeInt64(rv, "presentation-context-id")
+		if err != nil {
+			return Identification{}, err
+		}
+
+		id.PresentationContextID = &v
+	case 3:
+		var cn ContextNegotiation
+		if err := decodePair(rv, "context-negotiation", func(child *RawValue) (err error) {
+			cn.PresentationContextID, err = decodeInt64(child, "presentation-context-id")
+			return err
+		}, cn.TransferSyntax.UnmarshalRawValue); err != nil {
+			return Identification{}, err
+		}
+
+		id.ContextNegotiation = &cn
+	case 5:
+		if len(rv.Content) != 0 {
+			return Identification{}, kindError(ErrInvalidLength, "invalid fixed identification length: %d", len(rv.Content))
+		}
+
+		id.Fixed = true
+	default:
+		return Identification{}, kindError(ErrTagMismatch, "unexpected identification %s", rv.Tag)
+	}
+
+	return id, nil
+}
+
+// decodePair decodes the SEQUENCE rv of the components [0] and [1], second
+// is the same as first when nil.
+func decodePair(rv *RawValue, name string, first, second func(*RawValue) error) error {
+	if second == nil {
+		second = first
+	}
+
+	var n ASNValue
+
+	it := rv.Children()
+	for it.Next() {
+		child := it.Value()
+
+		if n > 1 || child.Tag != Tag(ClassContextSpecific, n) {
+			return kindError(ErrTagMismatch, "unexpected value %s in %s", child.Tag, name)
+		}
+
+		decode := first
+		if n == 1 {
+			decode = second
+		}
+
+		if err := decode(&child); err != nil {
+			return err
+		}
+
+		n++
+	}
+
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	if n != 2 {
+		return parseError("%s must have 2 components, got %d", name, n)
+	}
+
+	return nil
+}
+
+// encodeIdentified encodes the associated type of EMBEDDED PDV and
+// CHARACTER STRING, see decodeIdentified.
+func encodeIdentified(tag ASNValue, id Identification, data []byte) (*RawValue, error) {
+	choice, err := encodeIdentification(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBuilder(Tag(ClassUniversal, tag)).
+		Add(NewBuilder(Tag(ClassContextSpecific, 0)).Add(choice)).
+		Add(&RawValue{Tag: Tag(ClassContextSpecific, 2), Content: data}).
+		RawValue()
+}
+
+// encodeIdentification encodes the chosen alternative of id.
+func encodeIdentification(id Identification) (*RawValue, error) {
+	// implicitly tagged returns the universal value v with the context
+	// specific tag n
+	implicit := func(n ASNValue, v Marshaler) (*RawValue, error) {
+		raw, err := v.MarshalRawValue()
+		if err != nil {
+			return nil, err
+		}
+
+		raw.Tag = Tag(ClassContextSpecific, n)
+		return raw, nil
+	}
+
+	var choices []*RawValue
+	var errs []error
+
+	add := func(n ASNValue, v Marshaler) {
+		raw, err := implicit(n, v)
+		choices, errs = append(choices, raw), append(errs, err)
+	}
+
+	if id.Syntaxes != nil {
+		abstract, err := implicit(0, id.Syntaxes.Abstract)
+		if err != nil {
+			return nil, err
+		}
+
+		transfer, err := implicit(1, id.Syntaxes.Transfer)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := NewBuilder(Tag(ClassContextSpecific, 0)).Add(abstract).Add(transfer).RawValue()
+		choices, errs = append(choices, raw), append(errs, err)
+	}
+
+	if id.Syntax != nil {
+		add(1, *id.Syntax)
+	}
+
+	if id.PresentationContextID != nil {
+		add(2, NewInteger(*id.PresentationContextID))
+	}
+
+	if cn := id.ContextNegotiation; cn != nil {
+		transfer, err := implicit(1, cn.TransferSyntax)
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := NewBuilder(Tag(ClassContextSpecific, 3)).
+			Add(&RawValue{Tag: Tag(ClassContextSpecific, 0), Content: encodeInt64(cn.PresentationContextID)}).
+			Add(transfer).
+			RawValue()
+		choices, errs = append(choices, raw), append(errs, err)
+	}
+
+	if id.TransferSyntax != nil {
+		add(4, *id.TransferSyntax)
+	}
+
+	if id.Fixed {
+		add(5, Null{})
+	}
+
+	if len(choices) != 1 {
+		return nil, &SyntaxError{Msg: "identification must have exactly one alternative", Err: ErrConstraint}
+	}
+
+	return choices[0], errs[0]
+}
+
+// decodeExplicit returns the single value contained in the explicitly
+// tagged value rv.
+func decodeExplicit(rv *RawValue, name string) (RawValue, error) {
+	if !rv.Constructed {
+		return RawValue{}, parseError("%s must be constructed", name)
+	}
+
+	var values []RawValue
+
+	it := rv.Children()
+	for it.Next() {
+		values = append(values, it.Value())
+	}
+
+	if err := it.Err(); err != nil {
+		return RawValue{}, err
+	}
+
+	if len(values) != 1 {
+		return RawValue{}, parseError("%s must contain one value, got %d", name, len(values))
+	}
+
+	return values[0], nil
+}
+
+// decodeInt64 decodes the INTEGER rv, which can not be empty.
+func decodeInt64(rv *RawValue, name string) (int64, error) {
+	if len(rv.Content) == 0 {
+		return 0, kindError(ErrInvalidLength, "empty %s", name)
+	}
+
+	var i Integer
+	if err := i.UnmarshalRawValue(rv); err != nil {
+		return 0, err
+	}
+
+	return i.int64, nil
+}
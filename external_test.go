@@ -0,0 +1,190 @@
+package asn1_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestExternal(t *testing.T) {
+	var tests = []struct {
+		in       string
+		direct   string
+		indirect int64
+		encoding asn1.ExternalEncoding
+	}{
+		{in: "28 0c 06025101 020101 a003020105", direct: "2.1.1", indirect: 1, encoding: asn1.ExternalSingleASN1Type},
+		{in: "28 07 020103 8102abcd", indirect: 3, encoding: asn1.ExternalOctetAligned},
+		{in: "28 08 06025101 82020780", direct: "2.1.1", indirect: -1, encoding: asn1.ExternalArbitrary},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		var ext asn1.External
+		if err := asn1.Unmarshal(data, &ext); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if ext.DirectReference.String() != tt.direct {
+			t.Errorf("%d. direct reference mismatch: exp=%s got=%s", i, tt.direct, ext.DirectReference)
+		}
+
+		if tt.indirect < 0 && ext.IndirectReference != nil || tt.indirect >= 0 && (ext.IndirectReference == nil || *ext.IndirectReference != tt.indirect) {
+			t.Errorf("%d. indirect reference mismatch: exp=%d got=%v", i, tt.indirect, ext.IndirectReference)
+		}
+
+		if ext.Encoding != tt.encoding {
+			t.Errorf("%d. encoding mismatch: exp=%d got=%d", i, tt.encoding, ext.Encoding)
+		}
+
+		out, err := asn1.Marshal(ext)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+			t.Errorf("%d. encoding mismatch: exp=%x got=%x", i, data, out)
+		}
+	}
+
+	if _, err := asn1.Marshal(asn1.External{Encoding: asn1.ExternalOctetAligned}); !errors.Is(err, asn1.ErrConstraint) {
+		t.Errorf("expected error of kind ErrConstraint, got %v", err)
+	}
+}
+
+func TestExternal_Errors(t *testing.T) {
+	var tests = []string{
+		"0800",
+		"28 05 a003020105",
+		"28 03 020101",
+		"28 08 020101 06025101 8100",
+		"28 07 06025101 8300",
+		"28 0a 06025101 8100 8102abcd",
+		"28 06 06025101 8000",
+		"28 0b 06025101 a0050500 0500",
+	}
+
+	for i, in := range tests {
+		data, _ := hex.DecodeString(stripSpaces(in))
+
+		var ext asn1.External
+		if err := asn1.Unmarshal(data, &ext); err == nil {
+			t.Errorf("%d. %s: expected error", i, in)
+		}
+	}
+}
+
+func TestEmbeddedPDV(t *testing.T) {
+	var tests = []struct {
+		in    string
+		check func(id asn1.Identification) bool
+	}{
+		{
+			in: "2b 10 a00a a008 80025101 81025102 8202abcd",
+			check: func(id asn1.Identification) bool {
+				return id.Syntaxes != nil && id.Syntaxes.Abstract.String() == "2.1.1" && id.Syntaxes.Transfer.String() == "2.1.2"
+			},
+		},
+		{
+			in:    "2b 0a a004 81025101 8202abcd",
+			check: func(id asn1.Identification) bool { return id.Syntax != nil && id.Syntax.String() == "2.1.1" },
+		},
+		{
+			in: "2b 09 a003 820107 8202abcd",
+			check: func(id asn1.Identification) bool {
+				return id.PresentationContextID != nil && *id.PresentationContextID == 7
+			},
+		},
+		{
+			in: "2b 0f a009 a307 800101 81025101 8202abcd",
+			check: func(id asn1.Identification) bool {
+				cn := id.ContextNegotiation
+				return cn != nil && cn.PresentationContextID == 1 && cn.TransferSyntax.String() == "2.1.1"
+			},
+		},
+		{
+			in: "2b 0a a004 84025101 8202abcd",
+			check: func(id asn1.Identification) bool {
+				return id.TransferSyntax != nil && id.TransferSyntax.String() == "2.1.1"
+			},
+		},
+		{
+			in:    "2b 08 a002 8500 8202abcd",
+			check: func(id asn1.Identification) bool { return id.Fixed },
+		},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		var pdv asn1.EmbeddedPDV
+		if err := asn1.Unmarshal(data, &pdv); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if !tt.check(pdv.Identification) {
+			t.Errorf("%d. unexpected identification: %+v", i, pdv.Identification)
+		}
+
+		if hex.EncodeToString(pdv.DataValue) != "abcd" {
+			t.Errorf("%d. data value mismatch: got=%x", i, pdv.DataValue)
+		}
+
+		out, err := asn1.Marshal(pdv)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+			t.Errorf("%d. encoding mismatch: exp=%x got=%x", i, data, out)
+		}
+	}
+}
+
+func TestEmbeddedPDV_Errors(t *testing.T) {
+	var tests = []string{
+		"0b00",
+		"2b 06 a004 81025101",
+		"2b 08 a002 8600 8202abcd",
+		"2b 09 a003 850100 8202abcd",
+		"2b 08 a004 81025101 8100",
+		"2b 0c a006 a3 04 800101 8202abcd",
+	}
+
+	for i, in := range tests {
+		data, _ := hex.DecodeString(stripSpaces(in))
+
+		var pdv asn1.EmbeddedPDV
+		if err := asn1.Unmarshal(data, &pdv); err == nil {
+			t.Errorf("%d. %s: expected error", i, in)
+		}
+	}
+
+	oid := asn1.NewObjectIdentifier(2, 1, 1)
+	for i, id := range []asn1.Identification{{}, {Syntax: &oid, Fixed: true}} {
+		if _, err := asn1.Marshal(asn1.EmbeddedPDV{Identification: id}); !errors.Is(err, asn1.ErrConstraint) {
+			t.Errorf("%d. expected error of kind ErrConstraint, got %v", i, err)
+		}
+	}
+}
+
+func TestCharacterString(t *testing.T) {
+	data, _ := hex.DecodeString(stripSpaces("3d 0a a004 84025101 8202abcd"))
+
+	var s asn1.CharacterString
+	if err := asn1.Unmarshal(data, &s); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if s.Identification.TransferSyntax == nil || hex.EncodeToString(s.StringValue) != "abcd" {
+		t.Errorf("unexpected value: %+v", s)
+	}
+
+	out, err := asn1.Marshal(s)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+		t.Errorf("encoding mismatch: exp=%x got=%x", data, out)
+	}
+}
@@ -0,0 +1,337 @@
+package asn1jer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// parseJSON parses a JSON document, numbers are kept as json.Number to
+// preserve large integers.
+func parseJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("jer: %s", err)
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("jer: trailing data after document")
+	}
+
+	return v, nil
+}
+
+// jsonType returns the name of the JSON type of v, used in errors.
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return fmt.Sprintf("%T", v)
+}
+
+// decoder reads the values of a JSON document into BER encoded values.
+type decoder struct {
+	*Codec
+}
+
+// decodeItem decodes v, the value of the i-th item of a SEQUENCE, SET or
+// CHOICE.
+func (d *decoder) decodeItem(items []asn1parser.ASNItem, i int, v interface{}) (*asn1.RawValue, error) {
+	raw, err := d.decodeBuiltin(items[i].Type, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.def.TagItem(items, i, raw)
+}
+
+// decodeType decodes v, a value of type t.
+func (d *decoder) decodeType(t asn1parser.ASNType, v interface{}) (*asn1.RawValue, error) {
+	raw, err := d.decodeBuiltin(t, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.def.TagType(t, raw)
+}
+
+// decodeBuiltin decodes v, a value of the builtin type t refers to.
+func (d *decoder) decodeBuiltin(t asn1parser.ASNType, v interface{}) (*asn1.RawValue, error) {
+	builtin := d.def.Resolve(t)
+
+	// the choice value is the value of the alternative
+	if c, ok := builtin.(*asn1parser.ASNChoice); ok {
+		members, ok := v.(map[string]interface{})
+		if !ok || len(members) != 1 {
+			return nil, fmt.Errorf("jer: expected object with a single member for %s", c.Name())
+		}
+
+		for name, value := range members {
+			for i, item := range c.Items {
+				if !item.TripleDot && item.Name == name {
+					return d.decodeItem(c.Items, i, value)
+				}
+			}
+
+			return nil, fmt.Errorf("jer: unknown alternative %s of %s", name, c.Name())
+		}
+	}
+
+	value, ok := asn1parser.UniversalTag(builtin)
+	if !ok {
+		return nil, fmt.Errorf("jer: unsupported type %s", builtin.Name())
+	}
+
+	raw := &asn1.RawValue{
+		Tag: asn1.Tag(asn1.ClassUniversal, value),
+	}
+
+	expected := "string"
+
+	switch b := builtin.(type) {
+	case *asn1parser.ASNSequence:
+		if b.Of != "" {
+			return d.decodeSequenceOf(b, v)
+		}
+
+		return d.decodeSequence(b.Items, v, raw.Tag)
+	case *asn1parser.ASNSet:
+		return d.decodeSequence(b.Items, v, raw.Tag)
+	case *asn1parser.ASNInteger:
+		expected = "number"
+
+		if s, ok := v.(json.Number); ok {
+			n, ok := new(big.Int).SetString(string(s), 10)
+			if !ok {
+				return nil, fmt.Errorf("jer: invalid INTEGER value %s", s)
+			}
+
+			raw.Content = asn1.EncodeInteger(n)
+			return raw, nil
+		}
+	case *asn1parser.ASNEnumerated:
+		if s, ok := v.(string); ok {
+			_, values, err := b.Numbers()
+			if err != nil {
+				return nil, err
+			}
+
+			n, ok := values[s]
+			if !ok {
+				return nil, fmt.Errorf("jer: invalid value %s of %s", s, b.Name())
+			}
+
+			raw.Content = asn1.EncodeInteger(big.NewInt(n))
+			return raw, nil
+		}
+	case *asn1parser.ASNBitString:
+		content, err := d.decodeBits(t, v)
+		if err != nil {
+			return nil, err
+		}
+
+		raw.Content = content
+		return raw, nil
+	case *asn1parser.ASNOctetString:
+		if s, ok := v.(string); ok {
+			content, err := hex.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("jer: invalid OCTET STRING value: %s", err)
+			}
+
+			raw.Content = content
+			return raw, nil
+		}
+	case *asn1parser.ASNObjectIdentifier:
+		if s, ok := v.(string); ok {
+			oid, err := asn1.ParseObjectIdentifier(s)
+			if err != nil {
+				return nil, err
+			}
+
+			rv, err := oid.MarshalRawValue()
+			if err != nil {
+				return nil, err
+			}
+
+			raw.Content = rv.Content
+			return raw, nil
+		}
+	default:
+		switch value {
+		case asn1.TagBoolean:
+			expected = "boolean"
+
+			if b, ok := v.(bool); ok {
+				raw.Content = []byte{0x00}
+				if b {
+					raw.Content[0] = 0xff
+				}
+
+				return raw, nil
+			}
+		case asn1.TagNull:
+			expected = "null"
+
+			if v == nil {
+				raw.Content = []byte{}
+				return raw, nil
+			}
+		case asn1.TagReal:
+			return nil, fmt.Errorf("jer: unsupported type %s", builtin.Name())
+		default:
+			if s, ok := v.(string); ok {
+				raw.Content = []byte(s)
+				return raw, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("jer: found %s, expected %s for %s", jsonType(v), expected, builtin.Name())
+}
+
+// decodeBits decodes the content octets of a BIT STRING of type t. Types
+// with a fixed size are encoded as a hex string, other types as an object
+// with the hex value and the length in bits.
+func (d *decoder) decodeBits(t asn1parser.ASNType, v interface{}) ([]byte, error) {
+	var value interface{}
+	var length int64
+
+	if size, ok := d.fixedSize(t); ok {
+		value, length = v, size
+	} else {
+		members, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("jer: found %s, expected object for BIT STRING", jsonType(v))
+		}
+
+		n, ok := members["length"].(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("jer: missing length of BIT STRING")
+		}
+
+		var err error
+		if length, err = n.Int64(); err != nil || length < 0 {
+			return nil, fmt.Errorf("jer: invalid length %s of BIT STRING", n)
+		}
+
+		value = members["value"]
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("jer: found %s, expected string for BIT STRING", jsonType(value))
+	}
+
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("jer: invalid BIT STRING value: %s", err)
+	}
+
+	if int64(len(data)) != (length+7)/8 {
+		return nil, fmt.Errorf("jer: BIT STRING value of %d octets for %d bits", len(data), length)
+	}
+
+	unused := byte((8 - length%8) % 8)
+	if len(data) > 0 {
+		// unused bits are zero in DER
+		data[len(data)-1] &^= byte(1<<unused) - 1
+	}
+
+	return append([]byte{unused}, data...), nil
+}
+
+// decodeSequence decodes the members of an object into the components of a
+// SEQUENCE or SET.
+func (d *decoder) decodeSequence(items []asn1parser.ASNItem, v interface{}, tag asn1.ASNTag) (*asn1.RawValue, error) {
+	members, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("jer: found %s, expected object", jsonType(v))
+	}
+
+	values := map[int]*asn1.RawValue{}
+
+	for name, member := range members {
+		found := false
+
+		for i, item := range items {
+			if item.TripleDot || item.Name != name {
+				continue
+			}
+
+			value, err := d.decodeItem(items, i, member)
+			if err != nil {
+				return nil, err
+			}
+
+			values[i] = value
+			found = true
+			break
+		}
+
+		if !found {
+			return nil, fmt.Errorf("jer: unknown component %s", name)
+		}
+	}
+
+	root, _, _ := d.def.Components(items)
+	for _, i := range root {
+		if values[i] == nil && !items[i].Optional && items[i].Default == nil {
+			return nil, fmt.Errorf("jer: missing component %s", items[i].Name)
+		}
+	}
+
+	b := asn1.NewBuilder(tag)
+	for i := range items {
+		if values[i] != nil {
+			b.Add(values[i])
+		}
+	}
+
+	return b.RawValue()
+}
+
+// decodeSequenceOf decodes the elements of an array into the components of
+// a SEQUENCE OF.
+func (d *decoder) decodeSequenceOf(s *asn1parser.ASNSequence, v interface{}) (*asn1.RawValue, error) {
+	elements, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("jer: found %s, expected array", jsonType(v))
+	}
+
+	element := d.def.ElementType(s)
+
+	b := asn1.NewBuilder(asn1.Tag(asn1.ClassUniversal, asn1.TagSequence))
+
+	for _, e := range elements {
+		value, err := d.decodeType(element, e)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Add(value)
+	}
+
+	return b.RawValue()
+}
@@ -0,0 +1,239 @@
+package asn1jer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// encoder writes the JER encoding of BER encoded values.
+type encoder struct {
+	*Codec
+
+	buf bytes.Buffer
+}
+
+// writeString writes s as a JSON string.
+func (e *encoder) writeString(s string) error {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(s); err != nil {
+		return err
+	}
+
+	e.buf.Write(bytes.TrimRight(buf.Bytes(), "\n"))
+	return nil
+}
+
+// encodeItem writes raw, the value of the i-th item of a SEQUENCE, SET or
+// CHOICE.
+func (e *encoder) encodeItem(items []asn1parser.ASNItem, i int, raw *asn1.RawValue) error {
+	raw, err := e.def.UntagItem(items, i, raw)
+	if err != nil {
+		return fmt.Errorf("jer: %s", err)
+	}
+
+	return e.encodeBuiltin(items[i].Type, raw)
+}
+
+// encodeType writes raw, a value of type t.
+func (e *encoder) encodeType(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	raw, err := e.def.UntagType(t, raw)
+	if err != nil {
+		return fmt.Errorf("jer: %s", err)
+	}
+
+	return e.encodeBuiltin(t, raw)
+}
+
+// encodeBuiltin writes raw, a value of type t with the tags of t removed.
+func (e *encoder) encodeBuiltin(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	builtin := e.def.Resolve(t)
+
+	switch v := builtin.(type) {
+	case *asn1parser.ASNSequence:
+		if v.Of != "" {
+			return e.encodeSequenceOf(v, raw)
+		}
+
+		return e.encodeSequence(v.Items, raw, false)
+	case *asn1parser.ASNSet:
+		return e.encodeSequence(v.Items, raw, true)
+	case *asn1parser.ASNChoice:
+		i, ok := e.def.MatchAlternative(v.Items, raw.Tag)
+		if !ok {
+			return fmt.Errorf("jer: no alternative of %s matches %s", v.Name(), raw.Tag)
+		}
+
+		e.buf.WriteByte('{')
+
+		if err := e.writeString(v.Items[i].Name); err != nil {
+			return err
+		}
+
+		e.buf.WriteByte(':')
+
+		if err := e.encodeItem(v.Items, i, raw); err != nil {
+			return err
+		}
+
+		e.buf.WriteByte('}')
+	case *asn1parser.ASNInteger:
+		n, err := asn1.ParseInteger(raw.Content)
+		if err != nil {
+			return err
+		}
+
+		e.buf.WriteString(n.String())
+	case *asn1parser.ASNEnumerated:
+		n, err := asn1.ParseInteger(raw.Content)
+		if err != nil {
+			return err
+		}
+
+		name, ok, err := v.NameOf(n.Int64())
+		if err != nil {
+			return err
+		}
+
+		if !ok || !n.IsInt64() {
+			return fmt.Errorf("jer: unknown enumeration value %s", n)
+		}
+
+		return e.writeString(name)
+	case *asn1parser.ASNBitString:
+		if len(raw.Content) == 0 {
+			return fmt.Errorf("jer: zero length BIT STRING")
+		}
+
+		bits := strings.ToUpper(hex.EncodeToString(raw.Content[1:]))
+		length := (len(raw.Content)-1)*8 - int(raw.Content[0])
+
+		if size, ok := e.fixedSize(t); ok {
+			if int64(length) != size {
+				return fmt.Errorf("jer: found BIT STRING of %d bits, expected %d", length, size)
+			}
+
+			return e.writeString(bits)
+		}
+
+		e.buf.WriteString(`{"value":`)
+
+		if err := e.writeString(bits); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&e.buf, `,"length":%d}`, length)
+	case *asn1parser.ASNOctetString:
+		return e.writeString(strings.ToUpper(hex.EncodeToString(raw.Content)))
+	case *asn1parser.ASNObjectIdentifier:
+		oid := asn1.ObjectIdentifier{}
+		if err := oid.UnmarshalRawValue(raw); err != nil {
+			return err
+		}
+
+		return e.writeString(oid.String())
+	case *asn1parser.ASNCustom:
+		switch v.Type {
+		case "BOOLEAN":
+			if len(raw.Content) != 1 {
+				return fmt.Errorf("jer: invalid BOOLEAN length: %d", len(raw.Content))
+			}
+
+			if raw.Content[0] != 0x00 {
+				e.buf.WriteString("true")
+			} else {
+				e.buf.WriteString("false")
+			}
+		case "NULL":
+			e.buf.WriteString("null")
+		case "UTF8String", "IA5String":
+			return e.writeString(string(raw.Content))
+		default:
+			return fmt.Errorf("jer: unsupported type %s", builtin.Name())
+		}
+	case *asn1parser.ASNPrintableString, *asn1parser.ASNNumericString, *asn1parser.ASNVisibleString,
+		*asn1parser.ASNGraphicString, *asn1parser.ASNGeneralString, *asn1parser.ASNT61String,
+		*asn1parser.ASNObjectDescriptor, *asn1parser.ASNUTCTime, *asn1parser.ASNGeneralizedTime:
+		return e.writeString(string(raw.Content))
+	default:
+		return fmt.Errorf("jer: unsupported type %s", builtin.Name())
+	}
+
+	return nil
+}
+
+// encodeSequence writes the components of a SEQUENCE or SET as the members
+// of an object.
+func (e *encoder) encodeSequence(items []asn1parser.ASNItem, raw *asn1.RawValue, set bool) error {
+	values, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	present, err := e.def.MatchItems(items, values, set)
+	if err != nil {
+		return fmt.Errorf("jer: %s", err)
+	}
+
+	e.buf.WriteByte('{')
+
+	first := true
+	for i := range items {
+		if present[i] == nil {
+			continue
+		}
+
+		if !first {
+			e.buf.WriteByte(',')
+		}
+
+		first = false
+
+		if err := e.writeString(items[i].Name); err != nil {
+			return err
+		}
+
+		e.buf.WriteByte(':')
+
+		if err := e.encodeItem(items, i, present[i]); err != nil {
+			return err
+		}
+	}
+
+	e.buf.WriteByte('}')
+	return nil
+}
+
+// encodeSequenceOf writes the components of a SEQUENCE OF as an array.
+func (e *encoder) encodeSequenceOf(s *asn1parser.ASNSequence, raw *asn1.RawValue) error {
+	values, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	element := e.def.ElementType(s)
+
+	e.buf.WriteByte('[')
+
+	for i, value := range values {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+
+		if err := e.encodeType(element, value); err != nil {
+			return err
+		}
+	}
+
+	e.buf.WriteByte(']')
+	return nil
+}
@@ -0,0 +1,80 @@
+// Package asn1jer implements the JSON Encoding Rules (X.697). The codec
+// transcodes between JSON documents and the BER encoded RawValue used by the
+// asn1 package, using the types of a parsed asn1parser definition for the
+// member names.
+package asn1jer
+
+import (
+	"fmt"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// Codec encodes and decodes values of the types of a definition.
+type Codec struct {
+	def *asn1parser.ASNDefinition
+}
+
+// NewCodec returns a new Codec for the types of def.
+func NewCodec(def *asn1parser.ASNDefinition) *Codec {
+	return &Codec{
+		def: def,
+	}
+}
+
+// Encode returns the JSON document of raw, a BER encoded value of the type
+// typeName.
+func (c *Codec) Encode(typeName string, raw *asn1.RawValue) ([]byte, error) {
+	t := c.def.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("jer: unknown type %s", typeName)
+	}
+
+	raw, err := asn1.ToDER(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &encoder{
+		Codec: c,
+	}
+
+	if err := e.encodeType(t, raw); err != nil {
+		return nil, err
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// Decode decodes data, a JSON document with a value of the type typeName,
+// into the BER encoded value.
+func (c *Codec) Decode(typeName string, data []byte) (*asn1.RawValue, error) {
+	t := c.def.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("jer: unknown type %s", typeName)
+	}
+
+	v, err := parseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{
+		Codec: c,
+	}
+
+	return d.decodeType(t, v)
+}
+
+// fixedSize returns the size of t when t has a fixed size constraint
+// without extension marker. BIT STRING values of such types are encoded
+// without their length.
+func (c *Codec) fixedSize(t asn1parser.ASNType) (int64, bool) {
+	size, _ := c.def.Constraints(t)
+	if size == nil || size.Extensible || size.From != size.To {
+		return 0, false
+	}
+
+	return c.def.Value(size.From)
+}
@@ -0,0 +1,114 @@
+package asn1jer_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/jer"
+	"github.com/dutchsec/asn1/parser"
+)
+
+const scheme = `
+Test DEFINITIONS AUTOMATIC TAGS ::=
+BEGIN
+
+Record ::= SEQUENCE {
+	id INTEGER,
+	name UTF8String,
+	enabled BOOLEAN DEFAULT TRUE,
+	color ENUMERATED { red, green },
+	data OCTET STRING OPTIONAL,
+	flags BIT STRING,
+	mask BIT STRING (SIZE(4)),
+	tags SEQUENCE OF INTEGER,
+	choice CHOICE { a INTEGER, b NULL },
+	oid OBJECT IDENTIFIER
+}
+
+END
+`
+
+func TestCodec(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var tests = []struct {
+		ber string
+		jer string
+	}{
+		{
+			ber: "302a80010581026869820100830101840201ab850205a0860204a0a706020101020102a802810089032a0304",
+			jer: `{"id":5,"name":"hi","enabled":false,"color":"green","data":"01AB","flags":{"value":"A0","length":3},"mask":"A0","tags":[1,2],"choice":{"b":null},"oid":"1.2.3.4"}`,
+		},
+		{
+			ber: "3029800181810e3c263e20c3a9c3a02022e282ac22830100850100860204f0a700a80380012a89032a0304",
+			jer: `{"id":-127,"name":"<&> éà \"€\"","color":"red","flags":{"value":"","length":0},"mask":"F0","tags":[],"choice":{"a":42},"oid":"1.2.3.4"}`,
+		},
+	}
+
+	codec := asn1jer.NewCodec(def)
+
+	for i, tt := range tests {
+		ber, _ := hex.DecodeString(tt.ber)
+
+		raw, err := asn1.DecodeRawValue(bytes.NewReader(ber))
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		data, err := codec.Encode("Record", raw)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if string(data) != tt.jer {
+			t.Errorf("%d. encoding mismatch:\n  exp=%s\n  got=%s", i, tt.jer, data)
+		}
+
+		value, err := codec.Decode("Record", []byte(tt.jer))
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		out, err := value.Encode()
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if !bytes.Equal(out, ber) {
+			t.Errorf("%d. decoding mismatch: exp=%x got=%x", i, ber, out)
+		}
+	}
+}
+
+func TestCodec_DecodeErrors(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	codec := asn1jer.NewCodec(def)
+
+	var tests = []string{
+		`[]`,
+		`{"id":5}`,
+		`{"id":"5","name":"","color":"red","flags":{"value":"","length":0},"mask":"F0","tags":[],"choice":{"b":null},"oid":"1.2"}`,
+		`{"id":5,"name":"","color":"blue","flags":{"value":"","length":0},"mask":"F0","tags":[],"choice":{"b":null},"oid":"1.2"}`,
+		`{"id":5,"name":"","color":"red","flags":{"value":"FF","length":9},"mask":"F0","tags":[],"choice":{"b":null},"oid":"1.2"}`,
+		`{"id":5,"name":"","color":"red","flags":{"value":"","length":0},"mask":"F0","tags":[],"choice":{"a":1,"b":null},"oid":"1.2"}`,
+		`{"id":5,"name":"","color":"red","flags":{"value":"","length":0},"mask":"F0","tags":[],"choice":{"b":null},"oid":"1.2","unknown":1}`,
+		`{"id":5} {}`,
+		`{"id":`,
+	}
+
+	for i, tt := range tests {
+		if _, err := codec.Decode("Record", []byte(tt)); err == nil {
+			t.Errorf("%d. expected error for %s", i, tt)
+		}
+	}
+}
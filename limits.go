@@ -0,0 +1,244 @@
+package asn1
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Limits bounds the resources used to decode untrusted input. A zero field
+// does not limit the corresponding resource.
+type Limits struct {
+	// MaxSize is the maximum number of octets of a complete encoding.
+	MaxSize int64
+	// MaxLength is the maximum number of content octets of a single value.
+	MaxLength int64
+	// MaxDepth is the maximum nesting depth of values within constructed
+	// values, a top level value has depth zero.
+	MaxDepth int
+	// MaxElements is the maximum number of values, including all nested
+	// values.
+	MaxElements int
+}
+
+// LimitError is returned when the input exceeds one of the Limits.
+type LimitError struct {
+	// Limit is the name of the exceeded limit, eg. "MaxDepth".
+	Limit string
+	Max   int64
+}
+
+// Error returns the error message of a LimitError.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s of %d exceeded", e.Limit, e.Max)
+}
+
+// limiter keeps track of the resources used while decoding.
+type limiter struct {
+	Limits
+
+	elements int
+}
+
+// element accounts for a value at depth with length content octets.
+// Indefinite length values are accounted with a zero length.
+func (l *limiter) element(depth int, length uint) error {
+	l.elements++
+
+	switch {
+	case l.MaxElements > 0 && l.elements > l.MaxElements:
+		return &LimitError{"MaxElements", int64(l.MaxElements)}
+	case l.MaxDepth > 0 && depth > l.MaxDepth:
+		return &LimitError{"MaxDepth", int64(l.MaxDepth)}
+	case l.MaxLength > 0 && uint64(length) > uint64(l.MaxLength):
+		return &LimitError{"MaxLength", l.MaxLength}
+	case depth == 0 && l.MaxSize > 0 && uint64(length) > uint64(l.MaxSize):
+		return &LimitError{"MaxSize", l.MaxSize}
+	}
+
+	return nil
+}
+
+// nested returns true when the values nested in constructed values need to
+// be checked.
+func (l *limiter) nested() bool {
+	return l.MaxDepth > 0 || l.MaxElements > 0 || l.MaxLength > 0
+}
+
+// sizeReader fails with a *LimitError when more than max octets are read.
+type sizeReader struct {
+	r   io.Reader
+	n   int64
+	max int64
+}
+
+func (r *sizeReader) Read(p []byte) (int, error) {
+	if r.n >= r.max {
+		return 0, &LimitError{"MaxSize", r.max}
+	}
+
+	if int64(len(p)) > r.max-r.n {
+		p = p[:r.max-r.n]
+	}
+
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// DecodeRawValueLimits reads a value like DecodeRawValue, but returns a
+// *LimitError when the value, or one of the values nested in it, exceeds
+// limits. The limits are checked before the content octets are read.
+func DecodeRawValueLimits(reader io.Reader, limits Limits) (*RawValue, error) {
+	l, reader := newLimiter(reader, limits)
+	return l.decode(reader)
+}
+
+// newLimiter returns the limiter for limits and reader, which fails when
+// more than MaxSize octets are read from it.
+func newLimiter(reader io.Reader, limits Limits) (*limiter, io.Reader) {
+	if limits.MaxSize > 0 {
+		reader = &sizeReader{
+			r:   reader,
+			max: limits.MaxSize,
+		}
+	}
+
+	return &limiter{
+		Limits: limits,
+	}, reader
+}
+
+// decode reads a top level value from reader.
+func (l *limiter) decode(reader io.Reader) (*RawValue, error) {
+	tag, constructed, length, indefinite, n, err := decodeHeader(reader)
+	if err != nil {
+		return nil, errorAt(err, 0)
+	}
+
+	if indefinite && !constructed {
+		return nil, errorAt(kindError(ErrInvalidLength, "primitive node with indefinite length"), 0)
+	}
+
+	if err := l.element(0, length); err != nil {
+		return nil, err
+	}
+
+	raw := &RawValue{
+		Tag:          tag,
+		Constructed:  constructed,
+		Indefinite:   indefinite,
+		HeaderLength: n,
+	}
+
+	if !indefinite {
+		raw.Content, err = readContent(reader, length)
+	} else {
+		raw.Content, err = readIndefinite(reader)
+	}
+
+	if err != nil {
+		return nil, errorAt(err, 0)
+	}
+
+	if constructed && l.nested() {
+		if err := l.check(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	return raw, nil
+}
+
+// check accounts for all values nested in the constructed value raw. Nested
+// values are walked without recursion, so deeply nested input cannot exhaust
+// the stack.
+func (l *limiter) check(raw *RawValue) error {
+	reader := bytes.NewReader(raw.Content)
+
+	// the constructed values being walked
+	stack := []frame{{
+		tag:        raw.Tag,
+		indefinite: raw.Indefinite,
+		end:        int64(len(raw.Content)),
+	}}
+
+	// invalid records the position of the value at offset in err
+	invalid := func(offset int64, err error) error {
+		return errorIn(err, raw.ContentOffset()+offset, stack)
+	}
+
+	for {
+		offset := reader.Size() - int64(reader.Len())
+
+		for n := len(stack); n > 1 && !stack[n-1].indefinite && stack[n-1].end == offset; n = len(stack) {
+			stack = stack[:n-1]
+		}
+
+		if reader.Len() == 0 {
+			if len(stack) > 1 {
+				return invalid(offset, io.ErrUnexpectedEOF)
+			}
+
+			return nil
+		}
+
+		tag, constructed, err := decodeIdentifier(reader)
+		if err != nil {
+			return invalid(offset, err)
+		}
+
+		length, indefinite, err := decodeLength(reader)
+		if err != nil {
+			return invalid(offset, err)
+		}
+
+		if tag.Class == ClassUniversal && tag.Value == TagEoc && !constructed && !indefinite && length == 0 {
+			if n := len(stack); n > 1 && stack[n-1].indefinite {
+				stack = stack[:n-1]
+				continue
+			}
+
+			return invalid(offset, parseError("unexpected end-of-contents"))
+		}
+
+		if indefinite && !constructed {
+			return invalid(offset, kindError(ErrInvalidLength, "primitive node with indefinite length"))
+		}
+
+		if err := l.element(len(stack), length); err != nil {
+			return err
+		}
+
+		start := reader.Size() - int64(reader.Len())
+		if !indefinite && uint64(length) > uint64(reader.Len()) {
+			return invalid(offset, io.ErrUnexpectedEOF)
+		}
+
+		end := start + int64(length)
+		for i := len(stack) - 1; i > 0; i-- {
+			if stack[i].indefinite {
+				continue
+			}
+
+			if !indefinite && end > stack[i].end || indefinite && start >= stack[i].end {
+				return invalid(offset, kindError(ErrInvalidLength, "value exceeds length of enclosing value"))
+			}
+
+			break
+		}
+
+		switch {
+		case constructed:
+			stack = append(stack, frame{
+				tag:        tag,
+				indefinite: indefinite,
+				end:        end,
+			})
+		default:
+			if _, err := reader.Seek(int64(length), io.SeekCurrent); err != nil {
+				return err
+			}
+		}
+	}
+}
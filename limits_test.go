@@ -0,0 +1,153 @@
+package asn1_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestDecodeRawValueLimits(t *testing.T) {
+	var tests = []struct {
+		in     string
+		limits asn1.Limits
+		limit  string
+	}{
+		{in: "3006020101020102", limits: asn1.Limits{MaxElements: 3}},
+		{in: "3006020101020102", limits: asn1.Limits{MaxElements: 2}, limit: "MaxElements"},
+		{in: "30053003020101", limits: asn1.Limits{MaxDepth: 2}},
+		{in: "30053003020101", limits: asn1.Limits{MaxDepth: 1}, limit: "MaxDepth"},
+		{in: "3080 3080 020101 0000 0000", limits: asn1.Limits{MaxDepth: 1}, limit: "MaxDepth"},
+		{in: "3080 3003 020101 0000", limits: asn1.Limits{MaxElements: 2}, limit: "MaxElements"},
+		{in: "3006020101020102", limits: asn1.Limits{MaxLength: 6}},
+		{in: "3006020101020102", limits: asn1.Limits{MaxLength: 5}, limit: "MaxLength"},
+		{in: "3006 0404 01020304", limits: asn1.Limits{MaxLength: 3}, limit: "MaxLength"},
+		{in: "0403010203", limits: asn1.Limits{MaxSize: 5}},
+		{in: "0403010203", limits: asn1.Limits{MaxSize: 4}, limit: "MaxSize"},
+		{in: "3080 0403010203 0000", limits: asn1.Limits{MaxSize: 8}, limit: "MaxSize"},
+		{in: "04847fffffff", limits: asn1.Limits{MaxSize: 1024}, limit: "MaxSize"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		raw, err := asn1.DecodeRawValueLimits(bytes.NewReader(data), tt.limits)
+		if tt.limit == "" {
+			if err != nil {
+				t.Errorf("%d. unexpected error: %s", i, err)
+			} else if out, _ := raw.Encode(); !bytes.Equal(out, data) {
+				t.Errorf("%d. output mismatch: exp=%x got=%x", i, data, out)
+			}
+
+			continue
+		}
+
+		if le, ok := err.(*asn1.LimitError); !ok {
+			t.Errorf("%d. expected *LimitError, got %v", i, err)
+		} else if le.Limit != tt.limit {
+			t.Errorf("%d. expected %s to be exceeded, got %s", i, tt.limit, le.Limit)
+		}
+	}
+}
+
+func TestDecodeRawValue_Hostile(t *testing.T) {
+	var tests = []struct {
+		in  string
+		err error
+	}{
+		// the length claims 2GB of content octets
+		{in: "04847fffffff0102", err: io.ErrUnexpectedEOF},
+		// deeply nested indefinite length values
+		{in: strings.Repeat("3080", 100000) + strings.Repeat("0000", 100000)},
+		{in: strings.Repeat("3080", 100000), err: asn1.ErrTruncated},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(tt.in)
+
+		if _, err := asn1.DecodeRawValue(bytes.NewReader(data)); !errors.Is(err, tt.err) {
+			t.Errorf("%d. expected error %v, got %v", i, tt.err, err)
+		}
+	}
+}
+
+func TestContext_SetLimits(t *testing.T) {
+	data, _ := hex.DecodeString("3009020101020102020103")
+
+	ctx := asn1.NewContext()
+	ctx.SetLimits(asn1.Limits{MaxElements: 3})
+
+	var values []int
+	if _, ok := ctx.Unmarshal(data, &values).(*asn1.LimitError); !ok {
+		t.Errorf("expected *LimitError")
+	}
+
+	ctx.SetLimits(asn1.Limits{MaxElements: 4})
+	if err := ctx.Unmarshal(data, &values); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// the limits apply while verifying the DER and CER restrictions
+	var tests = []struct {
+		rules  asn1.EncodingRules
+		in     string
+		limits asn1.Limits
+	}{
+		{rules: asn1.DER, in: "3009020101020102020103", limits: asn1.Limits{MaxElements: 3}},
+		{rules: asn1.DER, in: "3009020101020102020103", limits: asn1.Limits{MaxSize: 10}},
+		{rules: asn1.DER, in: "3009020101020102020103", limits: asn1.Limits{MaxLength: 8}},
+		{rules: asn1.CER, in: "3080020101020102020103 0000", limits: asn1.Limits{MaxElements: 3}},
+		{rules: asn1.CER, in: "3080 3080 020101 0000 0000", limits: asn1.Limits{MaxDepth: 1}},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		ctx := asn1.NewContext()
+		ctx.SetRules(asn1.BER, tt.rules)
+		ctx.SetLimits(tt.limits)
+
+		var v asn1.RawValue
+		if _, ok := ctx.Unmarshal(data, &v).(*asn1.LimitError); !ok {
+			t.Errorf("%d. expected *LimitError", i)
+		}
+
+		ctx.SetLimits(asn1.Limits{})
+		if err := ctx.Unmarshal(data, &v); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		}
+	}
+}
+
+func TestDecoder_SetLimits(t *testing.T) {
+	data, _ := hex.DecodeString("308030803080020101000000000000")
+
+	d := asn1.NewDecoder(bytes.NewReader(data))
+	d.SetLimits(asn1.Limits{MaxDepth: 2})
+
+	for {
+		_, err := d.Token()
+		if err == nil {
+			continue
+		}
+
+		if _, ok := err.(*asn1.LimitError); !ok {
+			t.Errorf("expected *LimitError, got %v", err)
+		}
+
+		break
+	}
+
+	d = asn1.NewDecoder(bytes.NewReader([]byte{0x04, 0x84, 0x7f, 0xff, 0xff, 0xff}))
+	d.SetLimits(asn1.Limits{MaxSize: 1024})
+
+	if _, err := d.Token(); err == nil {
+		t.Errorf("expected *LimitError")
+	} else if _, ok := err.(*asn1.LimitError); !ok {
+		t.Errorf("expected *LimitError, got %v", err)
+	}
+}
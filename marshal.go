@@ -0,0 +1,298 @@
+package asn1
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Marshaler is implemented by types that encode themselves into a RawValue.
+// The tag of the returned RawValue is replaced by the tag of the struct tag
+// of the field, if any.
+type Marshaler interface {
+	MarshalRawValue() (*RawValue, error)
+}
+
+// encodeValue encodes value and applies the tagging of opts. A nil RawValue
+// is returned for nil pointers and interfaces, nil slices are encoded as
+// empty values, eg. an empty SEQUENCE OF.
+func (ctx *Context) encodeValue(value reflect.Value, opts *fieldOptions) (*RawValue, error) {
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	for (value.Kind() == reflect.Ptr && value.Type() != bigIntType) || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+
+	raw, err := ctx.encodeUntagged(value, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.tag == nil {
+		return raw, nil
+	}
+
+	if opts.explicit {
+		inner, err := raw.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		return &RawValue{
+			Tag:         *opts.tag,
+			Constructed: true,
+			Content:     inner,
+		}, nil
+	}
+
+	raw.Tag = *opts.tag
+	return raw, nil
+}
+
+// encodeUntagged encodes value using its universal tag.
+func (ctx *Context) encodeUntagged(value reflect.Value, opts *fieldOptions) (*RawValue, error) {
+	switch value.Type() {
+	case rawValueType:
+		raw := value.Interface().(RawValue)
+		return &raw, nil
+	case bigIntType:
+		return &RawValue{
+			Tag:     Tag(ClassUniversal, TagInteger),
+			Content: encodeBigInt(value.Interface().(*big.Int)),
+		}, nil
+	case timeType:
+		return ctx.encodeTime(value.Interface().(time.Time), opts.tag != nil && !opts.explicit)
+	}
+
+	if m, ok := value.Interface().(Marshaler); ok {
+		return m.MarshalRawValue()
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return Bool{value.Bool()}.MarshalRawValue()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Integer{value.Int()}.MarshalRawValue()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &RawValue{
+			Tag:     Tag(ClassUniversal, TagInteger),
+			Content: encodeUint64(value.Uint()),
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		return NewReal(value.Float()).MarshalRawValue()
+	case reflect.String:
+		return UTF8String{value.String()}.MarshalRawValue()
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return &RawValue{
+				Tag:     Tag(ClassUniversal, TagOctetString),
+				Content: append([]byte{}, value.Bytes()...),
+			}, nil
+		}
+
+		return ctx.encodeSlice(value, opts)
+	case reflect.Struct:
+		return ctx.encodeStruct(value, opts)
+	}
+
+	return nil, unsupportedType(value.Type())
+}
+
+// encodeTime encodes t as UTCTime when its year is in the century of the
+// UTCTime pivot of ctx, and as GeneralizedTime otherwise or when generalized
+// is set. Both are in the form required by DER.
+func (ctx *Context) encodeTime(t time.Time, generalized bool) (*RawValue, error) {
+	t = t.UTC()
+
+	if !generalized && t.Year() >= ctx.pivot && t.Year() < ctx.pivot+100 {
+		return UTCTime{t.Format("060102150405Z")}.MarshalRawValue()
+	}
+
+	s, err := NewGeneralizedTimeFrom(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.MarshalRawValue()
+}
+
+// encodeSlice encodes value as a SEQUENCE OF or SET OF.
+func (ctx *Context) encodeSlice(value reflect.Value, opts *fieldOptions) (*RawValue, error) {
+	encodings := [][]byte{}
+
+	for i := 0; i < value.Len(); i++ {
+		child, err := ctx.encodeValue(value.Index(i), &fieldOptions{})
+		if err != nil {
+			return nil, errorPath(err, fmt.Sprintf("[%d]", i))
+		}
+
+		if child == nil {
+			return nil, syntaxError("nil element %d in %s", i, value.Type())
+		}
+
+		data, err := child.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		encodings = append(encodings, data)
+	}
+
+	if opts.set && ctx.rules.encoding != BER {
+		// X.690 11.6
+		sort.Slice(encodings, func(i, j int) bool {
+			return compareEncodings(encodings[i], encodings[j]) < 0
+		})
+	}
+
+	content := []byte{}
+	for _, data := range encodings {
+		content = append(content, data...)
+	}
+
+	return &RawValue{
+		Tag:         Tag(ClassUniversal, sequenceTag(opts)),
+		Constructed: true,
+		Content:     content,
+	}, nil
+}
+
+// encodeStruct encodes value as a SEQUENCE or SET.
+func (ctx *Context) encodeStruct(value reflect.Value, opts *fieldOptions) (*RawValue, error) {
+	children := []*RawValue{}
+
+	// the unknown components, encoded after the other fields
+	var extensions []RawValue
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		fopts, err := parseFieldOptions(field.Tag.Get("asn1"))
+		if err != nil {
+			return nil, err
+		}
+
+		if fopts.ignore {
+			continue
+		}
+
+		fv := value.Field(i)
+
+		if fopts.extensions {
+			var ok bool
+			if extensions, ok = fv.Interface().([]RawValue); !ok {
+				return nil, syntaxError("extensions field %s must be a []RawValue", fieldName(field, fopts))
+			}
+
+			continue
+		}
+
+		if fopts.optional && isEmptyValue(fv) {
+			continue
+		}
+
+		if fopts.defaultValue != nil {
+			if ok, err := isDefaultValue(fv, *fopts.defaultValue); err != nil {
+				return nil, err
+			} else if ok {
+				continue
+			}
+		}
+
+		child, err := ctx.encodeValue(fv, fopts)
+		if err != nil {
+			return nil, errorPath(err, fieldName(field, fopts))
+		}
+
+		if child == nil {
+			if fopts.optional || fopts.defaultValue != nil {
+				continue
+			}
+
+			return nil, syntaxError("missing value for field %s", fieldName(field, fopts))
+		}
+
+		children = append(children, child)
+	}
+
+	for i := range extensions {
+		children = append(children, &extensions[i])
+	}
+
+	if opts.set && ctx.rules.encoding != BER {
+		// X.690 10.3
+		sort.SliceStable(children, func(i, j int) bool {
+			return lessTag(children[i].Tag, children[j].Tag)
+		})
+	}
+
+	content := []byte{}
+	for _, child := range children {
+		data, err := child.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, data...)
+	}
+
+	return &RawValue{
+		Tag:         Tag(ClassUniversal, sequenceTag(opts)),
+		Constructed: true,
+		Content:     content,
+	}, nil
+}
+
+// isEmptyValue returns true when value is a nil pointer, interface, slice
+// or map, this is used to omit OPTIONAL fields. Other values, like INTEGER 0
+// or BOOLEAN FALSE, are present. The zero RawValue, the value of an absent
+// OPTIONAL RawValue field after decoding, is not a value and is omitted.
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return value.IsNil()
+	case reflect.Struct:
+		if value.Type() == rawValueType {
+			return reflect.DeepEqual(value.Interface(), RawValue{})
+		}
+	}
+
+	return false
+}
+
+// isDefaultValue returns true when value equals the DEFAULT value s.
+func isDefaultValue(value reflect.Value, s string) (bool, error) {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return true, nil
+		}
+		value = value.Elem()
+	}
+
+	def, err := defaultValue(value.Type(), s)
+	if err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(value.Interface(), def.Interface()), nil
+}
+
+// sequenceTag returns the universal tag of a constructed value.
+func sequenceTag(opts *fieldOptions) ASNValue {
+	if opts.set {
+		return TagSet
+	}
+	return TagSequence
+}
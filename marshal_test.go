@@ -0,0 +1,310 @@
+package asn1_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+type testAlgorithm struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters asn1.RawValue `asn1:"optional"`
+}
+
+type testRecord struct {
+	Version   int `asn1:"tag:0,explicit,default:1"`
+	Serial    *big.Int
+	Algorithm testAlgorithm
+	Name      string
+	Flags     asn1.BitString    `asn1:"tag:1"`
+	Critical  bool              `asn1:"default:false"`
+	Extra     *asn1.OctetString `asn1:"tag:2,application,optional"`
+	Values    []int             `asn1:"set"`
+	Ignored   int               `asn1:"-"`
+}
+
+func TestMarshal(t *testing.T) {
+	var tests = []struct {
+		v   interface{}
+		out string
+	}{
+		{v: 10, out: "02010a"},
+		{v: -129, out: "0202ff7f"},
+		{v: 128, out: "02020080"},
+		{v: true, out: "0101ff"},
+		{v: "test", out: "0c0474657374"},
+		{v: []byte{0x01, 0x02}, out: "04020102"},
+		{v: asn1.NewObjectIdentifier(1, 2, 840, 113549), out: "06062a864886f70d"},
+		{v: asn1.NewInteger(-1), out: "0201ff"},
+		{v: asn1.NewPrintableString("abc"), out: "1303616263"},
+		{v: asn1.BitString{Bytes: []byte{0x80}, BitLength: 1}, out: "03020780"},
+		{v: []int{1, 2}, out: "3006020101020102"},
+		{v: asn1.Null{}, out: "0500"},
+		{v: uint64(math.MaxUint64), out: "020900ffffffffffffffff"},
+		{v: uint8(128), out: "02020080"},
+		{v: asn1.NewUnsignedInteger(255), out: "020200ff"},
+		{v: asn1.NewEnumerated(3), out: "0a0103"},
+		{v: asn1.NewNumericString("12 3"), out: "120431322033"},
+		{v: asn1.NewRelativeObjectIdentifier(8571, 3, 2), out: "0d04c27b0302"},
+		{v: asn1.NewDate("2008-12-05"), out: "1f1f083230303831323035"},
+	}
+
+	for i, tt := range tests {
+		data, err := asn1.Marshal(tt.v)
+		if err != nil {
+			t.Errorf("%d. %#v: unexpected error: %s", i, tt.v, err)
+		} else if hex.EncodeToString(data) != tt.out {
+			t.Errorf("%d. %#v: output mismatch: exp=%s got=%x", i, tt.v, tt.out, data)
+		}
+	}
+}
+
+func TestMarshal_Optional(t *testing.T) {
+	type optional struct {
+		A asn1.Integer     `asn1:"optional"`
+		B *asn1.Integer    `asn1:"optional"`
+		C asn1.Bool        `asn1:"optional"`
+		D asn1.BigInteger  `asn1:"optional"`
+		E asn1.RawValue    `asn1:"optional"`
+		F []byte           `asn1:"tag:0,optional"`
+		G asn1.Unmarshaler `asn1:"optional"`
+	}
+
+	var tests = []struct {
+		v   interface{}
+		out string
+	}{
+		// zero values are present, only nil values are omitted
+		{v: optional{A: asn1.NewInteger(0), C: asn1.BoolFalse}, out: "3009020100010100020100"},
+		{v: optional{D: asn1.NewBigInteger(new(big.Int))}, out: "3009020100010100020100"},
+		{v: optional{B: new(asn1.Integer), F: []byte{}}, out: "300e0201000201000101000201008000"},
+	}
+
+	for i, tt := range tests {
+		data, err := asn1.Marshal(tt.v)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if hex.EncodeToString(data) != tt.out {
+			t.Errorf("%d. output mismatch: exp=%s got=%x", i, tt.out, data)
+		}
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	extra := asn1.NewOctetString("extra")
+
+	in := testRecord{
+		Version: 3,
+		Serial:  new(big.Int).Lsh(big.NewInt(1), 100),
+		Algorithm: testAlgorithm{
+			Algorithm: asn1.NewObjectIdentifier(1, 2, 840, 113549, 1, 1, 11),
+			Parameters: asn1.RawValue{
+				Tag:     asn1.Tag(asn1.ClassUniversal, asn1.TagNull),
+				Content: []byte{},
+			},
+		},
+		Name:    "name",
+		Flags:   asn1.BitString{Bytes: []byte{0xa0}, BitLength: 3},
+		Extra:   &extra,
+		Values:  []int{5, 6, 7},
+		Ignored: 42,
+	}
+
+	data, err := asn1.Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var out testRecord
+	if err := asn1.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// the decoded raw value records its position in data
+	params := &out.Algorithm.Parameters
+	if params.HeaderLength != 2 || data[params.Offset] != 0x05 {
+		t.Errorf("unexpected position of raw value: offset=%d header=%d", params.Offset, params.HeaderLength)
+	}
+
+	params.Offset, params.HeaderLength = 0, 0
+
+	in.Ignored = 0
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch:\n\nexp=%#v\n\ngot=%#v", in, out)
+	}
+}
+
+func TestUnmarshal_Defaults(t *testing.T) {
+	// SEQUENCE { serial, algorithm, name, [1] flags, SET OF {} }
+	data, _ := hex.DecodeString("3015020101300306012a0c008101003106020101020102")
+
+	var out testRecord
+	if err := asn1.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if out.Version != 1 {
+		t.Errorf("default value not applied: got %d", out.Version)
+	}
+
+	if out.Extra != nil {
+		t.Errorf("optional value should be nil")
+	}
+
+	if !reflect.DeepEqual(out.Values, []int{1, 2}) {
+		t.Errorf("values mismatch: got %v", out.Values)
+	}
+
+	reencoded, err := asn1.Marshal(out)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if !bytes.Equal(reencoded, data) {
+		t.Errorf("output mismatch: exp=%x got=%x", data, reencoded)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	var tests = []struct {
+		in string
+		v  interface{}
+	}{
+		{in: "0101ff", v: new(int)},
+		{in: "0202ff7f", v: new(uint)},
+		{in: "02020100", v: new(int8)},
+		{in: "02010102", v: new(int)},
+		{in: "3003020101", v: new(struct{ A, B int })},
+		{in: "0200", v: new(asn1.Integer)},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(tt.in)
+		if err := asn1.Unmarshal(data, tt.v); err == nil {
+			t.Errorf("%d. %s: expected error", i, tt.in)
+		}
+	}
+}
+
+func TestUnmarshal_Segments(t *testing.T) {
+	var tests = []struct {
+		in  string
+		v   interface{}
+		out interface{}
+	}{
+		{in: "24080402616204026364", v: new(asn1.OctetString), out: asn1.NewOctetString("abcd")},
+		{in: "24800401612480040262630000 0000", v: new([]byte), out: []byte("abc")},
+		{in: "2c0604016104016a", v: new(string), out: "aj"},
+		{in: "3306040161040162", v: new(asn1.PrintableString), out: asn1.NewPrintableString("ab")},
+		{in: "2309030200f0030304abc0", v: new(asn1.BitString), out: asn1.BitString{Bytes: []byte{0xf0, 0xab, 0xc0}, BitLength: 20}},
+		{in: "23800303040ab00000", v: new(asn1.BitString), out: asn1.BitString{Bytes: []byte{0x0a, 0xb0}, BitLength: 12}},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+		if err := asn1.Unmarshal(data, tt.v); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if out := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("%d. output mismatch: exp=%#v got=%#v", i, tt.out, out)
+		}
+	}
+}
+
+func TestUnmarshal_SegmentErrors(t *testing.T) {
+	var tests = []struct {
+		in string
+		v  interface{}
+	}{
+		// segments must be OCTET STRINGs
+		{in: "240402026162", v: new(asn1.OctetString)},
+		{in: "2c0416026162", v: new(string)},
+		// only the last segment may contain unused bits
+		{in: "2308030204f0030200ab", v: new(asn1.BitString)},
+		{in: "230404020000", v: new(asn1.BitString)},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(tt.in)
+		if err := asn1.Unmarshal(data, tt.v); err == nil {
+			t.Errorf("%d. %s: expected error", i, tt.in)
+		}
+	}
+}
+
+func TestBigInteger(t *testing.T) {
+	var tests = []string{
+		"00",
+		"7f",
+		"0080",
+		"ff7f",
+		"80",
+		"00ffffffffffffffff",
+		"ff0000000000000000",
+		"7fffffffffffffffffffffffffffffffffffffff",
+		"8000000000000000000000000000000000000000",
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString("02" + hex.EncodeToString([]byte{byte(len(tt) / 2)}) + tt)
+
+		var v asn1.BigInteger
+		if err := asn1.UnmarshalDER(data, &v); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		out, err := asn1.Marshal(asn1.NewBigInteger(v.BigInt()))
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if !bytes.Equal(out, data) {
+			t.Errorf("%d. encoding mismatch: exp=%x got=%x", i, data, out)
+		}
+	}
+}
+
+func TestBigInteger_Unsigned(t *testing.T) {
+	type counters struct {
+		Counter64 asn1.BigInteger `asn1:"tag:6,application"`
+	}
+
+	in := counters{Counter64: asn1.NewUnsignedInteger(math.MaxUint64)}
+
+	data, err := asn1.Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if exp := "300b460900ffffffffffffffff"; hex.EncodeToString(data) != exp {
+		t.Errorf("encoding mismatch: exp=%s got=%x", exp, data)
+	}
+
+	var out counters
+	if err := asn1.UnmarshalDER(data, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if v, err := out.Counter64.Uint64(); err != nil || v != math.MaxUint64 {
+		t.Errorf("value mismatch: exp=%d got=%d (%v)", uint64(math.MaxUint64), v, err)
+	}
+
+	// the implicitly tagged INTEGER is not minimal
+	data, _ = hex.DecodeString("30044602007f")
+	if err := asn1.UnmarshalDER(data, &out); err == nil {
+		t.Errorf("expected DER error")
+	}
+
+	// negative values do not fit a uint64
+	if _, err := asn1.NewBigInteger(big.NewInt(-1)).Uint64(); !errors.Is(err, asn1.ErrConstraint) {
+		t.Errorf("expected error of kind ErrConstraint, got %v", err)
+	}
+
+	var v asn1.BigInteger
+	if err := asn1.Unmarshal([]byte{0x02, 0x00}, &v); !errors.Is(err, asn1.ErrInvalidLength) {
+		t.Errorf("expected error of kind ErrInvalidLength, got %v", err)
+	}
+}
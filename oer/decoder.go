@@ -0,0 +1,526 @@
+package asn1oer
+
+import (
+	"fmt"
+	"math/big"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// decoder reads OER encoded values into BER encoded values.
+type decoder struct {
+	*Codec
+
+	r reader
+}
+
+// decodeLength decodes a length determinant (X.696 8.6).
+func (d *decoder) decodeLength() (uint64, error) {
+	b, err := d.r.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if b&0x80 == 0 {
+		return uint64(b), nil
+	}
+
+	octets := int(b & 0x7f)
+	if octets == 0 || octets > 8 {
+		return 0, fmt.Errorf("oer: invalid length of %d octets", octets)
+	}
+
+	data, err := d.r.readBytes(uint64(octets))
+	if err != nil {
+		return 0, err
+	}
+
+	n := new(big.Int).SetBytes(data).Uint64()
+
+	// CANONICAL-OER uses the short form and the minimum number of octets
+	if d.canonical && (n < 128 || data[0] == 0x00) {
+		return 0, fmt.Errorf("oer: length %d is not encoded in the minimum number of octets", n)
+	}
+
+	return n, nil
+}
+
+// decodeOctets decodes octets preceded by a length determinant.
+func (d *decoder) decodeOctets() ([]byte, error) {
+	n, err := d.decodeLength()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.r.readBytes(n)
+}
+
+// decodeBits decodes n bits of a bitmap.
+func (d *decoder) decodeBits(n int) ([]bool, error) {
+	data, err := d.r.readBytes(uint64(n+7) / 8)
+	if err != nil {
+		return nil, err
+	}
+
+	return bitmap(data, n), nil
+}
+
+// bitmap returns the first n bits of data.
+func bitmap(data []byte, n int) []bool {
+	bits := make([]bool, n)
+	for i := range bits {
+		bits[i] = data[i/8]&(0x80>>uint(i%8)) != 0
+	}
+
+	return bits
+}
+
+// decodeOpenType decodes a value encoded as an open type (X.696 30), f
+// reads the value from the contained encoding.
+func (d *decoder) decodeOpenType(f func(sub *decoder) (*asn1.RawValue, error)) (*asn1.RawValue, error) {
+	data, err := d.decodeOctets()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &decoder{
+		Codec: d.Codec,
+		r: reader{
+			data: data,
+		},
+	}
+
+	raw, err := f(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	if rest := sub.r.remaining(); rest > 0 {
+		return nil, fmt.Errorf("oer: %d trailing octets in open type", rest)
+	}
+
+	return raw, nil
+}
+
+// decodeItem decodes the value of the i-th item of a SEQUENCE, SET or
+// CHOICE.
+func (d *decoder) decodeItem(items []asn1parser.ASNItem, i int) (*asn1.RawValue, error) {
+	raw, err := d.decodeBuiltin(items[i].Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.def.TagItem(items, i, raw)
+}
+
+// decodeType decodes a value of type t.
+func (d *decoder) decodeType(t asn1parser.ASNType) (*asn1.RawValue, error) {
+	raw, err := d.decodeBuiltin(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.def.TagType(t, raw)
+}
+
+// decodeBuiltin decodes a value of the builtin type t refers to.
+func (d *decoder) decodeBuiltin(t asn1parser.ASNType) (*asn1.RawValue, error) {
+	builtin := d.def.Resolve(t)
+
+	// the choice value is the value of the alternative
+	if v, ok := builtin.(*asn1parser.ASNChoice); ok {
+		return d.decodeChoice(v)
+	}
+
+	value, ok := asn1parser.UniversalTag(builtin)
+	if !ok {
+		return nil, fmt.Errorf("oer: unsupported type %s", builtin.Name())
+	}
+
+	raw := &asn1.RawValue{
+		Tag: asn1.Tag(asn1.ClassUniversal, value),
+	}
+
+	var err error
+
+	switch v := builtin.(type) {
+	case *asn1parser.ASNSequence:
+		raw.Constructed = true
+
+		if v.Of != "" {
+			raw.Content, err = d.decodeSequenceOf(v)
+		} else {
+			raw.Content, err = d.decodeSequence(v.Items, false)
+		}
+	case *asn1parser.ASNSet:
+		raw.Constructed = true
+		raw.Content, err = d.decodeSequence(v.Items, true)
+	case *asn1parser.ASNInteger:
+		raw.Content, err = d.decodeInteger(t)
+	case *asn1parser.ASNEnumerated:
+		raw.Content, err = d.decodeEnumerated()
+	case *asn1parser.ASNBitString:
+		raw.Content, err = d.decodeBitString(t)
+	case *asn1parser.ASNOctetString:
+		raw.Content, err = d.decodeString(t)
+	case *asn1parser.ASNObjectIdentifier, *asn1parser.ASNObjectDescriptor, *asn1parser.ASNGraphicString,
+		*asn1parser.ASNGeneralString, *asn1parser.ASNT61String, *asn1parser.ASNUTCTime,
+		*asn1parser.ASNGeneralizedTime:
+		raw.Content, err = d.decodeOctets()
+	default:
+		switch value {
+		case asn1.TagBoolean:
+			raw.Content, err = d.decodeBoolean()
+		case asn1.TagNull:
+			raw.Content = []byte{}
+		case asn1.TagReal, asn1.TagUTF8String:
+			raw.Content, err = d.decodeOctets()
+		default:
+			if !isKnownMultiplier(builtin) {
+				return nil, fmt.Errorf("oer: unsupported type %s", builtin.Name())
+			}
+
+			raw.Content, err = d.decodeString(t)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// decodeBoolean decodes a BOOLEAN (X.696 9).
+func (d *decoder) decodeBoolean() ([]byte, error) {
+	b, err := d.r.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b == 0x00:
+		return []byte{0x00}, nil
+	case b != 0xff && d.canonical:
+		return nil, fmt.Errorf("oer: invalid BOOLEAN value %#02x", b)
+	}
+
+	return []byte{0xff}, nil
+}
+
+// decodeInteger decodes an INTEGER (X.696 10).
+func (d *decoder) decodeInteger(t asn1parser.ASNType) ([]byte, error) {
+	b, octets, signed, err := d.integerForm(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if octets == 0 {
+		data, err = d.decodeOctets()
+	} else {
+		data, err = d.r.readBytes(uint64(octets))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, fmt.Errorf("oer: empty integer")
+	}
+
+	if octets == 0 && d.canonical && len(data) > 1 {
+		// CANONICAL-OER uses the minimum number of octets
+		if !signed && data[0] == 0x00 || signed && (data[0] == 0x00 && data[1]&0x80 == 0 || data[0] == 0xff && data[1]&0x80 != 0) {
+			return nil, fmt.Errorf("oer: integer is not encoded in the minimum number of octets")
+		}
+	}
+
+	n := new(big.Int).SetBytes(data)
+	if signed {
+		if n, err = asn1.ParseInteger(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if !b.contains(n) {
+		return nil, fmt.Errorf("oer: value %s is not within the constraint", n)
+	}
+
+	return asn1.EncodeInteger(n), nil
+}
+
+// decodeEnumerated decodes an ENUMERATED (X.696 11).
+func (d *decoder) decodeEnumerated() ([]byte, error) {
+	b, err := d.r.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	if b&0x80 == 0 {
+		return asn1.EncodeInteger(big.NewInt(int64(b))), nil
+	}
+
+	data, err := d.r.readBytes(uint64(b & 0x7f))
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := asn1.ParseInteger(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.canonical && n.Sign() >= 0 && n.Cmp(big.NewInt(127)) <= 0 {
+		return nil, fmt.Errorf("oer: enumeration value %s is not encoded in a single octet", n)
+	}
+
+	return asn1.EncodeInteger(n), nil
+}
+
+// decodeBitString decodes a BIT STRING (X.696 13).
+func (d *decoder) decodeBitString(t asn1parser.ASNType) ([]byte, error) {
+	size, fixed, err := d.fixedSize(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fixed {
+		data, err := d.decodeOctets()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) == 0 || data[0] > 7 || len(data) == 1 && data[0] != 0 {
+			return nil, fmt.Errorf("oer: invalid BIT STRING encoding")
+		}
+
+		return data, nil
+	}
+
+	data, err := d.r.readBytes(uint64(size+7) / 8)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{byte((8 - size%8) % 8)}, data...), nil
+}
+
+// decodeString decodes an OCTET STRING or a known-multiplier character
+// string (X.696 14 and 27).
+func (d *decoder) decodeString(t asn1parser.ASNType) ([]byte, error) {
+	size, fixed, err := d.fixedSize(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fixed {
+		return d.decodeOctets()
+	}
+
+	return d.r.readBytes(uint64(size))
+}
+
+// decodeSequenceOf decodes a SEQUENCE OF (X.696 17).
+func (d *decoder) decodeSequenceOf(s *asn1parser.ASNSequence) ([]byte, error) {
+	quantity, err := d.decodeOctets()
+	if err != nil {
+		return nil, err
+	}
+
+	// every element is encoded in at least one octet, except for the
+	// elements of types without content such as NULL, which are limited
+	// to maxEmptyElements.
+	n := new(big.Int).SetBytes(quantity)
+	if !n.IsInt64() || n.Int64() > int64(d.r.remaining()) && n.Int64() > maxEmptyElements {
+		return nil, fmt.Errorf("oer: quantity %s exceeds the remaining %d octets", n, d.r.remaining())
+	}
+
+	element := d.def.ElementType(s)
+
+	content := []byte{}
+	for i := int64(0); i < n.Int64(); i++ {
+		before := d.r.remaining()
+
+		value, err := d.decodeType(element)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.r.remaining() == before && i >= maxEmptyElements {
+			return nil, fmt.Errorf("oer: more than %d empty elements", maxEmptyElements)
+		}
+
+		data, err := value.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, data...)
+	}
+
+	return content, nil
+}
+
+// decodeSequence decodes a SEQUENCE or SET (X.696 16 and 18).
+func (d *decoder) decodeSequence(items []asn1parser.ASNItem, set bool) ([]byte, error) {
+	root, additions, extensible := d.def.Components(items)
+	if set {
+		d.def.SortByTag(items, root)
+	}
+
+	optional := 0
+	for _, i := range root {
+		if items[i].Optional || items[i].Default != nil {
+			optional++
+		}
+	}
+
+	n := optional
+	if extensible {
+		n++
+	}
+
+	// preamble with the extension bit and the presence of OPTIONAL and
+	// DEFAULT components
+	preamble, err := d.decodeBits(n)
+	if err != nil {
+		return nil, err
+	}
+
+	extended := false
+	if extensible {
+		extended, preamble = preamble[0], preamble[1:]
+	}
+
+	values := map[int]*asn1.RawValue{}
+	for _, i := range root {
+		if items[i].Optional || items[i].Default != nil {
+			present := preamble[0]
+			preamble = preamble[1:]
+
+			if !present {
+				continue
+			}
+		}
+
+		value, err := d.decodeItem(items, i)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = value
+	}
+
+	if extended {
+		data, err := d.decodeOctets()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) == 0 || data[0] > 7 || len(data) == 1 && data[0] != 0 {
+			return nil, fmt.Errorf("oer: invalid extension presence bitmap")
+		}
+
+		for j, bit := range bitmap(data[1:], len(data[1:])*8-int(data[0])) {
+			if !bit {
+				continue
+			}
+
+			// additions unknown to the schema are skipped
+			if j >= len(additions) {
+				if _, err := d.decodeOctets(); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			i := additions[j]
+
+			value, err := d.decodeOpenType(func(sub *decoder) (*asn1.RawValue, error) {
+				return sub.decodeItem(items, i)
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			values[i] = value
+		}
+	}
+
+	// the components are in the order of the schema
+	content := []byte{}
+	for i := range items {
+		if values[i] == nil {
+			continue
+		}
+
+		data, err := values[i].Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, data...)
+	}
+
+	return content, nil
+}
+
+// decodeTag decodes the tag of a CHOICE alternative (X.696 8.7).
+func (d *decoder) decodeTag() (asn1.ASNTag, error) {
+	b, err := d.r.readByte()
+	if err != nil {
+		return asn1.ASNTag{}, err
+	}
+
+	class := asn1.ASNClass(b >> 6)
+	if b&0x3f != 0x3f {
+		return asn1.Tag(class, asn1.ASNValue(b&0x3f)), nil
+	}
+
+	var v asn1.ASNValue
+	for i := 0; ; i++ {
+		b, err := d.r.readByte()
+		if err != nil {
+			return asn1.ASNTag{}, err
+		}
+
+		if i == 0 && b == 0x80 || i >= 4 {
+			return asn1.ASNTag{}, fmt.Errorf("oer: invalid tag encoding")
+		}
+
+		v = v<<7 | asn1.ASNValue(b&0x7f)
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	return asn1.Tag(class, v), nil
+}
+
+// decodeChoice decodes a CHOICE (X.696 20).
+func (d *decoder) decodeChoice(c *asn1parser.ASNChoice) (*asn1.RawValue, error) {
+	tag, err := d.decodeTag()
+	if err != nil {
+		return nil, err
+	}
+
+	i, ok := d.def.MatchAlternative(c.Items, tag)
+	if !ok {
+		return nil, fmt.Errorf("oer: unknown alternative %s of %s", tag, c.Name())
+	}
+
+	_, additions, _ := d.def.Components(c.Items)
+	for _, j := range additions {
+		if j == i {
+			return d.decodeOpenType(func(sub *decoder) (*asn1.RawValue, error) {
+				return sub.decodeItem(c.Items, i)
+			})
+		}
+	}
+
+	return d.decodeItem(c.Items, i)
+}
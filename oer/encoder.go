@@ -0,0 +1,394 @@
+package asn1oer
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// encoder writes the OER encoding of BER encoded values.
+type encoder struct {
+	*Codec
+
+	buf bytes.Buffer
+}
+
+// encodeLength encodes a length determinant (X.696 8.6).
+func (e *encoder) encodeLength(n int) {
+	if n < 128 {
+		e.buf.WriteByte(byte(n))
+		return
+	}
+
+	data := big.NewInt(int64(n)).Bytes()
+	e.buf.WriteByte(0x80 | byte(len(data)))
+	e.buf.Write(data)
+}
+
+// encodeOctets encodes data preceded by a length determinant.
+func (e *encoder) encodeOctets(data []byte) {
+	e.encodeLength(len(data))
+	e.buf.Write(data)
+}
+
+// encodeBits encodes bits as the octets of a bitmap, the last octet is
+// padded with zero bits.
+func (e *encoder) encodeBits(bits []bool) {
+	data := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			data[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+
+	e.buf.Write(data)
+}
+
+// encodeOpenType encodes the complete encoding of a value as an open type
+// (X.696 30), f writes the value.
+func (e *encoder) encodeOpenType(f func(sub *encoder) error) error {
+	sub := &encoder{
+		Codec: e.Codec,
+	}
+
+	if err := f(sub); err != nil {
+		return err
+	}
+
+	e.encodeOctets(sub.buf.Bytes())
+	return nil
+}
+
+// encodeItem encodes raw, the value of the i-th item of a SEQUENCE, SET or
+// CHOICE.
+func (e *encoder) encodeItem(items []asn1parser.ASNItem, i int, raw *asn1.RawValue) error {
+	raw, err := e.def.UntagItem(items, i, raw)
+	if err != nil {
+		return fmt.Errorf("oer: %s", err)
+	}
+
+	return e.encodeBuiltin(items[i].Type, raw)
+}
+
+// encodeType encodes raw, a value of type t.
+func (e *encoder) encodeType(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	raw, err := e.def.UntagType(t, raw)
+	if err != nil {
+		return fmt.Errorf("oer: %s", err)
+	}
+
+	return e.encodeBuiltin(t, raw)
+}
+
+// encodeBuiltin encodes raw, a value of type t with the tags of t removed.
+func (e *encoder) encodeBuiltin(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	builtin := e.def.Resolve(t)
+
+	switch v := builtin.(type) {
+	case *asn1parser.ASNSequence:
+		if v.Of != "" {
+			return e.encodeSequenceOf(v, raw)
+		}
+
+		return e.encodeSequence(v.Items, raw, false)
+	case *asn1parser.ASNSet:
+		return e.encodeSequence(v.Items, raw, true)
+	case *asn1parser.ASNChoice:
+		return e.encodeChoice(v, raw)
+	case *asn1parser.ASNInteger:
+		return e.encodeInteger(t, raw)
+	case *asn1parser.ASNEnumerated:
+		return e.encodeEnumerated(raw)
+	case *asn1parser.ASNBitString:
+		return e.encodeBitString(t, raw)
+	case *asn1parser.ASNOctetString:
+		return e.encodeString(t, raw)
+	case *asn1parser.ASNObjectIdentifier, *asn1parser.ASNObjectDescriptor, *asn1parser.ASNGraphicString,
+		*asn1parser.ASNGeneralString, *asn1parser.ASNT61String, *asn1parser.ASNUTCTime,
+		*asn1parser.ASNGeneralizedTime:
+		e.encodeOctets(raw.Content)
+		return nil
+	case *asn1parser.ASNCustom:
+		switch v.Type {
+		case "BOOLEAN":
+			if len(raw.Content) != 1 {
+				return fmt.Errorf("oer: invalid BOOLEAN length: %d", len(raw.Content))
+			}
+
+			if raw.Content[0] != 0x00 {
+				e.buf.WriteByte(0xff)
+			} else {
+				e.buf.WriteByte(0x00)
+			}
+
+			return nil
+		case "NULL":
+			return nil
+		case "REAL", "UTF8String":
+			e.encodeOctets(raw.Content)
+			return nil
+		}
+	}
+
+	if isKnownMultiplier(builtin) {
+		return e.encodeString(t, raw)
+	}
+
+	return fmt.Errorf("oer: unsupported type %s", builtin.Name())
+}
+
+// encodeInteger encodes an INTEGER (X.696 10).
+func (e *encoder) encodeInteger(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	b, octets, signed, err := e.integerForm(t)
+	if err != nil {
+		return err
+	}
+
+	n, err := asn1.ParseInteger(raw.Content)
+	if err != nil {
+		return err
+	}
+
+	if !b.contains(n) {
+		return fmt.Errorf("oer: value %s is not within the constraint", n)
+	}
+
+	data := asn1.EncodeInteger(n)
+	if !signed && len(data) > 1 && data[0] == 0x00 {
+		// unsigned values have no sign octet
+		data = data[1:]
+	}
+
+	if octets == 0 {
+		e.encodeOctets(data)
+		return nil
+	}
+
+	pad := byte(0x00)
+	if n.Sign() < 0 {
+		pad = 0xff
+	}
+
+	for i := len(data); i < octets; i++ {
+		e.buf.WriteByte(pad)
+	}
+
+	e.buf.Write(data)
+	return nil
+}
+
+// encodeEnumerated encodes an ENUMERATED (X.696 11), values 0..127 are
+// encoded in a single octet, other values as a signed integer preceded by
+// their length.
+func (e *encoder) encodeEnumerated(raw *asn1.RawValue) error {
+	n, err := asn1.ParseInteger(raw.Content)
+	if err != nil {
+		return err
+	}
+
+	if n.Sign() >= 0 && n.Cmp(big.NewInt(127)) <= 0 {
+		e.buf.WriteByte(byte(n.Int64()))
+		return nil
+	}
+
+	data := asn1.EncodeInteger(n)
+	if len(data) > 127 {
+		return fmt.Errorf("oer: enumeration value %s is too large", n)
+	}
+
+	e.buf.WriteByte(0x80 | byte(len(data)))
+	e.buf.Write(data)
+	return nil
+}
+
+// encodeBitString encodes a BIT STRING (X.696 13).
+func (e *encoder) encodeBitString(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	if len(raw.Content) == 0 {
+		return fmt.Errorf("oer: zero length BIT STRING")
+	}
+
+	size, fixed, err := e.fixedSize(t)
+	if err != nil {
+		return err
+	}
+
+	if !fixed {
+		// the content octets including the number of unused bits
+		e.encodeOctets(raw.Content)
+		return nil
+	}
+
+	if n := int64(len(raw.Content)-1)*8 - int64(raw.Content[0]); n != size {
+		return fmt.Errorf("oer: found BIT STRING of %d bits, expected %d", n, size)
+	}
+
+	e.buf.Write(raw.Content[1:])
+	return nil
+}
+
+// encodeString encodes an OCTET STRING or a known-multiplier character
+// string (X.696 14 and 27).
+func (e *encoder) encodeString(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	size, fixed, err := e.fixedSize(t)
+	if err != nil {
+		return err
+	}
+
+	if !fixed {
+		e.encodeOctets(raw.Content)
+		return nil
+	}
+
+	if n := int64(len(raw.Content)); n != size {
+		return fmt.Errorf("oer: found string of size %d, expected %d", n, size)
+	}
+
+	e.buf.Write(raw.Content)
+	return nil
+}
+
+// encodeSequenceOf encodes a SEQUENCE OF (X.696 17), the number of
+// components precedes the components.
+func (e *encoder) encodeSequenceOf(s *asn1parser.ASNSequence, raw *asn1.RawValue) error {
+	values, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	quantity := big.NewInt(int64(len(values))).Bytes()
+	if len(quantity) == 0 {
+		quantity = []byte{0x00}
+	}
+
+	e.encodeOctets(quantity)
+
+	element := e.def.ElementType(s)
+	for _, value := range values {
+		if err := e.encodeType(element, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeSequence encodes a SEQUENCE or SET (X.696 16 and 18).
+func (e *encoder) encodeSequence(items []asn1parser.ASNItem, raw *asn1.RawValue, set bool) error {
+	values, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	present, err := e.def.MatchItems(items, values, set)
+	if err != nil {
+		return fmt.Errorf("oer: %s", err)
+	}
+
+	root, additions, extensible := e.def.Components(items)
+	if set {
+		e.def.SortByTag(items, root)
+	}
+
+	extended := false
+	for _, i := range additions {
+		extended = extended || present[i] != nil
+	}
+
+	// preamble with the extension bit and the presence of OPTIONAL and
+	// DEFAULT components
+	preamble := []bool{}
+	if extensible {
+		preamble = append(preamble, extended)
+	}
+
+	for _, i := range root {
+		if items[i].Optional || items[i].Default != nil {
+			preamble = append(preamble, present[i] != nil)
+		}
+	}
+
+	e.encodeBits(preamble)
+
+	for _, i := range root {
+		if present[i] == nil {
+			continue
+		}
+
+		if err := e.encodeItem(items, i, present[i]); err != nil {
+			return err
+		}
+	}
+
+	if !extended {
+		return nil
+	}
+
+	// the presence bitmap of the additions is encoded as a BIT STRING
+	bitmap := make([]bool, len(additions))
+	for j, i := range additions {
+		bitmap[j] = present[i] != nil
+	}
+
+	e.encodeLength(1 + (len(bitmap)+7)/8)
+	e.buf.WriteByte(byte((8 - len(bitmap)%8) % 8))
+	e.encodeBits(bitmap)
+
+	for _, i := range additions {
+		if present[i] == nil {
+			continue
+		}
+
+		i := i
+		if err := e.encodeOpenType(func(sub *encoder) error {
+			return sub.encodeItem(items, i, present[i])
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeTag encodes the tag of a CHOICE alternative (X.696 8.7).
+func (e *encoder) encodeTag(tag asn1.ASNTag) {
+	class := byte(tag.Class) << 6
+
+	if tag.Value < 63 {
+		e.buf.WriteByte(class | byte(tag.Value))
+		return
+	}
+
+	e.buf.WriteByte(class | 0x3f)
+
+	data := []byte{byte(tag.Value & 0x7f)}
+	for v := tag.Value >> 7; v > 0; v >>= 7 {
+		data = append([]byte{0x80 | byte(v&0x7f)}, data...)
+	}
+
+	e.buf.Write(data)
+}
+
+// encodeChoice encodes a CHOICE (X.696 20), the tag of the alternative
+// precedes its value.
+func (e *encoder) encodeChoice(c *asn1parser.ASNChoice, raw *asn1.RawValue) error {
+	i, ok := e.def.MatchAlternative(c.Items, raw.Tag)
+	if !ok {
+		return fmt.Errorf("oer: no alternative of %s matches %s", c.Name(), raw.Tag)
+	}
+
+	e.encodeTag(raw.Tag)
+
+	_, additions, _ := e.def.Components(c.Items)
+	for _, j := range additions {
+		if j == i {
+			return e.encodeOpenType(func(sub *encoder) error {
+				return sub.encodeItem(c.Items, i, raw)
+			})
+		}
+	}
+
+	return e.encodeItem(c.Items, i, raw)
+}
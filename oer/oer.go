@@ -0,0 +1,213 @@
+// Package asn1oer implements the Octet Encoding Rules (X.696). OER
+// encodings can only be decoded with knowledge of the schema, the codec
+// transcodes between OER and the BER encoded RawValue used by the asn1
+// package, using the types of a parsed asn1parser definition.
+package asn1oer
+
+import (
+	"fmt"
+	"math/big"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// Variant is the variant of the Octet Encoding Rules.
+type Variant int
+
+const (
+	// Basic is BASIC-OER.
+	Basic Variant = iota
+	// Canonical is CANONICAL-OER, DEFAULT values are omitted and the
+	// decoder rejects encodings that are not canonical.
+	Canonical
+)
+
+// maxEmptyElements is the maximum number of elements of a SEQUENCE OF that
+// are encoded without octets.
+const maxEmptyElements = 1 << 16
+
+// Codec encodes and decodes values of the types of a definition.
+type Codec struct {
+	def       *asn1parser.ASNDefinition
+	canonical bool
+}
+
+// NewCodec returns a new Codec for the types of def.
+func NewCodec(def *asn1parser.ASNDefinition, variant Variant) *Codec {
+	return &Codec{
+		def:       def,
+		canonical: variant == Canonical,
+	}
+}
+
+// Decode decodes data, the complete OER encoding of a value of the type
+// typeName, into the BER encoded value.
+func (c *Codec) Decode(typeName string, data []byte) (*asn1.RawValue, error) {
+	t := c.def.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("oer: unknown type %s", typeName)
+	}
+
+	d := &decoder{
+		Codec: c,
+		r: reader{
+			data: data,
+		},
+	}
+
+	raw, err := d.decodeType(t)
+	if err != nil {
+		return nil, err
+	}
+
+	if rest := d.r.remaining(); rest > 0 {
+		return nil, fmt.Errorf("oer: %d trailing octets", rest)
+	}
+
+	return raw, nil
+}
+
+// Encode returns the complete OER encoding of raw, a BER encoded value of
+// the type typeName.
+func (c *Codec) Encode(typeName string, raw *asn1.RawValue) ([]byte, error) {
+	t := c.def.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("oer: unknown type %s", typeName)
+	}
+
+	var err error
+	if c.canonical {
+		raw, err = c.def.ToDER(typeName, raw)
+	} else {
+		// reassemble segmented strings and indefinite lengths
+		raw, err = asn1.ToDER(raw)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	e := &encoder{
+		Codec: c,
+	}
+
+	if err := e.encodeType(t, raw); err != nil {
+		return nil, err
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// bounds is a parsed value range or SIZE constraint.
+type bounds struct {
+	lb, ub       int64
+	hasLB, hasUB bool
+}
+
+// fixed returns true when the constraint allows a single value only.
+func (b bounds) fixed() bool {
+	return b.hasLB && b.hasUB && b.lb == b.ub
+}
+
+// contains returns true when n is within the bounds.
+func (b bounds) contains(n *big.Int) bool {
+	return (!b.hasLB || n.Cmp(big.NewInt(b.lb)) >= 0) && (!b.hasUB || n.Cmp(big.NewInt(b.ub)) <= 0)
+}
+
+// bounds parses a constraint. Extensible constraints are not OER-visible
+// (X.696 8.2.2), these and nil constraints are unbounded.
+func (c *Codec) bounds(r *asn1parser.ASNRange) (bounds, error) {
+	b := bounds{}
+	if r == nil || r.Extensible {
+		return b, nil
+	}
+
+	if r.From != "" && r.From != "MIN" {
+		v, ok := c.def.Value(r.From)
+		if !ok {
+			return b, fmt.Errorf("oer: unknown value %s", r.From)
+		}
+
+		b.lb, b.hasLB = v, true
+	}
+
+	if r.To != "" && r.To != "MAX" {
+		v, ok := c.def.Value(r.To)
+		if !ok {
+			return b, fmt.Errorf("oer: unknown value %s", r.To)
+		}
+
+		b.ub, b.hasUB = v, true
+	}
+
+	return b, nil
+}
+
+// fixedSize returns the size of t when t has a fixed size constraint, these
+// values are encoded without length determinant.
+func (c *Codec) fixedSize(t asn1parser.ASNType) (int64, bool, error) {
+	size, _ := c.def.Constraints(t)
+
+	b, err := c.bounds(size)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return b.ub, b.fixed(), nil
+}
+
+// integerForm returns the number of octets of the fixed size encoding of an
+// INTEGER of type t (X.696 10), zero for the variable size encoding. The
+// encoding is unsigned when the lower bound is not negative.
+func (c *Codec) integerForm(t asn1parser.ASNType) (b bounds, octets int, signed bool, err error) {
+	_, rng := c.def.Constraints(t)
+
+	if b, err = c.bounds(rng); err != nil {
+		return b, 0, false, err
+	}
+
+	switch {
+	case b.hasLB && b.lb >= 0:
+		if !b.hasUB {
+			return b, 0, false, nil
+		}
+
+		switch {
+		case b.ub <= 0xff:
+			return b, 1, false, nil
+		case b.ub <= 0xffff:
+			return b, 2, false, nil
+		case b.ub <= 0xffffffff:
+			return b, 4, false, nil
+		}
+
+		return b, 8, false, nil
+	case b.hasLB && b.hasUB:
+		switch {
+		case b.lb >= -1<<7 && b.ub < 1<<7:
+			return b, 1, true, nil
+		case b.lb >= -1<<15 && b.ub < 1<<15:
+			return b, 2, true, nil
+		case b.lb >= -1<<31 && b.ub < 1<<31:
+			return b, 4, true, nil
+		}
+
+		return b, 8, true, nil
+	}
+
+	return b, 0, true, nil
+}
+
+// isKnownMultiplier returns true for the character string types with a
+// fixed number of octets per character.
+func isKnownMultiplier(t asn1parser.ASNType) bool {
+	switch v := t.(type) {
+	case *asn1parser.ASNNumericString, *asn1parser.ASNPrintableString, *asn1parser.ASNVisibleString:
+		return true
+	case *asn1parser.ASNCustom:
+		return v.Type == "IA5String"
+	}
+
+	return false
+}
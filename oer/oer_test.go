@@ -0,0 +1,238 @@
+package asn1oer_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/oer"
+	"github.com/dutchsec/asn1/parser"
+)
+
+const scheme = `
+Test DEFINITIONS AUTOMATIC TAGS ::=
+BEGIN
+
+Byte ::= INTEGER (0..255)
+Word ::= INTEGER (0..65535)
+Dword ::= INTEGER (0..4294967295)
+Signed ::= INTEGER (-128..127)
+Short ::= INTEGER (-1000..1000)
+Positive ::= INTEGER (1..MAX)
+Number ::= INTEGER
+Loose ::= INTEGER (0..10, ...)
+Flag ::= BOOLEAN
+Nibble ::= BIT STRING (SIZE (4))
+Bits ::= BIT STRING
+Data ::= OCTET STRING
+Fixed ::= OCTET STRING (SIZE (2))
+Name ::= IA5String (SIZE (1..8))
+Digits ::= NumericString (SIZE (3))
+Color ::= ENUMERATED { red, green, blue }
+Range ::= ENUMERATED { low(-1), high(1000) }
+
+Record ::= SEQUENCE {
+	enabled BOOLEAN,
+	level Small OPTIONAL
+}
+
+Extended ::= SEQUENCE {
+	level Small,
+	...,
+	enabled BOOLEAN
+}
+
+Options ::= SEQUENCE {
+	mode Small DEFAULT 1,
+	enabled BOOLEAN
+}
+
+Choice ::= CHOICE {
+	level INTEGER (0..3),
+	enabled BOOLEAN
+}
+
+List ::= SEQUENCE (SIZE (1..4)) OF Small
+
+Small ::= INTEGER (0..7)
+
+Unit ::= NULL
+Units ::= SEQUENCE OF Unit
+
+END
+`
+
+func TestCodec(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var tests = []struct {
+		typ string
+		ber string
+		oer string
+	}{
+		{typ: "Byte", ber: "020105", oer: "05"},
+		{typ: "Word", ber: "02020102", oer: "0102"},
+		{typ: "Dword", ber: "020101", oer: "00000001"},
+		{typ: "Signed", ber: "0201ff", oer: "ff"},
+		{typ: "Short", ber: "0201fe", oer: "fffe"},
+		{typ: "Positive", ber: "02020100", oer: "020100"},
+		{typ: "Positive", ber: "020200ff", oer: "01ff"},
+		{typ: "Number", ber: "02020100", oer: "020100"},
+		{typ: "Number", ber: "0201ff", oer: "01ff"},
+		{typ: "Loose", ber: "020105", oer: "0105"},
+		{typ: "Flag", ber: "0101ff", oer: "ff"},
+		{typ: "Nibble", ber: "030204a0", oer: "a0"},
+		{typ: "Bits", ber: "030204a0", oer: "0204a0"},
+		{typ: "Data", ber: "04020102", oer: "020102"},
+		{typ: "Fixed", ber: "04020102", oer: "0102"},
+		{typ: "Name", ber: "16026162", oer: "026162"},
+		{typ: "Digits", ber: "1203303139", oer: "303139"},
+		{typ: "Color", ber: "0a0101", oer: "01"},
+		{typ: "Range", ber: "0a0203e8", oer: "8203e8"},
+		{typ: "Range", ber: "0a01ff", oer: "81ff"},
+		{typ: "Record", ber: "30068001ff810103", oer: "80ff03"},
+		{typ: "Record", ber: "30038001ff", oer: "00ff"},
+		{typ: "Extended", ber: "30068001018101ff", oer: "800102078001ff"},
+		{typ: "Extended", ber: "3003800101", oer: "0001"},
+		{typ: "Options", ber: "30068001028101ff", oer: "8002ff"},
+		{typ: "Choice", ber: "8101ff", oer: "81ff"},
+		{typ: "Choice", ber: "800102", oer: "8002"},
+		{typ: "List", ber: "3006020101020102", oer: "01020102"},
+		{typ: "Units", ber: "300405000500", oer: "0102"},
+	}
+
+	for i, tt := range tests {
+		ber, _ := hex.DecodeString(tt.ber)
+
+		raw, err := asn1.DecodeRawValue(bytes.NewReader(ber))
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		for _, variant := range []asn1oer.Variant{asn1oer.Basic, asn1oer.Canonical} {
+			codec := asn1oer.NewCodec(def, variant)
+
+			data, err := codec.Encode(tt.typ, raw)
+			if err != nil {
+				t.Errorf("%d. unexpected error: %s", i, err)
+				continue
+			}
+
+			if hex.EncodeToString(data) != tt.oer {
+				t.Errorf("%d. encoding mismatch: exp=%s got=%x", i, tt.oer, data)
+			}
+
+			value, err := codec.Decode(tt.typ, data)
+			if err != nil {
+				t.Errorf("%d. unexpected error: %s", i, err)
+				continue
+			}
+
+			out, err := value.Encode()
+			if err != nil {
+				t.Fatalf("%d. unexpected error: %s", i, err)
+			}
+
+			if !bytes.Equal(out, ber) {
+				t.Errorf("%d. decoding mismatch: exp=%x got=%x", i, ber, out)
+			}
+		}
+	}
+}
+
+func TestCodec_Canonical(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// mode has the DEFAULT value
+	ber, _ := hex.DecodeString("30068001018101ff")
+
+	raw, err := asn1.DecodeRawValue(bytes.NewReader(ber))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var tests = []struct {
+		variant asn1oer.Variant
+		oer     string
+	}{
+		{variant: asn1oer.Basic, oer: "8001ff"},
+		{variant: asn1oer.Canonical, oer: "00ff"},
+	}
+
+	for i, tt := range tests {
+		data, err := asn1oer.NewCodec(def, tt.variant).Encode("Options", raw)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if hex.EncodeToString(data) != tt.oer {
+			t.Errorf("%d. encoding mismatch: exp=%s got=%x", i, tt.oer, data)
+		}
+	}
+
+	// encodings that are valid BASIC-OER only
+	var nonCanonical = []struct {
+		typ string
+		oer string
+	}{
+		{typ: "Flag", oer: "01"},
+		{typ: "Data", oer: "8101ab"},
+		{typ: "Number", oer: "020001"},
+		{typ: "Range", oer: "8101"},
+	}
+
+	for i, tt := range nonCanonical {
+		data, _ := hex.DecodeString(tt.oer)
+
+		if _, err := asn1oer.NewCodec(def, asn1oer.Basic).Decode(tt.typ, data); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		}
+
+		if _, err := asn1oer.NewCodec(def, asn1oer.Canonical).Decode(tt.typ, data); err == nil {
+			t.Errorf("%d. expected error for %s", i, tt.oer)
+		}
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	codec := asn1oer.NewCodec(def, asn1oer.Basic)
+
+	var tests = []struct {
+		typ string
+		oer string
+	}{
+		{typ: "Unknown", oer: "00"},
+		{typ: "Byte", oer: ""},
+		{typ: "Byte", oer: "0500"},
+		{typ: "Small", oer: "08"},
+		{typ: "Data", oer: "0501"},
+		{typ: "Data", oer: "80"},
+		{typ: "Choice", oer: "8201"},
+		{typ: "Extended", oer: "80010107"},
+		{typ: "Bits", oer: "0108"},
+		{typ: "Units", oer: "050100000000"},
+		{typ: "Units", oer: "0103010001"},
+		{typ: "List", oer: "0104ffffffff"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(tt.oer)
+
+		if _, err := codec.Decode(tt.typ, data); err == nil {
+			t.Errorf("%d. expected error for %s", i, tt.oer)
+		}
+	}
+}
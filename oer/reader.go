@@ -0,0 +1,43 @@
+package asn1oer
+
+import (
+	"fmt"
+)
+
+// errUnexpectedEnd is returned when the data ends before a value is read
+// completely.
+var errUnexpectedEnd = fmt.Errorf("oer: unexpected end of data")
+
+// reader reads octets from an encoding.
+type reader struct {
+	data []byte
+	pos  int
+}
+
+// remaining returns the number of unread octets.
+func (r *reader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+// readByte reads a single octet.
+func (r *reader) readByte() (byte, error) {
+	if r.remaining() < 1 {
+		return 0, errUnexpectedEnd
+	}
+
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+// readBytes reads n octets.
+func (r *reader) readBytes(n uint64) ([]byte, error) {
+	if n > uint64(r.remaining()) {
+		return nil, errUnexpectedEnd
+	}
+
+	data := make([]byte, n)
+	copy(data, r.data[r.pos:])
+	r.pos += int(n)
+	return data, nil
+}
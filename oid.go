@@ -0,0 +1,422 @@
+package asn1
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ObjectIdentifier is an OBJECT IDENTIFIER (X.690 8.19), held in its dotted
+// decimal notation, eg. "1.2.840.113549". The arcs are not limited in size,
+// so the UUID based arcs below 2.25 can be represented. ObjectIdentifiers are
+// comparable and can be used as map keys, values returned by
+// ParseObjectIdentifier and UnmarshalRawValue are in canonical form.
+type ObjectIdentifier struct {
+	string
+}
+
+// NewObjectIdentifier returns the ObjectIdentifier with arcs. The arcs are
+// validated when the value is marshaled.
+func NewObjectIdentifier(arcs ...uint) ObjectIdentifier {
+	parts := make([]string, len(arcs))
+	for i, arc := range arcs {
+		parts[i] = strconv.FormatUint(uint64(arc), 10)
+	}
+
+	return ObjectIdentifier{strings.Join(parts, ".")}
+}
+
+// ParseObjectIdentifier parses the dotted decimal notation s, a *SyntaxError
+// is returned when s is not a valid object identifier.
+func ParseObjectIdentifier(s string) (ObjectIdentifier, error) {
+	data, err := encodeObjectIdentifier(s)
+	if err != nil {
+		return ObjectIdentifier{}, err
+	}
+
+	// decoding the encoding results in the canonical form
+	return decodeObjectIdentifier(data)
+}
+
+// String returns the dotted decimal notation of s.
+func (s ObjectIdentifier) String() string {
+	return s.string
+}
+
+// Arcs returns the arcs of s, ok is false when s contains an arc that does
+// not fit in a uint64.
+func (s ObjectIdentifier) Arcs() (arcs []uint64, ok bool) {
+	if s.string == "" {
+		return nil, true
+	}
+
+	for _, part := range strings.Split(s.string, ".") {
+		arc, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+
+		arcs = append(arcs, arc)
+	}
+
+	return arcs, true
+}
+
+// Cmp returns zero if both object identifiers are the same, a negative value
+// if s precedes other in the order of their arcs and a positive value
+// otherwise. Both must be in canonical form.
+func (s ObjectIdentifier) Cmp(other ObjectIdentifier) int {
+	a, b := strings.Split(s.string, "."), strings.Split(other.string, ".")
+
+	for i := range a {
+		if i >= len(b) {
+			return 1
+		}
+
+		// canonical arcs have no leading zeros, so longer arcs are larger
+		if len(a[i]) != len(b[i]) {
+			return len(a[i]) - len(b[i])
+		}
+
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(a) - len(b)
+}
+
+func (s *ObjectIdentifier) UnmarshalRawValue(rv *RawValue) error {
+	oid, err := decodeObjectIdentifier(rv.Content)
+	if err != nil {
+		return err
+	}
+
+	*s = oid
+	return nil
+}
+
+func (s ObjectIdentifier) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeObjectIdentifier(s.string)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagOid),
+		Content: data,
+	}, nil
+}
+
+// decodeObjectIdentifier decodes the content octets of an OBJECT
+// IDENTIFIER.
+func decodeObjectIdentifier(data []byte) (ObjectIdentifier, error) {
+	if len(data) == 0 {
+		return ObjectIdentifier{}, kindError(ErrInvalidLength, "empty OBJECT IDENTIFIER")
+	}
+
+	parts, err := decodeArcs(data, "OBJECT IDENTIFIER", true)
+	if err != nil {
+		return ObjectIdentifier{}, err
+	}
+
+	return ObjectIdentifier{strings.Join(parts, ".")}, nil
+}
+
+// decodeArcs decodes the subidentifiers in data to decimal arcs, the first
+// subidentifier combines the first two arcs if combined is set (X.690
+// 8.19.4). Subidentifiers must be encoded in the fewest possible octets
+// (X.690 8.19.2 and 8.20.2).
+func decodeArcs(data []byte, name string, combined bool) ([]string, error) {
+	var parts []string
+	for len(data) > 0 {
+		if data[0] == 0x80 {
+			return nil, parseError("subidentifier of %s is not encoded in the fewest possible octets", name)
+		}
+
+		n := 0
+		for n < len(data) && data[n]&0x80 != 0 {
+			n++
+		}
+
+		if n == len(data) {
+			return nil, kindError(ErrTruncated, "truncated subidentifier in %s", name)
+		}
+
+		var sub []byte
+		sub, data = data[:n+1], data[n+1:]
+
+		first := combined && parts == nil
+
+		if len(sub) <= 9 {
+			v := uint64(0)
+			for _, b := range sub {
+				v = v<<7 | uint64(b&0x7f)
+			}
+
+			switch {
+			case !first:
+				parts = append(parts, strconv.FormatUint(v, 10))
+			case v < 80:
+				parts = append(parts, strconv.FormatUint(v/40, 10), strconv.FormatUint(v%40, 10))
+			default:
+				parts = append(parts, "2", strconv.FormatUint(v-80, 10))
+			}
+
+			continue
+		}
+
+		v := new(big.Int)
+		for _, b := range sub {
+			v.Lsh(v, 7).Or(v, big.NewInt(int64(b&0x7f)))
+		}
+
+		if first {
+			parts = append(parts, "2")
+			v.Sub(v, big.NewInt(80))
+		}
+
+		parts = append(parts, v.String())
+	}
+
+	return parts, nil
+}
+
+// encodeObjectIdentifier returns the content octets of the object
+// identifier in dotted decimal notation s.
+func encodeObjectIdentifier(s string) ([]byte, error) {
+	arcs, err := parseArcs(s, "object identifier")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(arcs) < 2:
+		return nil, syntaxError("object identifier %q has less than two arcs", s)
+	case arcs[0].Cmp(big.NewInt(2)) > 0:
+		return nil, syntaxError("invalid value for first arc of object identifier %q", s)
+	case arcs[0].Cmp(big.NewInt(2)) < 0 && arcs[1].Cmp(big.NewInt(39)) > 0:
+		return nil, syntaxError("invalid value for second arc of object identifier %q", s)
+	}
+
+	first := new(big.Int).Mul(arcs[0], big.NewInt(40))
+	arcs = append([]*big.Int{first.Add(first, arcs[1])}, arcs[2:]...)
+
+	var data []byte
+	for _, arc := range arcs {
+		data = appendSubidentifier(data, arc)
+	}
+
+	return data, nil
+}
+
+// parseArcs parses the arcs of the dotted decimal notation s.
+func parseArcs(s string, name string) ([]*big.Int, error) {
+	parts := strings.Split(s, ".")
+
+	arcs := make([]*big.Int, len(parts))
+	for i, part := range parts {
+		arc, ok := new(big.Int).SetString(part, 10)
+		if !ok || !isDigits(part) {
+			return nil, syntaxError("invalid %s %q", name, s)
+		}
+
+		arcs[i] = arc
+	}
+
+	return arcs, nil
+}
+
+// appendSubidentifier appends the base 128 encoding of v to data, all octets
+// but the last have the most significant bit set.
+func appendSubidentifier(data []byte, v *big.Int) []byte {
+	n := (v.BitLen() + 6) / 7
+	if n == 0 {
+		n = 1
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		b := byte(new(big.Int).Rsh(v, uint(7*i)).Uint64() & 0x7f)
+		if i > 0 {
+			b |= 0x80
+		}
+
+		data = append(data, b)
+	}
+
+	return data
+}
+
+// RelativeObjectIdentifier is a RELATIVE-OID (X.690 8.20), the arcs of an
+// object identifier relative to a known object identifier, held in its
+// dotted decimal notation, eg. "8571.3.2".
+type RelativeObjectIdentifier struct {
+	string
+}
+
+// NewRelativeObjectIdentifier returns the RelativeObjectIdentifier with
+// arcs.
+func NewRelativeObjectIdentifier(arcs ...uint) RelativeObjectIdentifier {
+	return RelativeObjectIdentifier{NewObjectIdentifier(arcs...).string}
+}
+
+// ParseRelativeObjectIdentifier parses the dotted decimal notation s, a
+// *SyntaxError is returned when s is not a valid relative object identifier.
+func ParseRelativeObjectIdentifier(s string) (RelativeObjectIdentifier, error) {
+	data, err := encodeRelativeObjectIdentifier(s)
+	if err != nil {
+		return RelativeObjectIdentifier{}, err
+	}
+
+	return decodeRelativeObjectIdentifier(data)
+}
+
+// String returns the dotted decimal notation of s.
+func (s RelativeObjectIdentifier) String() string {
+	return s.string
+}
+
+// Resolve returns the object identifier of s relative to base.
+func (s RelativeObjectIdentifier) Resolve(base ObjectIdentifier) ObjectIdentifier {
+	return ObjectIdentifier{base.string + "." + s.string}
+}
+
+func (s *RelativeObjectIdentifier) UnmarshalRawValue(rv *RawValue) error {
+	oid, err := decodeRelativeObjectIdentifier(rv.Content)
+	if err != nil {
+		return err
+	}
+
+	*s = oid
+	return nil
+}
+
+func (s RelativeObjectIdentifier) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeRelativeObjectIdentifier(s.string)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagRelativeOid),
+		Content: data,
+	}, nil
+}
+
+func decodeRelativeObjectIdentifier(data []byte) (RelativeObjectIdentifier, error) {
+	if len(data) == 0 {
+		return RelativeObjectIdentifier{}, kindError(ErrInvalidLength, "empty RELATIVE-OID")
+	}
+
+	parts, err := decodeArcs(data, "RELATIVE-OID", false)
+	if err != nil {
+		return RelativeObjectIdentifier{}, err
+	}
+
+	return RelativeObjectIdentifier{strings.Join(parts, ".")}, nil
+}
+
+func encodeRelativeObjectIdentifier(s string) ([]byte, error) {
+	arcs, err := parseArcs(s, "relative object identifier")
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	for _, arc := range arcs {
+		data = appendSubidentifier(data, arc)
+	}
+
+	return data, nil
+}
+
+// OidIri is an OID-IRI (X.680 34), an object identifier written as the
+// Unicode labels of its arcs, eg. "/ISO/Registration_Authority/19785.CBEFF".
+// It is encoded as UTF-8 (X.690 8.21).
+type OidIri struct {
+	string
+}
+
+// NewOidIri returns the OidIri of s. The value is validated when it is
+// marshaled.
+func NewOidIri(s string) OidIri {
+	return OidIri{s}
+}
+
+// String returns the IRI notation of s.
+func (s OidIri) String() string {
+	return s.string
+}
+
+// Labels returns the arc labels of s.
+func (s OidIri) Labels() []string {
+	return strings.Split(strings.TrimPrefix(s.string, "/"), "/")
+}
+
+func (s *OidIri) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
+	if err := checkOidIri(string(data)); err != nil {
+		return kindError(ErrConstraint, "%s", err.Msg)
+	}
+
+	*s = OidIri{string(data)}
+	return nil
+}
+
+func (s OidIri) MarshalRawValue() (*RawValue, error) {
+	if err := checkOidIri(s.string); err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagOidIri),
+		Content: []byte(s.string),
+	}, nil
+}
+
+// checkOidIri validates the OID-IRI s, a sequence of arc labels each
+// preceded by a solidus (X.680 34.3). A label is a decimal number without
+// leading zeros, or a non-integer Unicode label (X.660 7.5).
+func checkOidIri(s string) *SyntaxError {
+	invalid := func(format string, args ...interface{}) *SyntaxError {
+		return &SyntaxError{Msg: fmt.Sprintf(format, args...), Err: ErrConstraint}
+	}
+
+	if !utf8.ValidString(s) {
+		return invalid("invalid UTF-8 in OID-IRI")
+	}
+
+	if !strings.HasPrefix(s, "/") {
+		return invalid("OID-IRI %q does not start with a solidus", s)
+	}
+
+	for _, label := range strings.Split(s[1:], "/") {
+		switch {
+		case label == "":
+			return invalid("empty arc label in OID-IRI %q", s)
+		case isDigits(label):
+			if len(label) > 1 && label[0] == '0' {
+				return invalid("integer label %q of OID-IRI has leading zeros", label)
+			}
+
+			continue
+		case strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-"):
+			return invalid("invalid hyphens in arc label %q of OID-IRI", label)
+		}
+
+		for _, r := range label {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-._~", r) {
+				return invalid("invalid character %q in arc label of OID-IRI", r)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package asn1_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestObjectIdentifier(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{in: "2a864886f70d", out: "1.2.840.113549"},
+		{in: "00", out: "0.0"},
+		{in: "27", out: "0.39"},
+		{in: "5000", out: "2.0.0"},
+		{in: "8837", out: "2.999"},
+		{in: "2b0601020101", out: "1.3.6.1.2.1.1"},
+		{in: "2b81ffffffffffffffff7f", out: "1.3.18446744073709551615"},
+		{in: "2b82808080808080808000", out: "1.3.18446744073709551616"},
+		// a UUID based arc below 2.25
+		{in: "6983f09da7ebcfdee0c7a1a7b2c0948cc8f9d776", out: "2.25.329800735698586629295641978511506172918"},
+		{in: "83ffffffffffffffff7f", out: "2.36893488147419103151"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString("06" + hex.EncodeToString([]byte{byte(len(tt.in) / 2)}) + tt.in)
+
+		var oid asn1.ObjectIdentifier
+		if err := asn1.Unmarshal(data, &oid); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if oid.String() != tt.out {
+			t.Errorf("%d. value mismatch: exp=%s got=%s", i, tt.out, oid)
+		}
+
+		parsed, err := asn1.ParseObjectIdentifier(tt.out)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if parsed != oid {
+			t.Errorf("%d. parsed value mismatch: exp=%s got=%s", i, oid, parsed)
+		}
+
+		out, err := asn1.Marshal(oid)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+			t.Errorf("%d. encoding mismatch: exp=%x got=%x", i, data, out)
+		}
+	}
+}
+
+func TestObjectIdentifier_Errors(t *testing.T) {
+	var tests = []struct {
+		in   string
+		kind error
+	}{
+		{in: "", kind: asn1.ErrInvalidLength},
+		{in: "2a86", kind: asn1.ErrTruncated},
+		{in: "2a8086f70d"},
+		{in: "80 01"},
+	}
+
+	for i, tt := range tests {
+		in := stripSpaces(tt.in)
+		data, _ := hex.DecodeString("06" + hex.EncodeToString([]byte{byte(len(in) / 2)}) + in)
+
+		var oid asn1.ObjectIdentifier
+		err := asn1.Unmarshal(data, &oid)
+		if _, ok := err.(*asn1.ParseError); !ok {
+			t.Errorf("%d. expected *ParseError, got %v", i, err)
+		} else if tt.kind != nil && !errors.Is(err, tt.kind) {
+			t.Errorf("%d. expected error of kind %v, got %v", i, tt.kind, err)
+		}
+	}
+
+	for i, s := range []string{"", "1", "3.1", "1.40", "1.2.x", "1..2", "1.2.", "1.-2", "+1.2"} {
+		if _, err := asn1.ParseObjectIdentifier(s); err == nil {
+			t.Errorf("%d. %q: expected error", i, s)
+		}
+	}
+
+	if _, err := asn1.Marshal(asn1.NewObjectIdentifier(1, 40)); err == nil {
+		t.Errorf("expected error for invalid second arc")
+	}
+}
+
+func TestObjectIdentifier_Arcs(t *testing.T) {
+	oid, _ := asn1.ParseObjectIdentifier("1.2.0840.113549")
+	if oid.String() != "1.2.840.113549" {
+		t.Errorf("value not canonical: %s", oid)
+	}
+
+	if arcs, ok := oid.Arcs(); !ok || len(arcs) != 4 || arcs[2] != 840 {
+		t.Errorf("unexpected arcs: %v", arcs)
+	}
+
+	uuid, _ := asn1.ParseObjectIdentifier("2.25.329800735698586629295641978511506172918")
+	if _, ok := uuid.Arcs(); ok {
+		t.Errorf("expected arcs not to fit uint64")
+	}
+
+	var tests = []struct {
+		a, b string
+		cmp  int
+	}{
+		{a: "1.2.840", b: "1.2.840", cmp: 0},
+		{a: "1.2.840", b: "1.2.840.1", cmp: -1},
+		{a: "1.2.99", b: "1.2.840", cmp: -1},
+		{a: "1.3", b: "1.2.840", cmp: 1},
+		{a: "2.25.10", b: "2.25.9", cmp: 1},
+	}
+
+	for i, tt := range tests {
+		a, _ := asn1.ParseObjectIdentifier(tt.a)
+		b, _ := asn1.ParseObjectIdentifier(tt.b)
+
+		if cmp := a.Cmp(b); cmp < 0 && tt.cmp >= 0 || cmp > 0 && tt.cmp <= 0 || cmp == 0 && tt.cmp != 0 {
+			t.Errorf("%d. %s %s: unexpected result %d", i, tt.a, tt.b, cmp)
+		}
+	}
+}
+
+func TestRelativeObjectIdentifier(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{in: "c27b0302", out: "8571.3.2"},
+		{in: "00", out: "0"},
+		{in: "7f", out: "127"},
+		{in: "8100", out: "128"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString("0d" + hex.EncodeToString([]byte{byte(len(tt.in) / 2)}) + tt.in)
+
+		var oid asn1.RelativeObjectIdentifier
+		if err := asn1.Unmarshal(data, &oid); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if oid.String() != tt.out {
+			t.Errorf("%d. value mismatch: exp=%s got=%s", i, tt.out, oid)
+		}
+
+		out, err := asn1.Marshal(oid)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+			t.Errorf("%d. encoding mismatch: exp=%x got=%x", i, data, out)
+		}
+	}
+
+	var oid asn1.RelativeObjectIdentifier
+	for i, in := range []string{"0d00", "0d0181", "0d028001"} {
+		data, _ := hex.DecodeString(in)
+		if err := asn1.Unmarshal(data, &oid); err == nil {
+			t.Errorf("%d. %s: expected error", i, in)
+		}
+	}
+
+	for i, s := range []string{"", "1..2", "1.x"} {
+		if _, err := asn1.ParseRelativeObjectIdentifier(s); err == nil {
+			t.Errorf("%d. %q: expected error", i, s)
+		}
+	}
+
+	base, _ := asn1.ParseObjectIdentifier("1.3.6.1.4.1")
+	rel, _ := asn1.ParseRelativeObjectIdentifier("8571.3")
+	if oid := rel.Resolve(base); oid.String() != "1.3.6.1.4.1.8571.3" {
+		t.Errorf("unexpected resolved value: %s", oid)
+	}
+}
+
+func TestOidIri(t *testing.T) {
+	var tests = []struct {
+		in    string
+		valid bool
+	}{
+		{in: "/ISO/Registration_Authority/19785.CBEFF", valid: true},
+		{in: "/Joint-ISO-ITU-T/Example", valid: true},
+		{in: "/2/25/42", valid: true},
+		{in: "/ISO/Événement", valid: true},
+		{in: ""},
+		{in: "ISO/Example"},
+		{in: "/ISO//Example"},
+		{in: "/ISO/"},
+		{in: "/01"},
+		{in: "/-ISO"},
+		{in: "/ISO Example"},
+	}
+
+	for i, tt := range tests {
+		data, err := asn1.Marshal(asn1.NewOidIri(tt.in))
+		if tt.valid != (err == nil) {
+			t.Errorf("%d. %q: unexpected marshal result: %v", i, tt.in, err)
+		}
+
+		raw := asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagOidIri), Content: []byte(tt.in)}
+		data, _ = raw.Encode()
+
+		var iri asn1.OidIri
+		err = asn1.Unmarshal(data, &iri)
+		if tt.valid != (err == nil) {
+			t.Errorf("%d. %q: unexpected unmarshal result: %v", i, tt.in, err)
+		} else if err != nil && !errors.Is(err, asn1.ErrConstraint) {
+			t.Errorf("%d. %q: expected error of kind ErrConstraint, got %v", i, tt.in, err)
+		} else if tt.valid && iri.String() != tt.in {
+			t.Errorf("%d. value mismatch: exp=%s got=%s", i, tt.in, iri)
+		}
+	}
+
+	if labels := asn1.NewOidIri("/ISO/Registration_Authority").Labels(); len(labels) != 2 || labels[1] != "Registration_Authority" {
+		t.Errorf("unexpected labels: %q", labels)
+	}
+}
@@ -0,0 +1,225 @@
+package asn1
+
+import (
+	"sync"
+)
+
+// OidInfo describes a registered object identifier.
+type OidInfo struct {
+	// Name is the symbolic name, eg. "id-ce-basicConstraints".
+	Name        string
+	Description string
+}
+
+// OidRegistry maps object identifiers to their symbolic names and
+// descriptions. An OidRegistry is safe for concurrent use.
+type OidRegistry struct {
+	mu    sync.RWMutex
+	oids  map[ObjectIdentifier]OidInfo
+	names map[string]ObjectIdentifier
+}
+
+// NewOidRegistry allocates an empty OidRegistry.
+func NewOidRegistry() *OidRegistry {
+	return &OidRegistry{
+		oids:  map[ObjectIdentifier]OidInfo{},
+		names: map[string]ObjectIdentifier{},
+	}
+}
+
+// Register adds oid to the registry, replacing a previous registration of
+// oid. The name is looked up by LookupName, a name that is registered for
+// more than one object identifier resolves to the last one registered.
+func (r *OidRegistry) Register(oid ObjectIdentifier, info OidInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.oids[oid] = info
+	if info.Name != "" {
+		r.names[info.Name] = oid
+	}
+}
+
+// Lookup returns the registration of oid.
+func (r *OidRegistry) Lookup(oid ObjectIdentifier) (OidInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	info, ok := r.oids[oid]
+	return info, ok
+}
+
+// LookupName returns the object identifier registered with the symbolic
+// name.
+func (r *OidRegistry) LookupName(name string) (ObjectIdentifier, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	oid, ok := r.names[name]
+	return oid, ok
+}
+
+// Name returns the symbolic name of oid, or its dotted decimal notation when
+// oid has no registered name.
+func (r *OidRegistry) Name(oid ObjectIdentifier) string {
+	if info, ok := r.Lookup(oid); ok && info.Name != "" {
+		return info.Name
+	}
+
+	return oid.String()
+}
+
+// DefaultOidRegistry contains the well-known object identifiers of PKIX,
+// SNMP MIB-2, Kerberos and IEC 61850. Applications can register additional
+// object identifiers.
+var DefaultOidRegistry = newDefaultOidRegistry()
+
+// RegisterOid adds oid to DefaultOidRegistry.
+func RegisterOid(oid ObjectIdentifier, info OidInfo) {
+	DefaultOidRegistry.Register(oid, info)
+}
+
+// LookupOid returns the registration of oid in DefaultOidRegistry.
+func LookupOid(oid ObjectIdentifier) (OidInfo, bool) {
+	return DefaultOidRegistry.Lookup(oid)
+}
+
+// newDefaultOidRegistry returns a registry with the well-known object
+// identifiers.
+func newDefaultOidRegistry() *OidRegistry {
+	r := NewOidRegistry()
+
+	for _, o := range wellKnownOids {
+		oid, err := ParseObjectIdentifier(o.oid)
+		if err != nil {
+			panic(err)
+		}
+
+		r.Register(oid, OidInfo{Name: o.name, Description: o.description})
+	}
+
+	return r
+}
+
+// wellKnownOids are the object identifiers of DefaultOidRegistry.
+var wellKnownOids = []struct {
+	oid         string
+	name        string
+	description string
+}{
+	// X.500 attribute types (RFC 5280, X.520)
+	{"2.5.4.3", "id-at-commonName", "Common name"},
+	{"2.5.4.4", "id-at-surname", "Surname"},
+	{"2.5.4.5", "id-at-serialNumber", "Serial number"},
+	{"2.5.4.6", "id-at-countryName", "Country name"},
+	{"2.5.4.7", "id-at-localityName", "Locality name"},
+	{"2.5.4.8", "id-at-stateOrProvinceName", "State or province name"},
+	{"2.5.4.9", "id-at-streetAddress", "Street address"},
+	{"2.5.4.10", "id-at-organizationName", "Organization name"},
+	{"2.5.4.11", "id-at-organizationalUnitName", "Organizational unit name"},
+	{"2.5.4.12", "id-at-title", "Title"},
+	{"2.5.4.42", "id-at-givenName", "Given name"},
+	{"0.9.2342.19200300.100.1.25", "id-domainComponent", "Domain component"},
+	{"1.2.840.113549.1.9.1", "id-emailAddress", "Email address"},
+
+	// certificate extensions (RFC 5280)
+	{"2.5.29.14", "id-ce-subjectKeyIdentifier", "Subject key identifier"},
+	{"2.5.29.15", "id-ce-keyUsage", "Key usage"},
+	{"2.5.29.17", "id-ce-subjectAltName", "Subject alternative name"},
+	{"2.5.29.18", "id-ce-issuerAltName", "Issuer alternative name"},
+	{"2.5.29.19", "id-ce-basicConstraints", "Basic constraints"},
+	{"2.5.29.20", "id-ce-cRLNumber", "CRL number"},
+	{"2.5.29.21", "id-ce-cRLReasons", "CRL reason code"},
+	{"2.5.29.30", "id-ce-nameConstraints", "Name constraints"},
+	{"2.5.29.31", "id-ce-cRLDistributionPoints", "CRL distribution points"},
+	{"2.5.29.32", "id-ce-certificatePolicies", "Certificate policies"},
+	{"2.5.29.32.0", "anyPolicy", "Any policy"},
+	{"2.5.29.35", "id-ce-authorityKeyIdentifier", "Authority key identifier"},
+	{"2.5.29.37", "id-ce-extKeyUsage", "Extended key usage"},
+
+	// PKIX (RFC 5280)
+	{"1.3.6.1.5.5.7", "id-pkix", "Public-Key Infrastructure using X.509"},
+	{"1.3.6.1.5.5.7.1", "id-pe", "PKIX private extensions"},
+	{"1.3.6.1.5.5.7.1.1", "id-pe-authorityInfoAccess", "Authority information access"},
+	{"1.3.6.1.5.5.7.1.11", "id-pe-subjectInfoAccess", "Subject information access"},
+	{"1.3.6.1.5.5.7.2", "id-qt", "PKIX policy qualifier types"},
+	{"1.3.6.1.5.5.7.2.1", "id-qt-cps", "CPS pointer qualifier"},
+	{"1.3.6.1.5.5.7.2.2", "id-qt-unotice", "User notice qualifier"},
+	{"1.3.6.1.5.5.7.3", "id-kp", "PKIX extended key purposes"},
+	{"1.3.6.1.5.5.7.3.1", "id-kp-serverAuth", "TLS web server authentication"},
+	{"1.3.6.1.5.5.7.3.2", "id-kp-clientAuth", "TLS web client authentication"},
+	{"1.3.6.1.5.5.7.3.3", "id-kp-codeSigning", "Code signing"},
+	{"1.3.6.1.5.5.7.3.4", "id-kp-emailProtection", "Email protection"},
+	{"1.3.6.1.5.5.7.3.8", "id-kp-timeStamping", "Time stamping"},
+	{"1.3.6.1.5.5.7.3.9", "id-kp-OCSPSigning", "OCSP signing"},
+	{"1.3.6.1.5.5.7.48", "id-ad", "PKIX access descriptors"},
+	{"1.3.6.1.5.5.7.48.1", "id-ad-ocsp", "OCSP"},
+	{"1.3.6.1.5.5.7.48.2", "id-ad-caIssuers", "CA issuers"},
+
+	// algorithms (RFC 3279, RFC 4055, RFC 5758, RFC 8410)
+	{"1.2.840.113549.1.1.1", "rsaEncryption", "RSA encryption"},
+	{"1.2.840.113549.1.1.5", "sha1WithRSAEncryption", "SHA-1 with RSA encryption"},
+	{"1.2.840.113549.1.1.10", "id-RSASSA-PSS", "RSASSA-PSS"},
+	{"1.2.840.113549.1.1.11", "sha256WithRSAEncryption", "SHA-256 with RSA encryption"},
+	{"1.2.840.113549.1.1.12", "sha384WithRSAEncryption", "SHA-384 with RSA encryption"},
+	{"1.2.840.113549.1.1.13", "sha512WithRSAEncryption", "SHA-512 with RSA encryption"},
+	{"1.2.840.10045.2.1", "id-ecPublicKey", "Elliptic curve public key"},
+	{"1.2.840.10045.3.1.7", "prime256v1", "NIST P-256 curve"},
+	{"1.3.132.0.34", "secp384r1", "NIST P-384 curve"},
+	{"1.3.132.0.35", "secp521r1", "NIST P-521 curve"},
+	{"1.2.840.10045.4.3.2", "ecdsa-with-SHA256", "ECDSA with SHA-256"},
+	{"1.2.840.10045.4.3.3", "ecdsa-with-SHA384", "ECDSA with SHA-384"},
+	{"1.2.840.10045.4.3.4", "ecdsa-with-SHA512", "ECDSA with SHA-512"},
+	{"1.3.101.112", "id-Ed25519", "Ed25519"},
+	{"1.3.14.3.2.26", "id-sha1", "SHA-1"},
+	{"2.16.840.1.101.3.4.2.1", "id-sha256", "SHA-256"},
+	{"2.16.840.1.101.3.4.2.2", "id-sha384", "SHA-384"},
+	{"2.16.840.1.101.3.4.2.3", "id-sha512", "SHA-512"},
+
+	// PKCS #7 and #9 (RFC 2315, RFC 2985)
+	{"1.2.840.113549.1.7.1", "id-data", "PKCS #7 data"},
+	{"1.2.840.113549.1.7.2", "id-signedData", "PKCS #7 signed data"},
+	{"1.2.840.113549.1.9.3", "id-contentType", "Content type"},
+	{"1.2.840.113549.1.9.4", "id-messageDigest", "Message digest"},
+	{"1.2.840.113549.1.9.5", "id-signingTime", "Signing time"},
+
+	// SNMP (RFC 1155, RFC 1213, RFC 3418)
+	{"1.3.6.1", "internet", "Internet"},
+	{"1.3.6.1.2.1", "mib-2", "MIB-2"},
+	{"1.3.6.1.2.1.1", "system", "MIB-2 system group"},
+	{"1.3.6.1.2.1.1.1", "sysDescr", "System description"},
+	{"1.3.6.1.2.1.1.2", "sysObjectID", "System object identifier"},
+	{"1.3.6.1.2.1.1.3", "sysUpTime", "System up time"},
+	{"1.3.6.1.2.1.1.4", "sysContact", "System contact"},
+	{"1.3.6.1.2.1.1.5", "sysName", "System name"},
+	{"1.3.6.1.2.1.1.6", "sysLocation", "System location"},
+	{"1.3.6.1.2.1.1.7", "sysServices", "System services"},
+	{"1.3.6.1.2.1.2", "interfaces", "MIB-2 interfaces group"},
+	{"1.3.6.1.2.1.2.1", "ifNumber", "Number of interfaces"},
+	{"1.3.6.1.2.1.2.2", "ifTable", "Interface table"},
+	{"1.3.6.1.2.1.4", "ip", "MIB-2 IP group"},
+	{"1.3.6.1.2.1.5", "icmp", "MIB-2 ICMP group"},
+	{"1.3.6.1.2.1.6", "tcp", "MIB-2 TCP group"},
+	{"1.3.6.1.2.1.7", "udp", "MIB-2 UDP group"},
+	{"1.3.6.1.2.1.11", "snmp", "MIB-2 SNMP group"},
+	{"1.3.6.1.2.1.31", "ifMIB", "Interfaces group MIB"},
+	{"1.3.6.1.4.1", "enterprises", "Private enterprises"},
+	{"1.3.6.1.6.3.1.1.4.1", "snmpTrapOID", "SNMP trap object identifier"},
+
+	// Kerberos (RFC 1964, RFC 4556, RFC 4178)
+	{"1.2.840.113554.1.2.2", "gss-krb5", "Kerberos 5 GSS-API mechanism"},
+	{"1.2.840.48018.1.2.2", "gss-ms-krb5", "Microsoft Kerberos 5 GSS-API mechanism"},
+	{"1.3.6.1.5.2.2", "id-pkinit-san", "Kerberos principal name"},
+	{"1.3.6.1.5.2.3.1", "id-pkinit-authData", "PKINIT authentication data"},
+	{"1.3.6.1.5.2.3.2", "id-pkinit-DHKeyData", "PKINIT Diffie-Hellman key data"},
+	{"1.3.6.1.5.2.3.3", "id-pkinit-rkeyData", "PKINIT reply key data"},
+	{"1.3.6.1.5.2.3.4", "id-pkinit-KPClientAuth", "PKINIT client authentication"},
+	{"1.3.6.1.5.2.3.5", "id-pkinit-KPKdc", "PKINIT KDC"},
+	{"1.3.6.1.5.5.2", "id-spnego", "SPNEGO"},
+
+	// IEC 61850-8-1 (ISO 9506 MMS, ISO 8650 ACSE)
+	{"1.0.9506.2.1", "mms-abstract-syntax-version1", "MMS abstract syntax"},
+	{"1.0.9506.2.3", "mms-annex-version1", "MMS application context"},
+	{"2.2.1.0.1", "acse-as-id", "ACSE abstract syntax"},
+	{"2.1.1", "basic-encoding", "ASN.1 basic encoding rules"},
+}
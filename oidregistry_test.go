@@ -0,0 +1,48 @@
+package asn1_test
+
+import (
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestOidRegistry(t *testing.T) {
+	var tests = []struct {
+		oid  asn1.ObjectIdentifier
+		name string
+	}{
+		{oid: asn1.NewObjectIdentifier(2, 5, 29, 19), name: "id-ce-basicConstraints"},
+		{oid: asn1.NewObjectIdentifier(1, 2, 840, 113549, 1, 1, 11), name: "sha256WithRSAEncryption"},
+		{oid: asn1.NewObjectIdentifier(1, 3, 6, 1, 2, 1, 1, 5), name: "sysName"},
+		{oid: asn1.NewObjectIdentifier(1, 2, 840, 113554, 1, 2, 2), name: "gss-krb5"},
+		{oid: asn1.NewObjectIdentifier(1, 0, 9506, 2, 3), name: "mms-annex-version1"},
+	}
+
+	for i, tt := range tests {
+		info, ok := asn1.LookupOid(tt.oid)
+		if !ok || info.Name != tt.name || info.Description == "" {
+			t.Errorf("%d. %s: unexpected registration: %+v", i, tt.oid, info)
+		}
+
+		if oid, ok := asn1.DefaultOidRegistry.LookupName(tt.name); !ok || oid != tt.oid {
+			t.Errorf("%d. %s: name lookup mismatch: exp=%s got=%s", i, tt.name, tt.oid, oid)
+		}
+	}
+
+	r := asn1.NewOidRegistry()
+
+	oid := asn1.NewObjectIdentifier(1, 3, 6, 1, 4, 1, 99999, 1)
+	if name := r.Name(oid); name != "1.3.6.1.4.1.99999.1" {
+		t.Errorf("expected dotted notation for unregistered oid, got %s", name)
+	}
+
+	r.Register(oid, asn1.OidInfo{Name: "example", Description: "Example"})
+
+	if name := r.Name(oid); name != "example" {
+		t.Errorf("name mismatch: exp=example got=%s", name)
+	}
+
+	if _, ok := asn1.LookupOid(oid); ok {
+		t.Errorf("registration leaked into the default registry")
+	}
+}
@@ -0,0 +1,124 @@
+package asn1
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// fieldOptions contains the tagging information of a struct field, as
+// parsed from its `asn1:"..."` struct tag.
+//
+// The following options are supported:
+//
+//	tag:N         use the (context specific) tag number N
+//	application   the tag is of the APPLICATION class
+//	private       the tag is of the PRIVATE class
+//	universal     the tag is of the UNIVERSAL class
+//	explicit      the tag is EXPLICIT
+//	implicit      the tag is IMPLICIT (default when a tag is given)
+//	optional      the field is OPTIONAL, nil pointers, interfaces, slices
+//	              and maps and the zero RawValue are omitted. Other values,
+//	              like a zero Integer or BoolFalse, are encoded; use a
+//	              pointer field to omit them
+//	default:V     the field has a DEFAULT value V (integers and booleans)
+//	set           the struct or slice is a SET or SET OF
+//	definedby:F   the interface field is an open type, decoded into the type
+//	              registered for the OBJECT IDENTIFIER in the preceding field F
+//	extensions    the []RawValue field holds the unknown components, see
+//	              KeepExtensions
+//	name:N        the component is named N in the schema, error paths use
+//	              N instead of the Go field name
+//	-             the field is ignored
+type fieldOptions struct {
+	tag          *ASNTag
+	explicit     bool
+	optional     bool
+	set          bool
+	ignore       bool
+	defaultValue *string
+	definedBy    string
+	extensions   bool
+	name         string
+}
+
+// parseFieldOptions parses the asn1 struct tag of a field.
+func parseFieldOptions(s string) (*fieldOptions, error) {
+	opts := &fieldOptions{}
+	if s == "" {
+		return opts, nil
+	}
+
+	if s == "-" {
+		opts.ignore = true
+		return opts, nil
+	}
+
+	class := ClassContextSpecific
+	classSet := false
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+
+		switch {
+		case part == "":
+		case strings.HasPrefix(part, "tag:"):
+			v, err := strconv.ParseUint(part[4:], 10, intBits)
+			if err != nil {
+				return nil, syntaxError("invalid tag: %s", part[4:])
+			}
+
+			opts.tag = &ASNTag{Value: ASNValue(v)}
+		case strings.HasPrefix(part, "definedby:"):
+			opts.definedBy = part[10:]
+		case strings.HasPrefix(part, "name:"):
+			opts.name = part[5:]
+		case strings.HasPrefix(part, "default:"):
+			v := part[8:]
+			opts.defaultValue = &v
+		case part == "application":
+			class, classSet = ClassApplication, true
+		case part == "private":
+			class, classSet = ClassPrivate, true
+		case part == "universal":
+			class, classSet = ClassUniversal, true
+		case part == "explicit":
+			opts.explicit = true
+		case part == "implicit":
+			opts.explicit = false
+		case part == "optional":
+			opts.optional = true
+		case part == "set":
+			opts.set = true
+		case part == "extensions":
+			opts.extensions = true
+		default:
+			return nil, syntaxError("invalid struct tag option: %s", part)
+		}
+	}
+
+	if opts.tag == nil {
+		if classSet {
+			return nil, syntaxError("class given without tag number")
+		}
+
+		if opts.explicit {
+			return nil, syntaxError("explicit given without tag number")
+		}
+
+		return opts, nil
+	}
+
+	opts.tag.Class = class
+	return opts, nil
+}
+
+// fieldName returns the name of field in error paths, the name option when
+// given or the Go field name.
+func fieldName(field reflect.StructField, opts *fieldOptions) string {
+	if opts.name != "" {
+		return opts.name
+	}
+
+	return field.Name
+}
@@ -0,0 +1,237 @@
+package asn1
+
+import (
+	"io"
+)
+
+// ParseBytes parses the BER encoded value at the start of b and returns the
+// octets following it in rest. Unlike DecodeRawValue, the content of the
+// value is not copied but aliases b.
+func ParseBytes(b []byte) (raw RawValue, rest []byte, err error) {
+	return parseBytes(b, 0)
+}
+
+// parseBytes parses the value at the start of b, offset is the offset of b
+// in the decoded input.
+func parseBytes(b []byte, offset int64) (RawValue, []byte, error) {
+	tag, constructed, length, indefinite, n, err := parseHeader(b)
+	if err != nil {
+		return RawValue{}, nil, errorAt(err, offset)
+	}
+
+	if indefinite && !constructed {
+		return RawValue{}, nil, errorAt(kindError(ErrInvalidLength, "primitive node with indefinite length"), offset)
+	}
+
+	raw := RawValue{
+		Tag:          tag,
+		Constructed:  constructed,
+		Indefinite:   indefinite,
+		Offset:       offset,
+		HeaderLength: n,
+	}
+
+	if !indefinite {
+		if uint64(length) > uint64(len(b)-n) {
+			return RawValue{}, nil, errorAt(io.ErrUnexpectedEOF, offset)
+		}
+
+		end := n + int(length)
+		raw.Content = b[n:end:end]
+		return raw, b[end:], nil
+	}
+
+	end, err := indefiniteEnd(b, n, offset)
+	if err != nil {
+		return RawValue{}, nil, err
+	}
+
+	// the content excludes the end-of-contents octets
+	raw.Content = b[n : end-2 : end-2]
+	return raw, b[end:], nil
+}
+
+// parseHeader parses the identifier and length octets at the start of b, n
+// is the number of octets parsed.
+func parseHeader(b []byte) (tag ASNTag, constructed bool, length uint, indefinite bool, n int, err error) {
+	if len(b) == 0 {
+		err = io.EOF
+		return
+	}
+
+	tag.Class = ASNClass(b[0] >> 6)
+	constructed = b[0]&0x20 != 0
+
+	value := uint(b[0] & 0x1f)
+	n = 1
+
+	if value == 0x1f {
+		// Tag is encoded in one or more following bytes
+		value = 0
+
+		for {
+			if n >= len(b) {
+				err = io.ErrUnexpectedEOF
+				return
+			}
+
+			c := b[n]
+			n++
+
+			if uint64(value)&(uint64(0xfe)<<(intBits-8)) != 0 {
+				err = parseError("multi byte tag too big")
+				return
+			}
+
+			value = value<<7 | uint(c&0x7f)
+			if c&0x80 == 0 {
+				break
+			}
+		}
+	}
+
+	tag.Value = ASNValue(value)
+
+	if n >= len(b) {
+		err = io.ErrUnexpectedEOF
+		return
+	}
+
+	c := b[n]
+	n++
+
+	switch {
+	case c&0x80 == 0:
+		length = uint(c)
+	case c == 0x80:
+		indefinite = true
+	case c == 0xff:
+		err = kindError(ErrInvalidLength, "invalid number of length octets: %x", c)
+	default:
+		octets := int(c & 0x7f)
+		if octets > len(b)-n {
+			err = io.ErrUnexpectedEOF
+			return
+		}
+
+		for _, c := range b[n : n+octets] {
+			if uint64(length)&(uint64(0xff)<<(intBits-8)) != 0 {
+				err = kindError(ErrInvalidLength, "multi byte length too big")
+				return
+			}
+
+			length = length<<8 | uint(c)
+		}
+
+		n += octets
+	}
+
+	return
+}
+
+// indefiniteEnd returns the offset just after the end-of-contents octets of
+// the indefinite length value with content starting at offset pos of b,
+// offset is the offset of b in the decoded input.
+func indefiniteEnd(b []byte, pos int, offset int64) (int, error) {
+	for open := 1; open > 0; {
+		tag, constructed, length, indefinite, n, err := parseHeader(b[pos:])
+		if err != nil {
+			return 0, errorAt(unexpectedEOF(err), offset+int64(pos))
+		}
+
+		if indefinite && !constructed {
+			return 0, errorAt(kindError(ErrInvalidLength, "primitive node with indefinite length"), offset+int64(pos))
+		}
+
+		pos += n
+
+		if tag.Class == 0 && tag.Value == 0 && !indefinite && length == 0 {
+			open--
+			continue
+		}
+
+		if indefinite {
+			open++
+			continue
+		}
+
+		if uint64(length) > uint64(len(b)-pos) {
+			return 0, errorAt(io.ErrUnexpectedEOF, offset+int64(pos-n))
+		}
+
+		pos += int(length)
+	}
+
+	return pos, nil
+}
+
+// ChildIterator iterates over the values contained in a constructed value,
+// see RawValue.Children.
+type ChildIterator struct {
+	rest   []byte
+	offset int64
+	value  RawValue
+	err    error
+}
+
+// Children returns an iterator over the values contained in the content of
+// raw. The values are parsed while iterating and their content aliases the
+// content of raw, so walking a value does not allocate. The offsets of the
+// values follow from the offset of raw.
+//
+//	it := raw.Children()
+//	for it.Next() {
+//		child := it.Value()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+func (raw *RawValue) Children() ChildIterator {
+	return ChildIterator{
+		rest:   raw.Content,
+		offset: raw.ContentOffset(),
+	}
+}
+
+// ChildValues returns all values contained in the content of raw, see
+// Children.
+func (raw *RawValue) ChildValues() ([]*RawValue, error) {
+	values := []*RawValue{}
+
+	it := raw.Children()
+	for it.Next() {
+		child := it.Value()
+		values = append(values, &child)
+	}
+
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// Next parses the next value, it returns false when all values have been
+// parsed or an error occurred.
+func (it *ChildIterator) Next() bool {
+	if it.err != nil || len(it.rest) == 0 {
+		return false
+	}
+
+	n := len(it.rest)
+
+	it.value, it.rest, it.err = parseBytes(it.rest, it.offset)
+	it.offset += int64(n - len(it.rest))
+	return it.err == nil
+}
+
+// Value returns the value parsed by the last call to Next.
+func (it *ChildIterator) Value() RawValue {
+	return it.value
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ChildIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,311 @@
+package asn1_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestParseBytes(t *testing.T) {
+	var tests = []struct {
+		in      string
+		content string
+		rest    string
+	}{
+		{in: "020105", content: "05"},
+		{in: "020105 0500", content: "05", rest: "0500"},
+		{in: "3003020105", content: "020105"},
+		{in: "3080 020105 0000 ff", content: "020105", rest: "ff"},
+		{in: "3080 3080 0500 0000 0000", content: "308005000000"},
+		{in: "1f8101 01 ff", content: "ff"},
+		{in: "04 8200 02 abcd", content: "abcd"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		raw, rest, err := asn1.ParseBytes(data)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if hex.EncodeToString(raw.Content) != tt.content {
+			t.Errorf("%d. content mismatch: exp=%s got=%x", i, tt.content, raw.Content)
+		}
+
+		if hex.EncodeToString(rest) != tt.rest {
+			t.Errorf("%d. rest mismatch: exp=%s got=%x", i, tt.rest, rest)
+		}
+
+		if raw.Offset != 0 || raw.ContentOffset() != int64(len(data)-len(rest)-len(raw.Content)) && !raw.Indefinite {
+			t.Errorf("%d. unexpected offsets: offset=%d header=%d", i, raw.Offset, raw.HeaderLength)
+		}
+
+		// the content aliases the input
+		if len(raw.Content) > 0 {
+			raw.Content[0] ^= 0xff
+			if hex.EncodeToString(raw.Content) == tt.content {
+				t.Errorf("%d. content does not alias the input", i)
+			}
+		}
+	}
+}
+
+func TestParseBytes_Errors(t *testing.T) {
+	var tests = []struct {
+		in  string
+		err error
+	}{
+		{in: "", err: io.EOF},
+		{in: "02", err: io.ErrUnexpectedEOF},
+		{in: "0202ff", err: io.ErrUnexpectedEOF},
+		{in: "1f81", err: io.ErrUnexpectedEOF},
+		{in: "04847fffffff", err: io.ErrUnexpectedEOF},
+		{in: "3080020105", err: io.ErrUnexpectedEOF},
+		{in: "0480"},
+		{in: "04ff"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(tt.in)
+
+		_, _, err := asn1.ParseBytes(data)
+		if err == nil {
+			t.Errorf("%d. %s: expected error", i, tt.in)
+		} else if tt.err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("%d. %s: expected %v, got %v", i, tt.in, tt.err, err)
+		}
+	}
+}
+
+// walk counts all values in raw and the values nested in it.
+func walk(raw asn1.RawValue) (int, error) {
+	n := 1
+	if !raw.Constructed {
+		return n, nil
+	}
+
+	it := raw.Children()
+	for it.Next() {
+		m, err := walk(it.Value())
+		if err != nil {
+			return 0, err
+		}
+
+		n += m
+	}
+
+	return n, it.Err()
+}
+
+func TestRawValue_Children(t *testing.T) {
+	data, _ := hex.DecodeString(stripSpaces("3080 3006 020101 020102 3080 0500 0000 0403616263 0000"))
+
+	raw, _, err := asn1.ParseBytes(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tags := []asn1.ASNTag{}
+	offsets := []int64{}
+
+	it := raw.Children()
+	for it.Next() {
+		tags = append(tags, it.Value().Tag)
+		offsets = append(offsets, it.Value().Offset)
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(tags) != 3 || tags[0].Value != asn1.TagSequence || tags[1].Value != asn1.TagSequence || tags[2].Value != asn1.TagOctetString {
+		t.Errorf("unexpected children: %v", tags)
+	}
+
+	if len(offsets) != 3 || offsets[0] != 2 || offsets[1] != 10 || offsets[2] != 16 {
+		t.Errorf("unexpected offsets: %v", offsets)
+	}
+
+	if n, err := walk(raw); err != nil || n != 7 {
+		t.Errorf("expected 7 values, got %d (%v)", n, err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		walk(raw)
+	})
+
+	if allocs != 0 {
+		t.Errorf("walking allocated %.0f times", allocs)
+	}
+
+	// a truncated child stops the iteration
+	truncated := asn1.RawValue{Tag: raw.Tag, Constructed: true, Content: []byte{0x02, 0x01, 0x01, 0x02, 0x02}}
+
+	it = truncated.Children()
+	for it.Next() {
+	}
+
+	if !errors.Is(it.Err(), asn1.ErrTruncated) {
+		t.Errorf("expected %v, got %v", asn1.ErrTruncated, it.Err())
+	}
+}
+
+type testValidity struct {
+	NotBefore int8
+}
+
+type testTBS struct {
+	Serial   int
+	Validity testValidity
+}
+
+type testCertificate struct {
+	TBS testTBS
+}
+
+type testNamedValidity struct {
+	NotBefore int8 `asn1:"name:notBefore"`
+}
+
+type testNamedTBS struct {
+	Serial   int               `asn1:"name:serialNumber"`
+	Validity testNamedValidity `asn1:"name:validity"`
+}
+
+type testNamedCertificate struct {
+	TBS testNamedTBS `asn1:"name:tbsCertificate"`
+}
+
+type testList struct {
+	Values []int8
+}
+
+type testExplicit struct {
+	A bool `asn1:"explicit,tag:0"`
+}
+
+type testInner struct {
+	Data []byte
+}
+
+type testOuter struct {
+	A testInner
+}
+
+func TestParseError(t *testing.T) {
+	var tests = []struct {
+		in   string
+		v    interface{}
+		kind error
+		err  string
+	}{
+		{
+			in:   "300b 3009 020101 3004 020200ff",
+			v:    &testCertificate{},
+			kind: asn1.ErrConstraint,
+			err:  "offset 0x9: testCertificate.TBS.Validity.NotBefore: integer 255 overflows int8",
+		},
+		{
+			in:   "300b 3009 020101 3004 020200ff",
+			v:    &testNamedCertificate{},
+			kind: asn1.ErrConstraint,
+			err:  "offset 0x9: testNamedCertificate.tbsCertificate.validity.notBefore: integer 255 overflows int8",
+		},
+		{
+			in:  "3000",
+			v:   &testNamedCertificate{},
+			err: "offset 0x0: testNamedCertificate: missing value for field tbsCertificate",
+		},
+		{
+			in:   "3009 3007 020101 020200ff",
+			v:    &testList{},
+			kind: asn1.ErrConstraint,
+			err:  "offset 0x7: testList.Values[1]: integer 255 overflows int8",
+		},
+		{
+			in:   "3005 a003 020101",
+			v:    &testExplicit{},
+			kind: asn1.ErrTagMismatch,
+			err:  "offset 0x4: testExplicit.A: unexpected tag [UNIVERSAL 2] for type bool",
+		},
+		{
+			in:   "3006 3004 0480 0000",
+			v:    &testOuter{},
+			kind: asn1.ErrInvalidLength,
+			err:  "offset 0x4: testOuter.A: primitive node with indefinite length",
+		},
+		{
+			in:   "3005 020101",
+			v:    &testCertificate{},
+			kind: asn1.ErrTruncated,
+			err:  "offset 0x0: truncated input: unexpected EOF",
+		},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		err := asn1.Unmarshal(data, tt.v)
+
+		var pe *asn1.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%d. expected *ParseError, got %v", i, err)
+			continue
+		}
+
+		if tt.kind != nil && !errors.Is(err, tt.kind) {
+			t.Errorf("%d. expected error of kind %v, got %v", i, tt.kind, pe.Err)
+		}
+
+		if err.Error() != tt.err {
+			t.Errorf("%d. error mismatch:\n  exp=%s\n  got=%s", i, tt.err, err)
+		}
+	}
+
+	if err := asn1.Unmarshal([]byte{0x02}, new(int)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected truncated input to match io.ErrUnexpectedEOF, got %v", err)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	type unsupported struct {
+		C chan int
+	}
+
+	_, err := asn1.Marshal(unsupported{C: make(chan int)})
+
+	var se *asn1.SyntaxError
+	if !errors.As(err, &se) || !errors.Is(err, asn1.ErrUnsupportedType) {
+		t.Fatalf("expected *SyntaxError of kind ErrUnsupportedType, got %v", err)
+	}
+
+	if exp := "unsupported.C: unsupported type: chan int"; err.Error() != exp {
+		t.Errorf("error mismatch:\n  exp=%s\n  got=%s", exp, err)
+	}
+}
+
+func TestDecoder_ErrorPosition(t *testing.T) {
+	data, _ := hex.DecodeString(stripSpaces("3080 a003 0480 00 0000"))
+
+	d := asn1.NewDecoder(bytes.NewReader(data))
+
+	var err error
+	for err == nil {
+		_, err = d.Token()
+	}
+
+	pe, ok := err.(*asn1.ParseError)
+	if !ok {
+		t.Fatalf("expected *ParseError, got %v", err)
+	}
+
+	if pe.Offset != 4 || len(pe.Path) != 2 || pe.Path[1] != "[CONTEXT 0]" {
+		t.Errorf("unexpected position: %s", pe)
+	}
+}
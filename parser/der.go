@@ -0,0 +1,279 @@
+package asn1parser
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	asn1 "github.com/dutchsec/asn1"
+)
+
+// ToDER converts raw, a BER encoded value of the type typeName, into its
+// canonical DER form. In addition to asn1.ToDER, components of SEQUENCE and
+// SET values that equal their DEFAULT value are omitted.
+func (d *ASNDefinition) ToDER(typeName string, raw *asn1.RawValue) (*asn1.RawValue, error) {
+	t := d.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("der: unknown type %s", typeName)
+	}
+
+	der, err := asn1.ToDER(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.omitDefaults(t, der, false)
+}
+
+// omitDefaults removes the components that equal their DEFAULT value from
+// the DER encoded value raw of type t. Retagged is set when the outermost
+// tag of raw has been replaced by an implicit tag.
+func (d *ASNDefinition) omitDefaults(t ASNType, raw *asn1.RawValue, retagged bool) (*asn1.RawValue, error) {
+	return d.rewrite(raw, d.TypeTags(t), retagged, func(raw *asn1.RawValue) (*asn1.RawValue, error) {
+		return d.omitBuiltinDefaults(d.Resolve(t), raw)
+	})
+}
+
+// omitItemDefaults omits the DEFAULT values of the value of the i-th item of
+// a SEQUENCE, SET or CHOICE, taking the tag of the item into account.
+func (d *ASNDefinition) omitItemDefaults(items []ASNItem, i int, raw *asn1.RawValue) (*asn1.RawValue, error) {
+	tag, ok := d.ItemTag(items, i)
+	if !ok {
+		return d.omitDefaults(items[i].Type, raw, false)
+	}
+
+	return d.rewrite(raw, []TypeTag{tag}, false, func(inner *asn1.RawValue) (*asn1.RawValue, error) {
+		return d.omitDefaults(items[i].Type, inner, !tag.Explicit)
+	})
+}
+
+// rewrite applies f to the value within raw that has been tagged with tags.
+// f has to keep the tag of the value. Values with unexpected tags are
+// returned as is.
+func (d *ASNDefinition) rewrite(raw *asn1.RawValue, tags []TypeTag, retagged bool, f func(*asn1.RawValue) (*asn1.RawValue, error)) (*asn1.RawValue, error) {
+	if len(tags) == 0 {
+		return f(raw)
+	}
+
+	if !retagged && raw.Tag != tags[0].Tag {
+		return raw, nil
+	}
+
+	if !tags[0].Explicit {
+		return d.rewrite(raw, tags[1:], true, f)
+	}
+
+	inner, err := unwrapExplicit(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	inner, err = d.rewrite(inner, tags[1:], false, f)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := inner.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &asn1.RawValue{
+		Tag:         raw.Tag,
+		Constructed: true,
+		Content:     content,
+	}, nil
+}
+
+// omitBuiltinDefaults omits the DEFAULT values of raw, a value of the
+// builtin type t.
+func (d *ASNDefinition) omitBuiltinDefaults(t ASNType, raw *asn1.RawValue) (*asn1.RawValue, error) {
+	var items []ASNItem
+	set := false
+
+	switch v := t.(type) {
+	case *ASNChoice:
+		// the value of a choice is the value of the alternative
+		if i, ok := d.MatchAlternative(v.Items, raw.Tag); ok {
+			return d.omitItemDefaults(v.Items, i, raw)
+		}
+
+		return raw, nil
+	case *ASNSequence:
+		items = v.Items
+
+		if v.Of != "" {
+			return d.omitElementDefaults(d.ElementType(v), raw)
+		}
+	case *ASNSet:
+		items = v.Items
+		set = true
+	default:
+		return raw, nil
+	}
+
+	if !raw.Constructed {
+		return raw, nil
+	}
+
+	children, err := raw.ChildValues()
+	if err != nil {
+		return nil, err
+	}
+
+	present, err := d.MatchItems(items, children, set)
+	if err != nil {
+		// not a value of the type, leave the value as is
+		return raw, nil
+	}
+
+	index := map[*asn1.RawValue]int{}
+	for i, child := range present {
+		index[child] = i
+	}
+
+	b := asn1.NewBuilder(raw.Tag)
+
+	for _, child := range children {
+		i, ok := index[child]
+		if !ok {
+			// an unknown extension addition
+			b.Add(child)
+			continue
+		}
+
+		if isDefault, err := d.isDefault(items, i, child); err != nil {
+			return nil, err
+		} else if isDefault {
+			continue
+		}
+
+		child, err = d.omitItemDefaults(items, i, child)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Add(child)
+	}
+
+	return b.RawValue()
+}
+
+// omitElementDefaults omits the DEFAULT values of the components of raw, a
+// SEQUENCE OF value with components of type t.
+func (d *ASNDefinition) omitElementDefaults(t ASNType, raw *asn1.RawValue) (*asn1.RawValue, error) {
+	children, err := raw.ChildValues()
+	if err != nil {
+		return nil, err
+	}
+
+	b := asn1.NewBuilder(raw.Tag)
+
+	for _, child := range children {
+		child, err = d.omitDefaults(t, child, false)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Add(child)
+	}
+
+	return b.RawValue()
+}
+
+// itemTag returns the tag given to the item in its group, eg. [1] or
+// [APPLICATION 2].
+func itemTag(item ASNItem) (asn1.ASNTag, bool) {
+	if item.Position == "" {
+		return ASNTagNotSet, false
+	}
+
+	n, err := strconv.Atoi(item.Position)
+	if err != nil {
+		return ASNTagNotSet, false
+	}
+
+	class := asn1.ClassContextSpecific
+	if item.Application {
+		class = asn1.ClassApplication
+	}
+
+	return asn1.Tag(class, asn1.ASNValue(n)), true
+}
+
+// isDefault returns true when the DER encoded value raw equals the DEFAULT
+// value of the i-th item of a SEQUENCE or SET.
+func (d *ASNDefinition) isDefault(items []ASNItem, i int, raw *asn1.RawValue) (bool, error) {
+	item := items[i]
+	if item.Default == nil {
+		return false, nil
+	}
+
+	tags := d.TypeTags(item.Type)
+	if tag, ok := d.ItemTag(items, i); ok {
+		tags = append([]TypeTag{tag}, tags...)
+	}
+
+	raw, _, err := Untag(raw, tags, false)
+	if err != nil {
+		return false, nil
+	}
+
+	t := d.Resolve(item.Type)
+
+	var enum *ASNEnum
+	switch v := t.(type) {
+	case *ASNInteger:
+		enum = &v.ASNEnum
+	case *ASNEnumerated:
+		enum = &v.ASNEnum
+	}
+
+	var content []byte
+
+	switch v := item.Default.(type) {
+	case bool:
+		content = []byte{0x00}
+		if v {
+			content[0] = 0xff
+		}
+	case int64:
+		rv, _ := asn1.NewInteger(v).MarshalRawValue()
+		content = rv.Content
+	case string:
+		if enum == nil {
+			return false, nil
+		}
+
+		value, ok := enum.Values[v]
+		if !ok {
+			return false, nil
+		}
+
+		n, err := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+		if err != nil {
+			return false, nil
+		}
+
+		rv, _ := asn1.NewInteger(n).MarshalRawValue()
+		content = rv.Content
+	default:
+		return false, nil
+	}
+
+	return !raw.Constructed && bytes.Equal(raw.Content, content), nil
+}
+
+// unwrapExplicit returns the value contained in the explicitly tagged raw.
+func unwrapExplicit(raw *asn1.RawValue) (*asn1.RawValue, error) {
+	children, err := raw.ChildValues()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(children) != 1 {
+		return nil, fmt.Errorf("der: explicitly tagged value %s contains %d values", raw.Tag, len(children))
+	}
+
+	return children[0], nil
+}
@@ -0,0 +1,65 @@
+package asn1parser_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+func TestDefinition_ToDER(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(`
+Test DEFINITIONS ::=
+BEGIN
+
+Record ::= SEQUENCE {
+	version [0] INTEGER DEFAULT 1,
+	critical BOOLEAN DEFAULT FALSE,
+	level ENUMERATED { low(0), high(1) } DEFAULT low,
+	name OCTET STRING
+}
+
+END
+`)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var tests = []struct {
+		in  string
+		out string
+	}{
+		{in: "3080a003020101010100 0a0100 24800401610000 0000", out: "3003040161"},
+		{in: "300ea0030201020101010a0101040161", out: "300ea0030201020101ff0a0101040161"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(strings.Replace(tt.in, " ", "", -1))
+
+		raw, err := asn1.DecodeRawValue(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		der, err := def.ToDER("Record", raw)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		out, err := der.Encode()
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if hex.EncodeToString(out) != tt.out {
+			t.Errorf("%d. output mismatch: exp=%s got=%x", i, tt.out, out)
+		}
+	}
+
+	if _, err := def.ToDER("Unknown", &asn1.RawValue{}); err == nil {
+		t.Errorf("expected error for unknown type")
+	}
+}
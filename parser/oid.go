@@ -0,0 +1,173 @@
+package asn1parser
+
+import (
+	"fmt"
+	"strings"
+
+	asn1 "github.com/dutchsec/asn1"
+)
+
+// rootArcs are the arcs of the top of the object identifier tree that can be
+// used by name without number (X.660 A.2).
+var rootArcs = map[string]string{
+	"itu-t":           "0",
+	"ccitt":           "0",
+	"iso":             "1",
+	"joint-iso-itu-t": "2",
+	"joint-iso-ccitt": "2",
+}
+
+// secondArcs are the arcs below the root arcs that can be used by name
+// without number (X.660 A.3 and A.4).
+var secondArcs = map[string]map[string]string{
+	"0": {
+		"recommendation":          "0",
+		"question":                "1",
+		"administration":          "2",
+		"network-operator":        "3",
+		"identified-organization": "4",
+	},
+	"1": {
+		"standard":                "0",
+		"registration-authority":  "1",
+		"member-body":             "2",
+		"identified-organization": "3",
+	},
+}
+
+// ResolveOids returns the values of the OBJECT IDENTIFIER value assignments
+// of the module by name. Values that are not assigned in the module, eg.
+// imported values, are looked up by name in registry, which may be nil.
+func (d *ASNDefinition) ResolveOids(registry *asn1.OidRegistry) (map[string]asn1.ObjectIdentifier, error) {
+	r := newOidResolver(d, registry)
+
+	for _, assignment := range d.Oids {
+		if _, err := r.resolve(assignment.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.values, nil
+}
+
+// RegisterOids adds the OBJECT IDENTIFIER value assignments of the module to
+// registry, with the name of the assignment as symbolic name. Values that are
+// not assigned in the module are looked up by name in registry. All values
+// that can be resolved are registered, the error reports the first value that
+// cannot be resolved.
+func (d *ASNDefinition) RegisterOids(registry *asn1.OidRegistry) error {
+	r := newOidResolver(d, registry)
+
+	var first error
+	for _, assignment := range d.Oids {
+		oid, err := r.resolve(assignment.Name)
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+
+			continue
+		}
+
+		registry.Register(oid, asn1.OidInfo{Name: assignment.Name})
+	}
+
+	return first
+}
+
+// oidResolver resolves the OBJECT IDENTIFIER values of a module.
+type oidResolver struct {
+	assignments map[string]*ASNOidAssignment
+	registry    *asn1.OidRegistry
+
+	values map[string]asn1.ObjectIdentifier
+	// pending contains the values being resolved, to detect cycles
+	pending map[string]bool
+}
+
+func newOidResolver(d *ASNDefinition, registry *asn1.OidRegistry) *oidResolver {
+	r := &oidResolver{
+		assignments: map[string]*ASNOidAssignment{},
+		registry:    registry,
+		values:      map[string]asn1.ObjectIdentifier{},
+		pending:     map[string]bool{},
+	}
+
+	for i := range d.Oids {
+		r.assignments[d.Oids[i].Name] = &d.Oids[i]
+	}
+
+	return r
+}
+
+// resolve returns the value of the OBJECT IDENTIFIER name.
+func (r *oidResolver) resolve(name string) (asn1.ObjectIdentifier, error) {
+	if oid, ok := r.values[name]; ok {
+		return oid, nil
+	}
+
+	assignment, ok := r.assignments[name]
+	if !ok {
+		if r.registry != nil {
+			if oid, ok := r.registry.LookupName(name); ok {
+				return oid, nil
+			}
+		}
+
+		return asn1.ObjectIdentifier{}, fmt.Errorf("parser: undefined OBJECT IDENTIFIER value %q", name)
+	}
+
+	if r.pending[name] {
+		return asn1.ObjectIdentifier{}, fmt.Errorf("parser: OBJECT IDENTIFIER value %q refers to itself", name)
+	}
+
+	r.pending[name] = true
+	defer delete(r.pending, name)
+
+	arcs := []string{}
+	for i, component := range assignment.Components {
+		_, assigned := r.assignments[component.Name]
+
+		switch {
+		case component.Number != "":
+			arcs = append(arcs, component.Number)
+		case i == 0 && !assigned && rootArcs[component.Name] != "":
+			arcs = append(arcs, rootArcs[component.Name])
+		case i == 0:
+			// a reference to another value
+			oid, err := r.resolve(component.Name)
+			if err != nil {
+				return asn1.ObjectIdentifier{}, err
+			}
+
+			arcs = append(arcs, oid.String())
+		case i == 1 && secondArcs[arcs[0]][component.Name] != "":
+			arcs = append(arcs, secondArcs[arcs[0]][component.Name])
+		default:
+			return asn1.ObjectIdentifier{}, fmt.Errorf("parser: component %q of OBJECT IDENTIFIER value %q has no number", component.Name, name)
+		}
+	}
+
+	oid, err := asn1.ParseObjectIdentifier(strings.Join(arcs, "."))
+	if err != nil {
+		return asn1.ObjectIdentifier{}, fmt.Errorf("parser: invalid OBJECT IDENTIFIER value %q: %s", name, err)
+	}
+
+	r.values[name] = oid
+	return oid, nil
+}
+
+// isNumber returns true when lit consists of decimal digits.
+func isNumber(lit string) bool {
+	if lit == "" {
+		return false
+	}
+
+	for _, ch := range lit {
+		if !isDigit(ch) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,74 @@
+package asn1parser_test
+
+import (
+	"strings"
+	"testing"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+func TestDefinition_RegisterOids(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(`
+Test DEFINITIONS ::=
+BEGIN
+
+id-example OBJECT IDENTIFIER ::= { iso(1) identified-organization(3) dod(6) internet(1) private(4) enterprise(1) 99999 }
+id-example-types OBJECT IDENTIFIER ::= { id-example 1 }
+id-example-pe OBJECT IDENTIFIER ::= { id-pkix 99 }
+id-example-us OBJECT IDENTIFIER ::= { iso member-body us(840) 99999 }
+
+END
+`)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(def.Oids) != 4 || len(def.Oids[0].Components) != 7 || def.Oids[0].Components[1] != (asn1parser.ASNOidComponent{Name: "identified-organization", Number: "3"}) {
+		t.Fatalf("unexpected assignments: %+v", def.Oids)
+	}
+
+	r := asn1.NewOidRegistry()
+	r.Register(asn1.NewObjectIdentifier(1, 3, 6, 1, 5, 5, 7), asn1.OidInfo{Name: "id-pkix"})
+
+	if err := def.RegisterOids(r); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var tests = []struct {
+		name string
+		oid  string
+	}{
+		{name: "id-example", oid: "1.3.6.1.4.1.99999"},
+		{name: "id-example-types", oid: "1.3.6.1.4.1.99999.1"},
+		{name: "id-example-pe", oid: "1.3.6.1.5.5.7.99"},
+		{name: "id-example-us", oid: "1.2.840.99999"},
+	}
+
+	for i, tt := range tests {
+		if oid, ok := r.LookupName(tt.name); !ok || oid.String() != tt.oid {
+			t.Errorf("%d. %s: value mismatch: exp=%s got=%s", i, tt.name, tt.oid, oid)
+		}
+	}
+}
+
+func TestDefinition_ResolveOidsErrors(t *testing.T) {
+	var tests = []string{
+		"id-a OBJECT IDENTIFIER ::= { id-imported 1 }",
+		"id-a OBJECT IDENTIFIER ::= { iso unknown 1 }",
+		"id-a OBJECT IDENTIFIER ::= { id-b 1 }\nid-b OBJECT IDENTIFIER ::= { id-a 1 }",
+		"id-a OBJECT IDENTIFIER ::= { 3 1 }",
+	}
+
+	for i, tt := range tests {
+		def, err := asn1parser.NewParser(strings.NewReader("Test DEFINITIONS ::= BEGIN\n" + tt + "\nEND")).Parse()
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if _, err := def.ResolveOids(nil); err == nil {
+			t.Errorf("%d. %s: expected error", i, tt)
+		}
+	}
+}
@@ -57,6 +57,28 @@ func (p *Parser) Parse() (*ASNDefinition, error) {
 		return nil, fmt.Errorf("parser: found %q, expected DEFINITIONS identifier", lit)
 	}
 
+	// DEFINITIONS AUTOMATIC TAGS ::=
+	if tok, lit := p.scanIgnoreWhitespace(); tok == IMPLICIT || tok == EXPLICIT || (tok == IDENT && lit == "AUTOMATIC") {
+		d.Tagging = lit
+
+		if tok, lit := p.scanIgnoreWhitespace(); tok != IDENT || lit != "TAGS" {
+			return nil, fmt.Errorf("parser: found %q, expected TAGS", lit)
+		}
+	} else {
+		p.unscan()
+	}
+
+	// EXTENSIBILITY IMPLIED
+	if tok, lit := p.scanIgnoreWhitespace(); tok == IDENT && lit == "EXTENSIBILITY" {
+		if tok, lit := p.scanIgnoreWhitespace(); tok != IDENT || lit != "IMPLIED" {
+			return nil, fmt.Errorf("parser: found %q, expected IMPLIED", lit)
+		}
+
+		d.ExtensibilityImplied = true
+	} else {
+		p.unscan()
+	}
+
 	if tok, lit := p.scanIgnoreWhitespace(); tok != ASSIGNMENT_OPERATOR {
 		return nil, fmt.Errorf("parser: found %q, expected ASSIGNMENT_OPERATOR", lit)
 	}
@@ -186,17 +208,27 @@ func (p *Parser) Parse() (*ASNDefinition, error) {
 				return nil, fmt.Errorf("found %q, expected GROUP_OPEN", lit)
 			}
 
+			assignment := ASNOidAssignment{
+				Name: cmmn.name,
+			}
+
 			for {
+				component := ASNOidComponent{}
+
 				if tok, lit := p.scanIgnoreWhitespace(); tok != IDENT {
 					p.unscan()
 					return nil, fmt.Errorf("found %q, expected IDENT", lit)
+				} else if isNumber(lit) {
+					component.Number = lit
 				} else {
+					component.Name = lit
 				}
 
 				if tok, lit := p.scanIgnoreWhitespace(); tok == PARENTHESES_OPEN {
 					if tok, lit = p.scanIgnoreWhitespace(); tok != IDENT {
 						p.unscan()
 					} else {
+						component.Number = lit
 					}
 
 					if tok, lit = p.scanIgnoreWhitespace(); tok != PARENTHESES_CLOSE {
@@ -206,6 +238,8 @@ func (p *Parser) Parse() (*ASNDefinition, error) {
 					p.unscan()
 				}
 
+				assignment.Components = append(assignment.Components, component)
+
 				if tok, _ := p.scanIgnoreWhitespace(); tok == GROUP_CLOSE {
 					break
 				} else {
@@ -213,6 +247,7 @@ func (p *Parser) Parse() (*ASNDefinition, error) {
 				}
 			}
 
+			d.Oids = append(d.Oids, assignment)
 			continue
 		}
 
@@ -302,23 +337,20 @@ func (p *Parser) scanSequence(cmmn ASNCommon) (ASNType, error) {
 		nil,
 	}
 
-	if tok, _ := p.scanIgnoreWhitespace(); tok != SIZE {
-		p.unscan()
-	} else if tok, lit := p.scanIgnoreWhitespace(); tok == PARENTHESES_OPEN {
-		if _, _, err := p.scanRange(); err != nil {
-			return nil, fmt.Errorf("scanSequence: found %q, expected range", lit)
+	if tok, _ := p.scanIgnoreWhitespace(); tok == SIZE {
+		// SEQUENCE SIZE (1..10) OF
+		size, err := p.scanSize()
+		if err != nil {
+			return nil, err
 		}
 
-		if tok, _ := p.scanIgnoreWhitespace(); tok != COMMA {
-			return nil, fmt.Errorf("scanSequence: found %q, expected comma", lit)
-		}
-
-		if tok, _ := p.scanIgnoreWhitespace(); tok != TRIPLE_DOT {
-			return nil, fmt.Errorf("scanSequence: found %q, expected triple dot", lit)
-		}
+		sequence.Size = size
+	} else {
+		// SEQUENCE (SIZE (1..10)) OF
+		p.unscan()
 
-		if tok, lit = p.scanIgnoreWhitespace(); tok != PARENTHESES_CLOSE {
-			return nil, fmt.Errorf("scanSequence: found %q, expected close parentheses", lit)
+		if err := p.scanConstraint(&sequence.ASNCommon); err != nil {
+			return nil, err
 		}
 	}
 
@@ -332,6 +364,8 @@ func (p *Parser) scanSequence(cmmn ASNCommon) (ASNType, error) {
 			return nil, fmt.Errorf("sequence: found %q, expected STRING", lit)
 		}
 
+		sequence.Of = "BIT STRING"
+
 		if tok, _ := p.scanIgnoreWhitespace(); tok != GROUP_OPEN {
 			p.unscan()
 		} else {
@@ -483,42 +517,14 @@ func (p *Parser) scanInteger(cmmn ASNCommon) (ASNType, error) {
 		ASNEnum{},
 	}
 
-	if err := p.scanEnum(obj); err != nil {
+	if err := p.scanEnum(&obj.ASNEnum); err != nil {
 		return nil, err
 	}
 
 	// scan ranges
 	// ABRT-source ::= INTEGER {service-user(0), service-provider(1)}(0..1, ...)
-	if tok, _ := p.scanIgnoreWhitespace(); tok == PARENTHESES_OPEN {
-		for {
-			tok, lit := p.scanIgnoreWhitespace()
-			if tok == TRIPLE_DOT {
-				// TODO: TRIPLE_DOT
-			} else if tok == IDENT {
-				p.unscan()
-
-				from, to, err := p.scanRange()
-				if err != nil {
-					return nil, err
-				}
-
-				_ = from
-				_ = to
-			} else {
-				return nil, fmt.Errorf("integer: found %q, expected value", lit)
-			}
-
-			if tok, _ := p.scanIgnoreWhitespace(); tok != COMMA {
-				p.unscan()
-				break
-			}
-		}
-
-		if tok, lit := p.scanIgnoreWhitespace(); tok != PARENTHESES_CLOSE {
-			return nil, fmt.Errorf("integer: found %q, expected close parentheses", lit)
-		}
-	} else {
-		p.unscan()
+	if err := p.scanConstraint(&obj.ASNCommon); err != nil {
+		return nil, err
 	}
 
 	return obj, nil
@@ -530,7 +536,7 @@ func (p *Parser) scanEnumerated(cmmn ASNCommon) (ASNType, error) {
 		ASNEnum{},
 	}
 
-	if err := p.scanEnum(obj); err != nil {
+	if err := p.scanEnum(&obj.ASNEnum); err != nil {
 		return nil, err
 	}
 
@@ -544,9 +550,10 @@ func (p *Parser) scanRange() (from string, to string, err error) {
 		from = lit
 	}
 
-	// double dot
-	if tok, lit := p.scanIgnoreWhitespace(); tok != DOUBLE_DOT {
-		return "", "", fmt.Errorf("range: found %q, expected double dot", lit)
+	// single value
+	if tok, _ := p.scanIgnoreWhitespace(); tok != DOUBLE_DOT {
+		p.unscan()
+		return from, from, nil
 	}
 
 	if tok, lit := p.scanIgnoreWhitespace(); tok != IDENT {
@@ -570,45 +577,156 @@ func (p *Parser) scanBitString(cmmn ASNCommon) (ASNType, error) {
 	}
 
 	// BIT TYPE (STRING)
-	if tok, lit := p.scanIgnoreWhitespace(); tok == PARENTHESES_OPEN {
-		if tok, lit = p.scanIgnoreWhitespace(); tok != SIZE {
-			p.unscan()
-		} else if tok, lit = p.scanIgnoreWhitespace(); tok != PARENTHESES_OPEN {
+	if err := p.scanConstraint(&obj.ASNCommon); err != nil {
+		return nil, err
+	}
+
+	if tok, _ := p.scanIgnoreWhitespace(); tok == DEFAULT {
+		// TODO: implement DEFAULT
+	} else {
+		p.unscan()
+	}
+
+	if err := p.scanEnum(&obj.ASNEnum); err != nil {
+		return nil, err
+	}
+
+	// BIT STRING { a(0), b(1) } (SIZE (2))
+	if err := p.scanConstraint(&obj.ASNCommon); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// scanConstraint scans the optional constraint following a type, eg.
+// (0..255, ...) or (SIZE (1..8)), and records it. Other constraints, like
+// permitted alphabets, are skipped.
+func (p *Parser) scanConstraint(cmmn *ASNCommon) error {
+	if tok, _ := p.scanIgnoreWhitespace(); tok != PARENTHESES_OPEN {
+		p.unscan()
+		return nil
+	}
+
+	if tok, _ := p.scanIgnoreWhitespace(); tok == SIZE {
+		size, err := p.scanSize()
+		if err != nil {
+			return err
+		}
+
+		cmmn.Size = size
+
+		// (SIZE (1..8), ...)
+		if tok, _ := p.scanIgnoreWhitespace(); tok != COMMA {
 			p.unscan()
+		} else if tok, lit := p.scanIgnoreWhitespace(); tok != TRIPLE_DOT {
+			return fmt.Errorf("constraint: found %q, expected triple dot", lit)
 		} else {
+			cmmn.Size.Extensible = true
+		}
+	} else if tok == IDENT || tok == TRIPLE_DOT {
+		p.unscan()
+
+		r, err := p.scanRangeList()
+		if err != nil {
+			return err
+		}
+
+		cmmn.Range = r
+	} else {
+		// skip unsupported constraint
+		for depth := 1; depth > 0; {
+			switch tok, _ := p.scanIgnoreWhitespace(); tok {
+			case PARENTHESES_OPEN:
+				depth++
+			case PARENTHESES_CLOSE:
+				depth--
+			case EOF:
+				return fmt.Errorf("constraint: unexpected end of file")
+			}
+		}
+
+		return nil
+	}
+
+	if tok, lit := p.scanIgnoreWhitespace(); tok != PARENTHESES_CLOSE {
+		return fmt.Errorf("constraint: found %q, expected close parentheses", lit)
+	}
+
+	return nil
+}
+
+// scanSize scans the ranges of a SIZE constraint, eg. (1..8, ...)
+func (p *Parser) scanSize() (*ASNRange, error) {
+	if tok, lit := p.scanIgnoreWhitespace(); tok != PARENTHESES_OPEN {
+		return nil, fmt.Errorf("size: found %q, expected open parentheses", lit)
+	}
+
+	r, err := p.scanRangeList()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok, lit := p.scanIgnoreWhitespace(); tok != PARENTHESES_CLOSE {
+		return nil, fmt.Errorf("size: found %q, expected close parentheses", lit)
+	}
+
+	return r, nil
+}
+
+// scanRangeList scans a list of ranges and an optional extension marker.
+// Only the first range is recorded.
+func (p *Parser) scanRangeList() (*ASNRange, error) {
+	r := &ASNRange{}
+
+	first := true
+	for {
+		tok, lit := p.scanIgnoreWhitespace()
+		if tok == TRIPLE_DOT {
+			r.Extensible = true
+		} else if tok == IDENT {
+			p.unscan()
+
 			from, to, err := p.scanRange()
 			if err != nil {
 				return nil, err
 			}
 
-			_ = from
-			_ = to
-			if tok, lit = p.scanIgnoreWhitespace(); tok != PARENTHESES_CLOSE {
-				return nil, fmt.Errorf("bitstring: found %q, expected close parentheses", lit)
+			if first {
+				r.From, r.To = from, to
+				first = false
 			}
+		} else {
+			return nil, fmt.Errorf("range: found %q, expected value", lit)
 		}
 
-		if tok, lit = p.scanIgnoreWhitespace(); tok != PARENTHESES_CLOSE {
-			return nil, fmt.Errorf("bitstring: found %q, expected close parentheses", lit)
+		if tok, _ := p.scanIgnoreWhitespace(); tok != COMMA {
+			p.unscan()
+			break
 		}
-	} else {
-		p.unscan()
 	}
 
-	if tok, _ := p.scanIgnoreWhitespace(); tok == DEFAULT {
-		// TODO: implement DEFAULT
-	} else {
-		p.unscan()
-	}
+	return r, nil
+}
 
-	if err := p.scanEnum(obj); err != nil {
+func (p *Parser) scanType(cmmn ASNCommon) (ASNType, error) {
+	type_, err := p.scanPlainType(cmmn)
+	if err != nil {
 		return nil, err
 	}
 
-	return obj, nil
+	if c, ok := type_.(interface {
+		common() *ASNCommon
+	}); ok {
+		if err := p.scanConstraint(c.common()); err != nil {
+			return nil, err
+		}
+	}
+
+	return type_, nil
 }
 
-func (p *Parser) scanType(cmmn ASNCommon) (ASNType, error) {
+func (p *Parser) scanPlainType(cmmn ASNCommon) (ASNType, error) {
 	switch tok, lit := p.scanIgnoreWhitespace(); tok {
 	case OBJECT_DESCRIPTOR:
 		return &ASNObjectDescriptor{
@@ -696,16 +814,35 @@ func (p *Parser) scanDefault(e ASNEnumerer /* ASNDefaulter */) error {
 	return fmt.Errorf("Default has not been implemented yet.")
 }
 
-func (p *Parser) scanEnum(e ASNEnumerer) error {
+func (p *Parser) scanEnum(e *ASNEnum) error {
 	if tok, _ := p.scanIgnoreWhitespace(); tok != GROUP_OPEN {
 		p.unscan()
 		return nil
 	}
 
+	// names without a value are numbered after all items have been scanned
+	unnumbered := []string{}
+	extension := false
+
+	add := func(name string, v interface{}) error {
+		if _, ok := e.Values[name]; ok {
+			return fmt.Errorf("enum: duplicate name %s", name)
+		}
+
+		e.Add(name, v)
+
+		if extension {
+			e.Additions = append(e.Additions, name)
+		}
+
+		return nil
+	}
+
 	for {
 		tok, lit := p.scanIgnoreWhitespace()
 		if tok == TRIPLE_DOT {
-			// TODO: triple dot
+			e.Extensible = true
+			extension = true
 		} else if tok == IDENT {
 		} else {
 			return fmt.Errorf("enum: found %q, expected IDENT %+#v", tok, lit)
@@ -720,7 +857,9 @@ func (p *Parser) scanEnum(e ASNEnumerer) error {
 				return fmt.Errorf("enum: found %q, expected IDENT5", tok)
 			}
 
-			e.Add(name, lit)
+			if err := add(name, lit); err != nil {
+				return err
+			}
 
 			// CONSTANT
 			if tok, lit = p.scanIgnoreWhitespace(); tok != PARENTHESES_CLOSE {
@@ -737,6 +876,16 @@ func (p *Parser) scanEnum(e ASNEnumerer) error {
 			p.unscan()
 		}
 
+		if name != "" {
+			// the value is set once all items have been scanned, the
+			// name is added now to keep the order of the scheme
+			if err := add(name, nil); err != nil {
+				return err
+			}
+
+			unnumbered = append(unnumbered, name)
+		}
+
 		if tok, lit = p.scanIgnoreWhitespace(); tok != COMMA {
 			p.unscan()
 			break
@@ -747,6 +896,68 @@ func (p *Parser) scanEnum(e ASNEnumerer) error {
 		return fmt.Errorf("enum: found %q, expected GROUP_CLOSE", lit)
 	}
 
+	additions := map[string]bool{}
+	for _, name := range e.Additions {
+		additions[name] = true
+	}
+
+	// X.680 20.3, unnumbered root items get the lowest values unused by the
+	// root items
+	used := map[string]bool{}
+	for name, v := range e.Values {
+		if v != nil && !additions[name] {
+			used[fmt.Sprint(v)] = true
+		}
+	}
+
+	next := 0
+	for _, name := range unnumbered {
+		if additions[name] {
+			continue
+		}
+
+		for used[strconv.Itoa(next)] {
+			next++
+		}
+
+		e.Add(name, strconv.Itoa(next))
+		used[strconv.Itoa(next)] = true
+	}
+
+	// unnumbered extension additions get the value following the largest
+	// value of the root and the preceding additions
+	last := int64(-1)
+	for _, name := range e.Names {
+		if n, err := strconv.ParseInt(fmt.Sprint(e.Values[name]), 10, 64); err == nil && !additions[name] && n > last {
+			last = n
+		}
+	}
+
+	for _, name := range e.Additions {
+		if e.Values[name] == nil {
+			last++
+			e.Add(name, strconv.FormatInt(last, 10))
+		} else if n, err := strconv.ParseInt(fmt.Sprint(e.Values[name]), 10, 64); err == nil && n > last {
+			last = n
+		}
+	}
+
+	// the names of an enumeration, named number or named bit list have
+	// distinct values
+	names := map[string]string{}
+	for _, name := range e.Names {
+		value := fmt.Sprint(e.Values[name])
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			value = strconv.FormatInt(n, 10)
+		}
+
+		if other, ok := names[value]; ok {
+			return fmt.Errorf("enum: duplicate value %s of %s and %s", value, other, name)
+		}
+
+		names[value] = name
+	}
+
 	return nil
 }
 
@@ -804,32 +1015,37 @@ func (p *Parser) scanGroupItem(current ASNType) error {
 	}
 
 	optional := false
-	defaultValue := ""
+	var defaultValue interface{}
 
 	if tok, lit = p.scanIgnoreWhitespace(); tok == OPTIONAL {
 		// value is optional
 		optional = true
 	} else if tok == DEFAULT {
-		defaultValue = "DEFAULT"
-
 		// value has a default value
 		if tok, lit = p.scanIgnoreWhitespace(); tok == TRUE {
-			// TODO: implement DEFAULT
+			defaultValue = true
 		} else if tok == FALSE {
-			// TODO: implement DEFAULT
+			defaultValue = false
 		} else if tok == IDENT {
-			// TODO: implement DEFAULT
+			if v, err := strconv.ParseInt(lit, 10, 64); err == nil {
+				defaultValue = v
+			} else {
+				defaultValue = lit
+			}
 		} else if tok == GROUP_OPEN {
+			values := []string{}
+
 			if tok, lit := p.scanIgnoreWhitespace(); tok != IDENT {
 				p.unscan()
 			} else {
-				// TODO: implement DEFAULT
-				_ = lit
+				values = append(values, lit)
 			}
 
 			if tok, lit := p.scanIgnoreWhitespace(); tok != GROUP_CLOSE {
 				return fmt.Errorf("group: found %q, expected GROUP_CLOSE", lit)
 			}
+
+			defaultValue = values
 		} else {
 			return fmt.Errorf("group: found %q, expected VALUE", lit)
 		}
@@ -919,17 +1135,6 @@ func (p *Parser) scanGroup(current ASNType) error {
 			p.unscan()
 			break
 		}
-
-		// REPEATER
-		if tok, lit = p.scanIgnoreWhitespace(); tok != TRIPLE_DOT {
-			p.unscan()
-			continue
-		}
-
-		if tok, lit = p.scanIgnoreWhitespace(); tok != COMMA {
-			p.unscan()
-			break
-		}
 	}
 
 	if tok, lit := p.scanIgnoreWhitespace(); tok != GROUP_CLOSE {
@@ -51,8 +51,9 @@ DEFINITIONS ::=
 BEGIN
 END
 `, def: &asn1parser.ASNDefinition{
-			Name:  "MMS",
-			Types: []asn1parser.ASNType{},
+			Name:    "MMS",
+			Types:   []asn1parser.ASNType{},
+			Imports: map[string][]string{},
 		},
 			err: ""},
 	}
@@ -74,3 +75,93 @@ func errstring(err error) string {
 	}
 	return ""
 }
+
+func TestParser_Constraints(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(`
+Test DEFINITIONS AUTOMATIC TAGS ::=
+BEGIN
+
+Level ::= INTEGER (-1..10, ...)
+Name ::= IA5String (SIZE (1..8))
+List ::= SEQUENCE SIZE (0..4) OF Level
+Color ::= ENUMERATED { red, green(5), ..., blue }
+
+END
+`)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if def.Tagging != "AUTOMATIC" {
+		t.Errorf("tagging mismatch: exp=AUTOMATIC got=%s", def.Tagging)
+	}
+
+	level := def.Lookup("Level").(*asn1parser.ASNInteger)
+	if exp := (&asn1parser.ASNRange{From: "-1", To: "10", Extensible: true}); !reflect.DeepEqual(exp, level.Range) {
+		t.Errorf("range mismatch: exp=%#v got=%#v", exp, level.Range)
+	}
+
+	name := def.Lookup("Name").(*asn1parser.ASNCustom)
+	if exp := (&asn1parser.ASNRange{From: "1", To: "8"}); !reflect.DeepEqual(exp, name.Size) {
+		t.Errorf("size mismatch: exp=%#v got=%#v", exp, name.Size)
+	}
+
+	list := def.Lookup("List").(*asn1parser.ASNSequence)
+	if exp := (&asn1parser.ASNRange{From: "0", To: "4"}); list.Of != "Level" || !reflect.DeepEqual(exp, list.Size) {
+		t.Errorf("sequence of mismatch: exp=%#v got=%s %#v", exp, list.Of, list.Size)
+	}
+
+	color := def.Lookup("Color").(*asn1parser.ASNEnumerated)
+	if exp := map[string]interface{}{"red": "0", "green": "5", "blue": "6"}; !reflect.DeepEqual(exp, color.Values) {
+		t.Errorf("values mismatch: exp=%#v got=%#v", exp, color.Values)
+	}
+
+	if !color.Extensible || !reflect.DeepEqual([]string{"blue"}, color.Additions) {
+		t.Errorf("extension mismatch: got=%v %v", color.Extensible, color.Additions)
+	}
+}
+
+func TestParser_Enumerations(t *testing.T) {
+	var tests = []struct {
+		in     string
+		values map[string]interface{}
+		err    string
+	}{
+		{in: "ENUMERATED { a(5), b(6), ..., c }", values: map[string]interface{}{"a": "5", "b": "6", "c": "7"}},
+		{in: "ENUMERATED { a, b(0), ..., c(4), d }", values: map[string]interface{}{"a": "1", "b": "0", "c": "4", "d": "5"}},
+		{in: "INTEGER { a(1), b(2) }", values: map[string]interface{}{"a": "1", "b": "2"}},
+		{in: "ENUMERATED { a(1), b(0), c(1) }", err: "enum: duplicate value 1 of a and c"},
+		{in: "ENUMERATED { a, b(0) }", values: map[string]interface{}{"a": "1", "b": "0"}},
+		{in: "ENUMERATED { a, ..., b(0) }", err: "enum: duplicate value 0 of a and b"},
+		{in: "ENUMERATED { a, b, a }", err: "enum: duplicate name a"},
+		{in: "INTEGER { a(1), b(01) }", err: "enum: duplicate value 1 of a and b"},
+		{in: "BIT STRING { a(0), b(0) }", err: "enum: duplicate value 0 of a and b"},
+	}
+
+	for i, tt := range tests {
+		def, err := asn1parser.NewParser(strings.NewReader("Test DEFINITIONS ::= BEGIN T ::= " + tt.in + " END")).Parse()
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%d. error mismatch: exp=%s got=%v", i, tt.err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		var values map[string]interface{}
+		switch v := def.Lookup("T").(type) {
+		case *asn1parser.ASNEnumerated:
+			values = v.Values
+		case *asn1parser.ASNInteger:
+			values = v.Values
+		}
+
+		if !reflect.DeepEqual(tt.values, values) {
+			t.Errorf("%d. values mismatch: exp=%#v got=%#v", i, tt.values, values)
+		}
+	}
+}
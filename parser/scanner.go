@@ -28,6 +28,14 @@ func (s *Scanner) Scan() (tok Token, lit string) {
 		s.unread()
 		return s.scanWhitespace()
 	} else if isComment(ch) {
+		// a minus sign followed by a digit is a negative number
+		if next := s.read(); isDigit(next) {
+			s.unread()
+
+			_, lit := s.scanIdent()
+			return IDENT, "-" + lit
+		}
+
 		// peek next char is comment also
 		s.unread()
 		return s.scanComment()
@@ -99,11 +107,12 @@ func (s *Scanner) Scan() (tok Token, lit string) {
 	return ILLEGAL, string(ch)
 }
 
-// scanComment consumes the current rune and all contiguous whitespace.
+// scanComment consumes the rest of the line, the initial dash has already
+// been read.
 func (s *Scanner) scanComment() (tok Token, lit string) {
-	// Create a buffer and read the current character into it.
+	// Create a buffer and write the current character into it.
 	var buf bytes.Buffer
-	buf.WriteRune(s.read())
+	buf.WriteRune('-')
 
 	// Read every subsequent whitespace character into the buffer.
 	// Non-whitespace characters and EOF will cause the loop to exit.
@@ -219,6 +228,8 @@ func (s *Scanner) scanIdent() (tok Token, lit string) {
 		return VISIBLE_STRING, buf.String()
 	case "PrintableString":
 		return PRINTABLE_STRING, buf.String()
+	case "NumericString":
+		return NUMERIC_STRING, buf.String()
 	case "T61String":
 		return T61_STRING, buf.String()
 	case "OBJECT":
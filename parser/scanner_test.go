@@ -3,6 +3,8 @@ package asn1parser_test
 import (
 	"strings"
 	"testing"
+
+	"github.com/dutchsec/asn1/parser"
 )
 
 // Ensure the scanner can scan tokens correctly.
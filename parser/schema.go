@@ -0,0 +1,624 @@
+package asn1parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	asn1 "github.com/dutchsec/asn1"
+)
+
+// TypeTag is a tag applied to a type or an item, eg. [APPLICATION 1].
+type TypeTag struct {
+	Tag asn1.ASNTag
+
+	// Explicit is set when the tag is an explicit tag, the tagged value is
+	// then encoded within a constructed value with the tag.
+	Explicit bool
+}
+
+// Lookup returns the type with the given name, or nil if the definition
+// does not contain the type.
+func (d *ASNDefinition) Lookup(name string) ASNType {
+	for _, t := range d.Types {
+		if t.Name() == name {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// Resolve follows references to other types of the definition, until a
+// type is found that is not a reference. References to unknown types, like
+// the builtin BOOLEAN type, are returned as is.
+func (d *ASNDefinition) Resolve(t ASNType) ASNType {
+	types := d.chain(t)
+	return types[len(types)-1]
+}
+
+// chain returns t followed by the types it references, see Resolve.
+func (d *ASNDefinition) chain(t ASNType) []ASNType {
+	types := []ASNType{t}
+
+	for i := 0; i < len(d.Types); i++ {
+		var name string
+
+		switch v := t.(type) {
+		case *ASNCustom:
+			name = v.Type
+		case *ASNAlias:
+			name = v.Alias
+		default:
+			return types
+		}
+
+		next := d.Lookup(name)
+		if next == nil {
+			return types
+		}
+
+		t = next
+		types = append(types, t)
+	}
+
+	return types
+}
+
+// commonOf returns the ASNCommon embedded in t.
+func commonOf(t ASNType) (*ASNCommon, bool) {
+	c, ok := t.(interface {
+		common() *ASNCommon
+	})
+	if !ok {
+		return nil, false
+	}
+
+	return c.common(), true
+}
+
+// TypeTags returns the tags applied to values of type t and the types it
+// references, outermost first. The universal tag of the builtin type is not
+// included, see UniversalTag.
+func (d *ASNDefinition) TypeTags(t ASNType) []TypeTag {
+	types := d.chain(t)
+
+	_, choice := types[len(types)-1].(*ASNChoice)
+
+	tags := []TypeTag{}
+	for i := len(types) - 1; i >= 0; i-- {
+		c, ok := commonOf(types[i])
+		if !ok || c.Tag() == ASNTagNotSet {
+			continue
+		}
+
+		tags = append([]TypeTag{{
+			Tag:      c.Tag(),
+			Explicit: d.isExplicitTag(c.Implicit, c.Explicit, choice),
+		}}, tags...)
+
+		choice = false
+	}
+
+	return tags
+}
+
+// ItemTag returns the tag given to the i-th item of a SEQUENCE, SET or
+// CHOICE, either in the scheme or by automatic tagging. False is returned
+// when the item is not tagged.
+func (d *ASNDefinition) ItemTag(items []ASNItem, i int) (TypeTag, bool) {
+	item := items[i]
+
+	_, choice := d.Resolve(item.Type).(*ASNChoice)
+	choice = choice && len(d.TypeTags(item.Type)) == 0
+
+	if tag, ok := itemTag(item); ok {
+		return TypeTag{
+			Tag:      tag,
+			Explicit: d.isExplicitTag(item.Implicit, item.Explicit, choice),
+		}, true
+	}
+
+	if d.Tagging != "AUTOMATIC" {
+		return TypeTag{}, false
+	}
+
+	// X.680 25.3, automatic tagging only applies when none of the items
+	// have been tagged.
+	n := 0
+	for j, other := range items {
+		if other.TripleDot {
+			continue
+		}
+
+		if _, ok := itemTag(other); ok {
+			return TypeTag{}, false
+		}
+
+		if j < i {
+			n++
+		}
+	}
+
+	return TypeTag{
+		Tag:      asn1.Tag(asn1.ClassContextSpecific, asn1.ASNValue(n)),
+		Explicit: choice,
+	}, true
+}
+
+// isExplicitTag returns true when a tag is an explicit tag, taking the
+// default tagging of the module into account. Tags of untagged CHOICE types
+// are always explicit (X.680 31.2.9).
+func (d *ASNDefinition) isExplicitTag(implicit, explicit, choice bool) bool {
+	if explicit || choice {
+		return true
+	}
+
+	if implicit {
+		return false
+	}
+
+	return d.Tagging != "IMPLICIT" && d.Tagging != "AUTOMATIC"
+}
+
+// Constraints returns the SIZE and value range constraints of type t. The
+// constraints of t take precedence over those of the types it references.
+func (d *ASNDefinition) Constraints(t ASNType) (size *ASNRange, rng *ASNRange) {
+	for _, t := range d.chain(t) {
+		c, ok := commonOf(t)
+		if !ok {
+			continue
+		}
+
+		if size == nil {
+			size = c.Size
+		}
+
+		if rng == nil {
+			rng = c.Range
+		}
+	}
+
+	return size, rng
+}
+
+// Value returns the integer value of s, which is either a number or the
+// name of a value defined in the module, eg. maxSize INTEGER ::= 10.
+func (d *ASNDefinition) Value(s string) (int64, bool) {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return v, true
+	}
+
+	alias, ok := d.Lookup(s).(*ASNAlias)
+	if !ok {
+		return 0, false
+	}
+
+	v, err := strconv.ParseInt(alias.Default, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
+
+// ElementType returns the type of the components of a SEQUENCE OF.
+func (d *ASNDefinition) ElementType(s *ASNSequence) ASNType {
+	if t := d.Lookup(s.Of); t != nil {
+		return t
+	}
+
+	cmmn := ASNCommon{
+		name: s.Of,
+	}
+
+	switch s.Of {
+	case "SEQUENCE":
+		return &ASNSequence{cmmn, "", s.Items}
+	case "CHOICE":
+		return &ASNChoice{cmmn, s.Items}
+	case "INTEGER":
+		return &ASNInteger{cmmn, ASNEnum{}}
+	case "BIT STRING":
+		return &ASNBitString{cmmn, ASNEnum{}}
+	case "OBJECT IDENTIFIER":
+		return &ASNObjectIdentifier{cmmn}
+	case "GraphicString":
+		return &ASNGraphicString{cmmn}
+	case "VisibleString":
+		return &ASNVisibleString{cmmn}
+	case "GeneralizedTime":
+		return &ASNGeneralizedTime{cmmn}
+	case "NumericString":
+		return &ASNNumericString{cmmn}
+	case "GeneralString":
+		return &ASNGeneralString{cmmn}
+	case "PrintableString":
+		return &ASNPrintableString{cmmn}
+	}
+
+	return &ASNCustom{cmmn, s.Of}
+}
+
+// UniversalTag returns the universal tag of the builtin type t.
+func UniversalTag(t ASNType) (asn1.ASNValue, bool) {
+	switch v := t.(type) {
+	case *ASNSequence:
+		return asn1.TagSequence, true
+	case *ASNSet:
+		return asn1.TagSet, true
+	case *ASNInteger:
+		return asn1.TagInteger, true
+	case *ASNEnumerated:
+		return asn1.TagEnumerated, true
+	case *ASNBitString:
+		return asn1.TagBitString, true
+	case *ASNOctetString:
+		return asn1.TagOctetString, true
+	case *ASNObjectIdentifier:
+		return asn1.TagOid, true
+	case *ASNObjectDescriptor:
+		return asn1.TagObjectDescriptor, true
+	case *ASNPrintableString:
+		return asn1.TagPrintableString, true
+	case *ASNNumericString:
+		return asn1.TagNumericString, true
+	case *ASNT61String:
+		return asn1.TagT61String, true
+	case *ASNVisibleString:
+		return asn1.TagVisibleString, true
+	case *ASNGraphicString:
+		return asn1.TagGraphicString, true
+	case *ASNGeneralString:
+		return asn1.TagGeneralString, true
+	case *ASNUTCTime:
+		return asn1.TagUTCTime, true
+	case *ASNGeneralizedTime:
+		return asn1.TagGeneralizedTime, true
+	case *ASNCustom:
+		switch v.Type {
+		case "BOOLEAN":
+			return asn1.TagBoolean, true
+		case "NULL":
+			return asn1.TagNull, true
+		case "REAL":
+			return asn1.TagReal, true
+		case "UTF8String":
+			return asn1.TagUTF8String, true
+		case "IA5String":
+			return asn1.TagIA5String, true
+		}
+	}
+
+	return 0, false
+}
+
+// Components splits the items of a SEQUENCE, SET or CHOICE into the
+// indexes of the root components and the extension additions.
+func (d *ASNDefinition) Components(items []ASNItem) (root []int, additions []int, extensible bool) {
+	markers := 0
+
+	for i, item := range items {
+		if item.TripleDot {
+			markers++
+			continue
+		}
+
+		if markers == 1 {
+			additions = append(additions, i)
+		} else {
+			root = append(root, i)
+		}
+	}
+
+	return root, additions, markers > 0 || d.ExtensibilityImplied
+}
+
+// ItemOuterTags returns the possible outermost tags of values of the i-th
+// item of a SEQUENCE, SET or CHOICE.
+func (d *ASNDefinition) ItemOuterTags(items []ASNItem, i int) []asn1.ASNTag {
+	if tag, ok := d.ItemTag(items, i); ok {
+		return []asn1.ASNTag{tag.Tag}
+	}
+
+	return d.OuterTags(items[i].Type)
+}
+
+// OuterTags returns the possible outermost tags of values of type t, in
+// canonical order. Values of an untagged CHOICE have the tag of one of the
+// alternatives.
+func (d *ASNDefinition) OuterTags(t ASNType) []asn1.ASNTag {
+	if tags := d.TypeTags(t); len(tags) > 0 {
+		return []asn1.ASNTag{tags[0].Tag}
+	}
+
+	t = d.Resolve(t)
+
+	if choice, ok := t.(*ASNChoice); ok {
+		tags := []asn1.ASNTag{}
+		for i, item := range choice.Items {
+			if !item.TripleDot {
+				tags = append(tags, d.ItemOuterTags(choice.Items, i)...)
+			}
+		}
+
+		sort.Slice(tags, func(i, j int) bool {
+			return lessTag(tags[i], tags[j])
+		})
+
+		return tags
+	}
+
+	if value, ok := UniversalTag(t); ok {
+		return []asn1.ASNTag{asn1.Tag(asn1.ClassUniversal, value)}
+	}
+
+	return nil
+}
+
+// lessTag returns true when a precedes b in the canonical order of tags.
+func lessTag(a, b asn1.ASNTag) bool {
+	if a.Class != b.Class {
+		return a.Class < b.Class
+	}
+	return a.Value < b.Value
+}
+
+// SortByTag sorts item indexes into the canonical order of the tags of the
+// items, as used for the components of SET and the alternatives of CHOICE
+// types.
+func (d *ASNDefinition) SortByTag(items []ASNItem, indexes []int) {
+	sort.SliceStable(indexes, func(i, j int) bool {
+		a := d.ItemOuterTags(items, indexes[i])
+		b := d.ItemOuterTags(items, indexes[j])
+		if len(a) == 0 || len(b) == 0 {
+			return false
+		}
+
+		return lessTag(a[0], b[0])
+	})
+}
+
+// MatchItems returns the values of a SEQUENCE or SET by the index of their
+// item. The values of a SEQUENCE are in the order of the items, the values
+// of a SET can be in any order. Values of an extensible type that match no
+// item are extension additions unknown to the schema, they are left out.
+func (d *ASNDefinition) MatchItems(items []ASNItem, values []*asn1.RawValue, set bool) (map[int]*asn1.RawValue, error) {
+	present := map[int]*asn1.RawValue{}
+	root, _, extensible := d.Components(items)
+
+	next := 0
+	for _, value := range values {
+		found := false
+
+		for i := next; i < len(items); i++ {
+			if items[i].TripleDot || present[i] != nil || !hasTag(d.ItemOuterTags(items, i), value.Tag) {
+				continue
+			}
+
+			present[i] = value
+			found = true
+
+			if !set {
+				next = i + 1
+			}
+
+			break
+		}
+
+		if !found && !extensible {
+			return nil, fmt.Errorf("unexpected component %s", value.Tag)
+		}
+	}
+
+	for _, i := range root {
+		if present[i] == nil && !items[i].Optional && items[i].Default == nil {
+			return nil, fmt.Errorf("missing component %s", items[i].Name)
+		}
+	}
+
+	return present, nil
+}
+
+// MatchAlternative returns the index of the item of a CHOICE the value with
+// the given tag is a value of.
+func (d *ASNDefinition) MatchAlternative(items []ASNItem, tag asn1.ASNTag) (int, bool) {
+	for i, item := range items {
+		if !item.TripleDot && hasTag(d.ItemOuterTags(items, i), tag) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// hasTag returns true when tags contains tag.
+func hasTag(tags []asn1.ASNTag, tag asn1.ASNTag) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Numbers returns the names of the root values of an enumeration in the
+// order of their values, and the values by name.
+func (e *ASNEnum) Numbers() ([]string, map[string]int64, error) {
+	values := map[string]int64{}
+	for name, v := range e.Values {
+		n, err := strconv.ParseInt(fmt.Sprint(v), 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid value %v of %s", v, name)
+		}
+
+		values[name] = n
+	}
+
+	additions := map[string]bool{}
+	for _, name := range e.Additions {
+		additions[name] = true
+	}
+
+	root := []string{}
+	for _, name := range e.names() {
+		if !additions[name] {
+			root = append(root, name)
+		}
+	}
+
+	sort.SliceStable(root, func(i, j int) bool {
+		return values[root[i]] < values[root[j]]
+	})
+
+	return root, values, nil
+}
+
+// NameOf returns the name with the value n.
+func (e *ASNEnum) NameOf(n int64) (string, bool, error) {
+	for _, name := range e.names() {
+		value, err := strconv.ParseInt(fmt.Sprint(e.Values[name]), 10, 64)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid value %v of %s", e.Values[name], name)
+		}
+
+		if value == n {
+			return name, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// names returns the names of the enumeration in the order of the scheme, or
+// sorted when the order is not known.
+func (e *ASNEnum) names() []string {
+	if len(e.Names) == len(e.Values) {
+		return e.Names
+	}
+
+	names := []string{}
+	for name := range e.Values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ApplyTag tags raw, a value of a type, with tag.
+func ApplyTag(raw *asn1.RawValue, tag TypeTag) (*asn1.RawValue, error) {
+	if !tag.Explicit {
+		return &asn1.RawValue{
+			Tag:         tag.Tag,
+			Constructed: raw.Constructed,
+			Content:     raw.Content,
+		}, nil
+	}
+
+	content, err := raw.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &asn1.RawValue{
+		Tag:         tag.Tag,
+		Constructed: true,
+		Content:     content,
+	}, nil
+}
+
+// Untag removes the tags from raw, outermost first. Retagged is set when
+// the outermost tag of raw has been replaced by an implicit tag, the tag of
+// raw is then not checked.
+func Untag(raw *asn1.RawValue, tags []TypeTag, retagged bool) (*asn1.RawValue, bool, error) {
+	for _, tag := range tags {
+		if !retagged && raw.Tag != tag.Tag {
+			return nil, false, fmt.Errorf("found %s, expected %s", raw.Tag, tag.Tag)
+		}
+
+		retagged = !tag.Explicit
+		if retagged {
+			continue
+		}
+
+		inner, err := unwrapExplicit(raw)
+		if err != nil {
+			return nil, false, err
+		}
+
+		raw = inner
+	}
+
+	return raw, retagged, nil
+}
+
+// TagType applies the tags of type t to raw, a value of the builtin type t
+// refers to. It is used when building the BER encoding of a value.
+func (d *ASNDefinition) TagType(t ASNType, raw *asn1.RawValue) (*asn1.RawValue, error) {
+	tags := d.TypeTags(t)
+	for i := len(tags) - 1; i >= 0; i-- {
+		var err error
+		if raw, err = ApplyTag(raw, tags[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return raw, nil
+}
+
+// TagItem applies the tags of the i-th item of a SEQUENCE, SET or CHOICE
+// and of its type to raw, a value of the builtin type of the item.
+func (d *ASNDefinition) TagItem(items []ASNItem, i int, raw *asn1.RawValue) (*asn1.RawValue, error) {
+	raw, err := d.TagType(items[i].Type, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if tag, ok := d.ItemTag(items, i); ok {
+		return ApplyTag(raw, tag)
+	}
+
+	return raw, nil
+}
+
+// UntagType removes the tags of type t from raw, a BER encoded value of type
+// t, and returns the value of the builtin type t refers to. The universal tag
+// of the value is verified, unless it has been replaced by an implicit tag.
+func (d *ASNDefinition) UntagType(t ASNType, raw *asn1.RawValue) (*asn1.RawValue, error) {
+	return d.untagType(t, raw, false)
+}
+
+// UntagItem removes the tags of the i-th item of a SEQUENCE, SET or CHOICE
+// and of its type from raw, see UntagType.
+func (d *ASNDefinition) UntagItem(items []ASNItem, i int, raw *asn1.RawValue) (*asn1.RawValue, error) {
+	tag, ok := d.ItemTag(items, i)
+	if !ok {
+		return d.untagType(items[i].Type, raw, false)
+	}
+
+	raw, retagged, err := Untag(raw, []TypeTag{tag}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.untagType(items[i].Type, raw, retagged)
+}
+
+// untagType removes the tags of type t from raw. Retagged is set when the
+// outermost tag of raw has been replaced by an implicit tag.
+func (d *ASNDefinition) untagType(t ASNType, raw *asn1.RawValue, retagged bool) (*asn1.RawValue, error) {
+	raw, retagged, err := Untag(raw, d.TypeTags(t), retagged)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, ok := UniversalTag(d.Resolve(t)); ok && !retagged {
+		if tag := asn1.Tag(asn1.ClassUniversal, value); raw.Tag != tag {
+			return nil, fmt.Errorf("found %s, expected %s", raw.Tag, tag)
+		}
+	}
+
+	return raw, nil
+}
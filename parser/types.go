@@ -8,6 +8,30 @@ type ASNDefinition struct {
 
 	Types   []ASNType
 	Imports map[string][]string
+
+	// Tagging is the default tagging of the module: EXPLICIT, IMPLICIT,
+	// AUTOMATIC or empty when not specified.
+	Tagging string
+	// ExtensibilityImplied is set when all types of the module are
+	// extensible.
+	ExtensibilityImplied bool
+
+	// Oids contains the OBJECT IDENTIFIER value assignments of the module.
+	Oids []ASNOidAssignment
+}
+
+// ASNOidAssignment is an OBJECT IDENTIFIER value assignment, eg.
+// "id-pkix OBJECT IDENTIFIER ::= { iso(1) identified-organization(3) 6 }".
+type ASNOidAssignment struct {
+	Name       string
+	Components []ASNOidComponent
+}
+
+// ASNOidComponent is a component of an OBJECT IDENTIFIER value: a name, a
+// number or a name with a number.
+type ASNOidComponent struct {
+	Name   string
+	Number string
 }
 
 // ASNItem is the base struct for definition types
@@ -22,6 +46,9 @@ type ASNItem struct {
 
 	Type ASNType
 
+	// Default contains the DEFAULT value of the item: a bool, an int64, an
+	// identifier string or a []string of identifiers. It is nil when the
+	// item has no DEFAULT value.
 	Default interface{}
 
 	TripleDot bool
@@ -33,6 +60,11 @@ type ASNCommon struct {
 	Implicit bool
 	Explicit bool
 
+	// Size contains the SIZE constraint of the type, if any.
+	Size *ASNRange
+	// Range contains the value range constraint of the type, if any.
+	Range *ASNRange
+
 	tag asn1.ASNTag
 }
 
@@ -40,6 +72,10 @@ func (c *ASNCommon) Name() string {
 	return c.name
 }
 
+func (c *ASNCommon) common() *ASNCommon {
+	return c
+}
+
 func (c *ASNCommon) Tag() asn1.ASNTag {
 	return c.tag
 }
@@ -53,6 +89,15 @@ type ASNType interface {
 	Tag() asn1.ASNTag
 }
 
+// ASNRange is a value range or SIZE constraint, eg. (1..8, ...). The bounds
+// are the literal values of the scheme, which can be MIN or MAX.
+type ASNRange struct {
+	From string
+	To   string
+
+	Extensible bool
+}
+
 type ASNAlias struct {
 	ASNCommon
 	Alias   string
@@ -71,6 +116,14 @@ type ASNInteger struct {
 
 type ASNEnum struct {
 	Values map[string]interface{}
+
+	// Names contains the names of Values in the order of the scheme.
+	Names []string
+
+	// Extensible is set when the list contains an extension marker, the
+	// names defined after the marker are listed in Additions.
+	Extensible bool
+	Additions  []string
 }
 
 func (b *ASNEnum) Add(key string, v interface{}) {
@@ -78,6 +131,10 @@ func (b *ASNEnum) Add(key string, v interface{}) {
 		b.Values = map[string]interface{}{}
 	}
 
+	if _, ok := b.Values[key]; !ok {
+		b.Names = append(b.Names, key)
+	}
+
 	b.Values[key] = v
 }
 
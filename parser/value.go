@@ -0,0 +1,518 @@
+package asn1parser
+
+import (
+	"fmt"
+
+	asn1 "github.com/dutchsec/asn1"
+)
+
+// ValueKind is the kind of a Value.
+type ValueKind int
+
+const (
+	// PrimitiveValue is a value of a simple type, see Value.Primitive.
+	PrimitiveValue ValueKind = iota
+	// RecordValue is a SEQUENCE or SET value, see Value.Fields.
+	RecordValue
+	// ChoiceValue is a CHOICE value, see Value.Alternative.
+	ChoiceValue
+	// ListValue is a SEQUENCE OF value, see Value.Elements.
+	ListValue
+)
+
+func (k ValueKind) String() string {
+	switch k {
+	case PrimitiveValue:
+		return "primitive"
+	case RecordValue:
+		return "record"
+	case ChoiceValue:
+		return "choice"
+	case ListValue:
+		return "list"
+	}
+
+	return fmt.Sprintf("ValueKind(%d)", int(k))
+}
+
+// Value is a value of a type of a definition, decoded using the schema
+// instead of Go types.
+type Value struct {
+	Kind ValueKind
+
+	// Type is the type of the value as referenced in the schema, use
+	// Resolve to get the builtin type.
+	Type ASNType
+
+	// Primitive contains the value of simple types:
+	//
+	//	BOOLEAN                     bool
+	//	NULL                        nil
+	//	INTEGER                     int64, or *big.Int when out of range
+	//	ENUMERATED                  int64
+	//	REAL                        float64
+	//	BIT STRING                  asn1.BitString
+	//	OCTET STRING                []byte
+	//	OBJECT IDENTIFIER           asn1.ObjectIdentifier
+	//	UTCTime, GeneralizedTime    time.Time
+	//	character string types      string
+	//
+	// Values of types that are not defined in the definition, eg. imported
+	// types, are kept as *asn1.RawValue.
+	Primitive interface{}
+
+	// Name is the identifier of the value of an ENUMERATED or an INTEGER
+	// with named numbers, or the name of the selected alternative of a
+	// CHOICE.
+	Name string
+
+	// Fields contains the components of a record in the order of the
+	// schema. Absent OPTIONAL components are not included.
+	Fields []Field
+
+	// Alternative is the value of the selected alternative of a CHOICE.
+	Alternative *Value
+
+	// Elements contains the components of a list.
+	Elements []*Value
+
+	// Raw is the encoding of the value, it is nil for DEFAULT values.
+	Raw *asn1.RawValue
+}
+
+// Field is a named component of a record.
+type Field struct {
+	Name  string
+	Value *Value
+
+	// Default is set when the component is absent from the encoding and
+	// the value is its DEFAULT value.
+	Default bool
+}
+
+// Field returns the value of the component of a record with the given name.
+func (v *Value) Field(name string) (*Value, bool) {
+	for _, f := range v.Fields {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// Decode decodes data, the complete BER encoding of a value of the type
+// typeName, into a Value.
+func (d *ASNDefinition) Decode(typeName string, data []byte) (*Value, error) {
+	raw, rest, err := asn1.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("value: %d trailing octets", len(rest))
+	}
+
+	return d.DecodeValue(typeName, &raw)
+}
+
+// DecodeValue decodes raw, a BER encoded value of the type typeName, into a
+// Value. References to other types are resolved, tags are removed and
+// absent components with a DEFAULT value are filled in.
+func (d *ASNDefinition) DecodeValue(typeName string, raw *asn1.RawValue) (*Value, error) {
+	t := d.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("value: unknown type %s", typeName)
+	}
+
+	return d.decodeValue(t, raw)
+}
+
+// decodeValue decodes raw, a value of type t.
+func (d *ASNDefinition) decodeValue(t ASNType, raw *asn1.RawValue) (*Value, error) {
+	inner, err := d.UntagType(t, raw)
+	if err != nil {
+		return nil, fmt.Errorf("value: %s", err)
+	}
+
+	return d.decodeBuiltin(t, raw, inner)
+}
+
+// decodeItem decodes raw, the value of the i-th item of a SEQUENCE, SET or
+// CHOICE.
+func (d *ASNDefinition) decodeItem(items []ASNItem, i int, raw *asn1.RawValue) (*Value, error) {
+	inner, err := d.UntagItem(items, i, raw)
+	if err != nil {
+		return nil, fmt.Errorf("value: %s: %s", items[i].Name, err)
+	}
+
+	return d.decodeBuiltin(items[i].Type, raw, inner)
+}
+
+// decodeBuiltin decodes inner, the value raw of type t with its tags
+// removed.
+func (d *ASNDefinition) decodeBuiltin(t ASNType, raw, inner *asn1.RawValue) (*Value, error) {
+	v, err := d.decodeResolved(d.Resolve(t), inner)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Type = t
+	v.Raw = raw
+	return v, nil
+}
+
+// decodeResolved decodes raw, a value of the builtin type t with its tags
+// removed.
+func (d *ASNDefinition) decodeResolved(t ASNType, raw *asn1.RawValue) (*Value, error) {
+	// the choice value is the value of the alternative
+	if v, ok := t.(*ASNChoice); ok {
+		i, ok := d.MatchAlternative(v.Items, raw.Tag)
+		if !ok {
+			return nil, fmt.Errorf("value: unknown alternative %s of %s", raw.Tag, t.Name())
+		}
+
+		alternative, err := d.decodeItem(v.Items, i, raw)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Value{
+			Kind:        ChoiceValue,
+			Name:        v.Items[i].Name,
+			Alternative: alternative,
+		}, nil
+	}
+
+	value, ok := UniversalTag(t)
+	if !ok {
+		// a type that is not defined in the definition
+		return &Value{
+			Kind:      PrimitiveValue,
+			Primitive: raw,
+		}, nil
+	}
+
+	switch v := t.(type) {
+	case *ASNSequence:
+		if v.Of != "" {
+			return d.decodeList(d.ElementType(v), raw)
+		}
+
+		return d.decodeRecord(v.Items, raw, false)
+	case *ASNSet:
+		return d.decodeRecord(v.Items, raw, true)
+	}
+
+	primitive, name, err := d.decodePrimitive(t, value, raw)
+	if err != nil {
+		return nil, fmt.Errorf("value: %s", err)
+	}
+
+	return &Value{
+		Kind:      PrimitiveValue,
+		Primitive: primitive,
+		Name:      name,
+	}, nil
+}
+
+// decodeRecord decodes raw, a SEQUENCE or SET value with the components
+// items.
+func (d *ASNDefinition) decodeRecord(items []ASNItem, raw *asn1.RawValue, set bool) (*Value, error) {
+	if !raw.Constructed {
+		return nil, fmt.Errorf("value: primitive %s, expected constructed value", raw.Tag)
+	}
+
+	children, err := raw.ChildValues()
+	if err != nil {
+		return nil, err
+	}
+
+	present, err := d.MatchItems(items, children, set)
+	if err != nil {
+		return nil, fmt.Errorf("value: %s", err)
+	}
+
+	v := &Value{
+		Kind:   RecordValue,
+		Fields: []Field{},
+	}
+
+	for i, item := range items {
+		if item.TripleDot {
+			continue
+		}
+
+		field := Field{
+			Name: item.Name,
+		}
+
+		if child, ok := present[i]; ok {
+			field.Value, err = d.decodeItem(items, i, child)
+		} else if item.Default != nil {
+			field.Value, err = d.defaultValue(item)
+			field.Default = true
+		} else {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		v.Fields = append(v.Fields, field)
+	}
+
+	return v, nil
+}
+
+// decodeList decodes raw, a SEQUENCE OF value with components of type t.
+func (d *ASNDefinition) decodeList(t ASNType, raw *asn1.RawValue) (*Value, error) {
+	if !raw.Constructed {
+		return nil, fmt.Errorf("value: primitive %s, expected constructed value", raw.Tag)
+	}
+
+	children, err := raw.ChildValues()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Value{
+		Kind:     ListValue,
+		Elements: []*Value{},
+	}
+
+	for _, child := range children {
+		element, err := d.decodeValue(t, child)
+		if err != nil {
+			return nil, err
+		}
+
+		v.Elements = append(v.Elements, element)
+	}
+
+	return v, nil
+}
+
+// stringValue is a character string type of the asn1 package.
+type stringValue interface {
+	asn1.Unmarshaler
+	String() string
+}
+
+// stringValues returns a new value of the asn1 package for the character
+// string types by their universal tag.
+var stringValues = map[asn1.ASNValue]func() stringValue{
+	asn1.TagObjectDescriptor: func() stringValue { return new(asn1.ObjectDescriptor) },
+	asn1.TagUTF8String:       func() stringValue { return new(asn1.UTF8String) },
+	asn1.TagNumericString:    func() stringValue { return new(asn1.NumericString) },
+	asn1.TagPrintableString:  func() stringValue { return new(asn1.PrintableString) },
+	asn1.TagT61String:        func() stringValue { return new(asn1.T61String) },
+	asn1.TagIA5String:        func() stringValue { return new(asn1.IA5String) },
+	asn1.TagGraphicString:    func() stringValue { return new(asn1.GraphicString) },
+	asn1.TagVisibleString:    func() stringValue { return new(asn1.VisibleString) },
+	asn1.TagGeneralString:    func() stringValue { return new(asn1.GeneralString) },
+}
+
+// decodePrimitive returns the value of raw, a value of the simple builtin
+// type t with the universal tag value, and the identifier of the value of
+// enumerations.
+func (d *ASNDefinition) decodePrimitive(t ASNType, value asn1.ASNValue, raw *asn1.RawValue) (interface{}, string, error) {
+	switch v := t.(type) {
+	case *ASNInteger:
+		var i asn1.BigInteger
+		if err := i.UnmarshalRawValue(raw); err != nil {
+			return nil, "", err
+		}
+
+		n := i.BigInt()
+		if !n.IsInt64() {
+			return n, "", nil
+		}
+
+		name, _, err := v.NameOf(n.Int64())
+		return n.Int64(), name, err
+	case *ASNEnumerated:
+		var e asn1.Enumerated
+		if err := e.UnmarshalRawValue(raw); err != nil {
+			return nil, "", err
+		}
+
+		name, ok, err := v.NameOf(e.Int64())
+		if err == nil && !ok && !v.Extensible {
+			err = fmt.Errorf("unknown value %d of %s", e.Int64(), t.Name())
+		}
+
+		return e.Int64(), name, err
+	case *ASNBitString:
+		var b asn1.BitString
+		err := b.UnmarshalRawValue(raw)
+		return b, "", err
+	case *ASNOctetString:
+		var s asn1.OctetString
+		err := s.UnmarshalRawValue(raw)
+		return s.Bytes(), "", err
+	case *ASNObjectIdentifier:
+		var oid asn1.ObjectIdentifier
+		err := oid.UnmarshalRawValue(raw)
+		return oid, "", err
+	case *ASNUTCTime:
+		var s asn1.UTCTime
+		if err := s.UnmarshalRawValue(raw); err != nil {
+			return nil, "", err
+		}
+
+		tm, err := s.Time()
+		return tm, "", err
+	case *ASNGeneralizedTime:
+		var s asn1.GeneralizedTime
+		if err := s.UnmarshalRawValue(raw); err != nil {
+			return nil, "", err
+		}
+
+		tm, err := s.Time()
+		return tm, "", err
+	}
+
+	switch value {
+	case asn1.TagBoolean:
+		var b asn1.Bool
+		err := b.UnmarshalRawValue(raw)
+		return b.Bool(), "", err
+	case asn1.TagNull:
+		var n asn1.Null
+		err := n.UnmarshalRawValue(raw)
+		return nil, "", err
+	case asn1.TagReal:
+		var r asn1.Real
+		err := r.UnmarshalRawValue(raw)
+		return r.Float64(), "", err
+	}
+
+	if f, ok := stringValues[value]; ok {
+		s := f()
+		err := s.UnmarshalRawValue(raw)
+		return s.String(), "", err
+	}
+
+	return nil, "", fmt.Errorf("unsupported type %s", t.Name())
+}
+
+// defaultValue returns the DEFAULT value of item, see ASNItem.Default.
+func (d *ASNDefinition) defaultValue(item ASNItem) (*Value, error) {
+	t := d.Resolve(item.Type)
+
+	v := &Value{
+		Kind: PrimitiveValue,
+		Type: item.Type,
+	}
+
+	var enum *ASNEnum
+	switch e := t.(type) {
+	case *ASNInteger:
+		enum = &e.ASNEnum
+	case *ASNEnumerated:
+		enum = &e.ASNEnum
+	case *ASNBitString:
+		enum = &e.ASNEnum
+	}
+
+	switch dv := item.Default.(type) {
+	case bool:
+		if value, ok := UniversalTag(t); !ok || value != asn1.TagBoolean {
+			break
+		}
+
+		v.Primitive = dv
+		return v, nil
+	case int64:
+		if _, ok := t.(*ASNInteger); !ok {
+			break
+		}
+
+		name, _, err := enum.NameOf(dv)
+		if err != nil {
+			return nil, fmt.Errorf("value: %s", err)
+		}
+
+		v.Primitive, v.Name = dv, name
+		return v, nil
+	case string:
+		if enum != nil {
+			_, values, err := enum.Numbers()
+			if err != nil {
+				return nil, fmt.Errorf("value: %s", err)
+			}
+
+			if n, ok := values[dv]; ok {
+				v.Primitive, v.Name = n, dv
+				return v, nil
+			}
+		}
+
+		// a value defined in the module, eg. defaultVersion INTEGER ::= 1
+		if _, ok := t.(*ASNInteger); ok {
+			if n, ok := d.Value(dv); ok {
+				v.Primitive = n
+				return v, nil
+			}
+		}
+	case []string:
+		if s, ok := t.(*ASNSequence); ok && s.Of != "" && len(dv) == 0 {
+			v.Kind, v.Elements = ListValue, []*Value{}
+			return v, nil
+		}
+
+		if enum == nil {
+			break
+		}
+
+		if _, ok := t.(*ASNBitString); !ok {
+			break
+		}
+
+		b, err := namedBits(enum, dv)
+		if err != nil {
+			return nil, fmt.Errorf("value: %s", err)
+		}
+
+		v.Primitive = b
+		return v, nil
+	}
+
+	return nil, fmt.Errorf("value: unsupported DEFAULT value %v of %s", item.Default, item.Name)
+}
+
+// namedBits returns the BIT STRING with the named bits set.
+func namedBits(e *ASNEnum, names []string) (asn1.BitString, error) {
+	_, values, err := e.Numbers()
+	if err != nil {
+		return asn1.BitString{}, err
+	}
+
+	positions := []int{}
+	length := 0
+
+	for _, name := range names {
+		n, ok := values[name]
+		if !ok || n < 0 {
+			return asn1.BitString{}, fmt.Errorf("unknown bit %s", name)
+		}
+
+		positions = append(positions, int(n))
+		if int(n) >= length {
+			length = int(n) + 1
+		}
+	}
+
+	b := asn1.BitString{
+		Bytes:     make([]byte, (length+7)/8),
+		BitLength: length,
+	}
+
+	for _, i := range positions {
+		b.Bytes[i/8] |= 0x80 >> uint(i%8)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,177 @@
+package asn1parser_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dutchsec/asn1/parser"
+)
+
+// formatValue returns a compact notation of v, DEFAULT values are marked
+// with an asterisk.
+func formatValue(v *asn1parser.Value) string {
+	switch v.Kind {
+	case asn1parser.RecordValue:
+		fields := []string{}
+		for _, f := range v.Fields {
+			s := f.Name + "=" + formatValue(f.Value)
+			if f.Default {
+				s += "*"
+			}
+
+			fields = append(fields, s)
+		}
+
+		return "{" + strings.Join(fields, ", ") + "}"
+	case asn1parser.ChoiceValue:
+		return v.Name + ":" + formatValue(v.Alternative)
+	case asn1parser.ListValue:
+		elements := []string{}
+		for _, e := range v.Elements {
+			elements = append(elements, formatValue(e))
+		}
+
+		return "[" + strings.Join(elements, " ") + "]"
+	}
+
+	if v.Name != "" {
+		return v.Name
+	}
+
+	return fmt.Sprint(v.Primitive)
+}
+
+func TestDefinition_Decode(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(`
+Test DEFINITIONS IMPLICIT TAGS ::=
+BEGIN
+
+Version ::= INTEGER { v1(0), v2(1) }
+
+Name ::= [APPLICATION 1] IA5String
+
+Level ::= ENUMERATED { low(0), high(1) }
+
+Target ::= CHOICE {
+	host [0] IA5String,
+	port [1] INTEGER
+}
+
+Ext ::= SEQUENCE {
+	a INTEGER,
+	...
+}
+
+Record ::= SEQUENCE {
+	version [0] EXPLICIT Version DEFAULT v1,
+	critical BOOLEAN DEFAULT FALSE,
+	level Level DEFAULT low,
+	name Name,
+	id [1] INTEGER OPTIONAL,
+	target [2] Target,
+	values SEQUENCE OF INTEGER
+}
+
+END
+`)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var tests = []struct {
+		in  string
+		out string
+		err bool
+	}{
+		{in: "3012 4103616263 a203800161 3006020101020102", out: "{version=v1*, critical=false*, level=low*, name=abc, target=host:a, values=[1 2]}"},
+		{in: "301a a003020101 0101ff 0a0101 4103616263 810105 a203810105 3000", out: "{version=v2, critical=true, level=high, name=abc, id=5, target=port:5, values=[]}"},
+		// indefinite length
+		{in: "3080 4103616263 a280800161 0000 3080020101 0000 0000", out: "{version=v1*, critical=false*, level=low*, name=abc, target=host:a, values=[1]}"},
+		// unexpected tag
+		{in: "3112 4103616263 a203800161 3006020101020102", err: true},
+		// missing name
+		{in: "300d a203800161 3006020101020102", err: true},
+		// unknown alternative
+		{in: "3012 4103616263 a203820161 3006020101020102", err: true},
+		// unknown value of level
+		{in: "3015 0a0102 4103616263 a203800161 3006020101020102", err: true},
+		// trailing octets
+		{in: "3012 4103616263 a203800161 3006020101020102 00", err: true},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(strings.Replace(tt.in, " ", "", -1))
+
+		v, err := def.Decode("Record", data)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%d. expected error, got %s", i, formatValue(v))
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if out := formatValue(v); out != tt.out {
+			t.Errorf("%d. output mismatch: exp=%s got=%s", i, tt.out, out)
+		}
+	}
+
+	// an extension addition that is not in the schema is left out
+	data, _ := hex.DecodeString("3006020101800100")
+
+	v, err := def.Decode("Ext", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if out := formatValue(v); out != "{a=1}" {
+		t.Errorf("output mismatch: exp={a=1} got=%s", out)
+	}
+}
+
+func TestValue_Field(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(`
+Test DEFINITIONS ::=
+BEGIN
+
+Record ::= SET {
+	name [0] IMPLICIT OCTET STRING,
+	count [1] INTEGER DEFAULT 3
+}
+
+END
+`)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, _ := hex.DecodeString("3108a103020105800161")
+
+	v, err := def.Decode("Record", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	name, ok := v.Field("name")
+	if !ok {
+		t.Fatalf("name missing")
+	}
+
+	if b, ok := name.Primitive.([]byte); !ok || string(b) != "a" {
+		t.Errorf("name mismatch: got=%#v", name.Primitive)
+	}
+
+	if count, ok := v.Field("count"); !ok || count.Primitive != int64(5) {
+		t.Errorf("count mismatch: got=%#v", count)
+	}
+
+	if _, ok := v.Field("other"); ok {
+		t.Errorf("unexpected field other")
+	}
+}
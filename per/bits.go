@@ -0,0 +1,130 @@
+package asn1per
+
+import (
+	"fmt"
+)
+
+// bitWriter writes a sequence of bits, most significant bit first.
+type bitWriter struct {
+	data []byte
+	n    uint
+}
+
+// writeBit appends a single bit.
+func (w *bitWriter) writeBit(b bool) {
+	if w.n%8 == 0 {
+		w.data = append(w.data, 0)
+	}
+
+	if b {
+		w.data[len(w.data)-1] |= 0x80 >> (w.n % 8)
+	}
+
+	w.n++
+}
+
+// writeBits appends the n least significant bits of v.
+func (w *bitWriter) writeBits(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		w.writeBit(v&(1<<uint(i)) != 0)
+	}
+}
+
+// writeBytes appends all bits of data.
+func (w *bitWriter) writeBytes(data []byte) {
+	if w.n%8 == 0 {
+		w.data = append(w.data, data...)
+		w.n += uint(len(data)) * 8
+		return
+	}
+
+	for _, b := range data {
+		w.writeBits(uint64(b), 8)
+	}
+}
+
+// align pads the written bits with zero bits to an octet boundary.
+func (w *bitWriter) align() {
+	w.n = uint(len(w.data)) * 8
+}
+
+// Bytes returns the written bits, padded to an octet boundary.
+func (w *bitWriter) Bytes() []byte {
+	return w.data
+}
+
+// bitReader reads a sequence of bits, most significant bit first.
+type bitReader struct {
+	data []byte
+	n    uint
+}
+
+// errUnexpectedEnd is returned when the data ends before a value is read
+// completely.
+var errUnexpectedEnd = fmt.Errorf("per: unexpected end of data")
+
+// readBit reads a single bit.
+func (r *bitReader) readBit() (bool, error) {
+	if r.n >= uint(len(r.data))*8 {
+		return false, errUnexpectedEnd
+	}
+
+	b := r.data[r.n/8]&(0x80>>(r.n%8)) != 0
+	r.n++
+
+	return b, nil
+}
+
+// readBits reads n bits, n is at most 64.
+func (r *bitReader) readBits(n int) (uint64, error) {
+	v := uint64(0)
+
+	for i := 0; i < n; i++ {
+		b, err := r.readBit()
+		if err != nil {
+			return 0, err
+		}
+
+		v <<= 1
+		if b {
+			v |= 1
+		}
+	}
+
+	return v, nil
+}
+
+// readBytes reads n octets.
+func (r *bitReader) readBytes(n uint64) ([]byte, error) {
+	if n > uint64(len(r.data))-uint64(r.n/8) {
+		return nil, errUnexpectedEnd
+	}
+
+	if r.n%8 == 0 {
+		data := append([]byte{}, r.data[r.n/8:r.n/8+uint(n)]...)
+		r.n += uint(n) * 8
+		return data, nil
+	}
+
+	data := make([]byte, n)
+	for i := range data {
+		v, err := r.readBits(8)
+		if err != nil {
+			return nil, err
+		}
+
+		data[i] = byte(v)
+	}
+
+	return data, nil
+}
+
+// align skips the bits up to the next octet boundary.
+func (r *bitReader) align() {
+	r.n = (r.n + 7) / 8 * 8
+}
+
+// remaining returns the number of unread bits.
+func (r *bitReader) remaining() uint {
+	return uint(len(r.data))*8 - r.n
+}
@@ -0,0 +1,702 @@
+package asn1per
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// decoder reads PER encoded values into BER encoded values.
+type decoder struct {
+	*Codec
+
+	r bitReader
+
+	// empty counts the decoded elements of SEQUENCE OF values that are
+	// encoded without bits, it is shared with the decoders of open types.
+	empty *int64
+}
+
+// align skips to an octet boundary in the ALIGNED variant.
+func (d *decoder) align() {
+	if d.aligned {
+		d.r.align()
+	}
+}
+
+// decodeConstrained decodes a constrained whole number in the range lb..ub
+// (X.691 11.5).
+func (d *decoder) decodeConstrained(lb, ub int64) (int64, error) {
+	if ub < lb {
+		return 0, fmt.Errorf("per: invalid range %d..%d", lb, ub)
+	}
+
+	rng := uint64(ub-lb) + 1
+
+	var n uint64
+	var err error
+
+	switch {
+	case rng == 1:
+	case !d.aligned || rng <= 255:
+		n, err = d.r.readBits(bits.Len64(rng - 1))
+	case rng == 256:
+		d.r.align()
+		n, err = d.r.readBits(8)
+	case rng <= 65536:
+		d.r.align()
+		n, err = d.r.readBits(16)
+	default:
+		var octets int64
+		if octets, err = d.decodeConstrained(1, int64(octetLen(rng-1))); err != nil {
+			return 0, err
+		}
+
+		d.r.align()
+		n, err = d.r.readBits(int(octets) * 8)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if n >= rng {
+		return 0, fmt.Errorf("per: value %d is not within %d..%d", int64(n)+lb, lb, ub)
+	}
+
+	return lb + int64(n), nil
+}
+
+// decodeLength decodes elements preceded by an unconstrained length
+// determinant (X.691 11.9.3.6 to 11.9.3.8), read is called to read the n
+// elements following a length determinant. Lengths of 16K and more are
+// encoded in fragments, the total number of elements is returned.
+func (d *decoder) decodeLength(read func(n int64) error) (int64, error) {
+	total := int64(0)
+
+	for {
+		d.align()
+
+		v, err := d.r.readBits(8)
+		if err != nil {
+			return 0, err
+		}
+
+		n := int64(v)
+		fragment := false
+
+		switch {
+		case v&0x80 == 0:
+		case v&0xc0 == 0x80:
+			w, err := d.r.readBits(8)
+			if err != nil {
+				return 0, err
+			}
+
+			n = int64(v&0x3f)<<8 | int64(w)
+		default:
+			if m := v & 0x3f; m < 1 || m > 4 {
+				return 0, fmt.Errorf("per: invalid fragment of %d times 16K", m)
+			}
+
+			n = int64(v&0x3f) * fragmentSize
+			fragment = true
+		}
+
+		if err := read(n); err != nil {
+			return 0, err
+		}
+
+		total += n
+
+		if !fragment {
+			return total, nil
+		}
+	}
+}
+
+// decodeOctets decodes octets preceded by an unconstrained length.
+func (d *decoder) decodeOctets() ([]byte, error) {
+	data := []byte{}
+
+	_, err := d.decodeLength(func(n int64) error {
+		d.align()
+
+		octets, err := d.r.readBytes(uint64(n))
+		data = append(data, octets...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// decodeSmall decodes a normally small non-negative whole number (X.691
+// 11.6).
+func (d *decoder) decodeSmall() (int64, error) {
+	large, err := d.r.readBit()
+	if err != nil {
+		return 0, err
+	}
+
+	if !large {
+		v, err := d.r.readBits(6)
+		return int64(v), err
+	}
+
+	data, err := d.decodeOctets()
+	if err != nil {
+		return 0, err
+	}
+
+	n := new(big.Int).SetBytes(data)
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("per: number too large")
+	}
+
+	return n.Int64(), nil
+}
+
+// decodeOpenType decodes a value encoded as an open type (X.691 11.2), f
+// reads the value from the contained encoding.
+func (d *decoder) decodeOpenType(f func(sub *decoder) (*asn1.RawValue, error)) (*asn1.RawValue, error) {
+	data, err := d.decodeOctets()
+	if err != nil {
+		return nil, err
+	}
+
+	return f(&decoder{
+		Codec: d.Codec,
+		r: bitReader{
+			data: data,
+		},
+		empty: d.empty,
+	})
+}
+
+// decodeItem decodes the value of the i-th item of a SEQUENCE, SET or
+// CHOICE.
+func (d *decoder) decodeItem(items []asn1parser.ASNItem, i int) (*asn1.RawValue, error) {
+	raw, err := d.decodeBuiltin(items[i].Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.def.TagItem(items, i, raw)
+}
+
+// decodeType decodes a value of type t.
+func (d *decoder) decodeType(t asn1parser.ASNType) (*asn1.RawValue, error) {
+	raw, err := d.decodeBuiltin(t)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.def.TagType(t, raw)
+}
+
+// decodeBuiltin decodes a value of the builtin type t refers to.
+func (d *decoder) decodeBuiltin(t asn1parser.ASNType) (*asn1.RawValue, error) {
+	builtin := d.def.Resolve(t)
+
+	// the choice value is the value of the alternative
+	if v, ok := builtin.(*asn1parser.ASNChoice); ok {
+		return d.decodeChoice(v)
+	}
+
+	value, ok := asn1parser.UniversalTag(builtin)
+	if !ok {
+		return nil, fmt.Errorf("per: unsupported type %s", builtin.Name())
+	}
+
+	raw := &asn1.RawValue{
+		Tag: asn1.Tag(asn1.ClassUniversal, value),
+	}
+
+	var err error
+
+	switch v := builtin.(type) {
+	case *asn1parser.ASNSequence:
+		raw.Constructed = true
+
+		if v.Of != "" {
+			raw.Content, err = d.decodeSequenceOf(t, v)
+		} else {
+			raw.Content, err = d.decodeSequence(v.Items, false)
+		}
+	case *asn1parser.ASNSet:
+		raw.Constructed = true
+		raw.Content, err = d.decodeSequence(v.Items, true)
+	case *asn1parser.ASNInteger:
+		raw.Content, err = d.decodeInteger(t)
+	case *asn1parser.ASNEnumerated:
+		raw.Content, err = d.decodeEnumerated(v)
+	case *asn1parser.ASNBitString:
+		raw.Content, err = d.decodeBitString(t)
+	case *asn1parser.ASNOctetString:
+		raw.Content, err = d.decodeOctetString(t)
+	case *asn1parser.ASNObjectIdentifier, *asn1parser.ASNObjectDescriptor, *asn1parser.ASNGraphicString,
+		*asn1parser.ASNGeneralString, *asn1parser.ASNT61String:
+		raw.Content, err = d.decodeOctets()
+	default:
+		switch value {
+		case asn1.TagBoolean:
+			var b bool
+			if b, err = d.r.readBit(); b {
+				raw.Content = []byte{0xff}
+			} else {
+				raw.Content = []byte{0x00}
+			}
+		case asn1.TagNull:
+			raw.Content = []byte{}
+		case asn1.TagReal, asn1.TagUTF8String:
+			raw.Content, err = d.decodeOctets()
+		default:
+			bits, alphabet, ok := d.knownMultiplier(builtin)
+			if !ok {
+				return nil, fmt.Errorf("per: unsupported type %s", builtin.Name())
+			}
+
+			raw.Content, err = d.decodeKnownMultiplier(t, bits, alphabet)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// decodeInteger decodes an INTEGER (X.691 13).
+func (d *decoder) decodeInteger(t asn1parser.ASNType) ([]byte, error) {
+	_, rng := d.def.Constraints(t)
+
+	b, err := d.bounds(rng)
+	if err != nil {
+		return nil, err
+	}
+
+	extended := false
+	if b.extensible {
+		if extended, err = d.r.readBit(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case extended || !b.hasLB:
+		data, err := d.decodeOctets()
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := asn1.ParseInteger(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return asn1.EncodeInteger(n), nil
+	case b.hasUB:
+		n, err := d.decodeConstrained(b.lb, b.ub)
+		if err != nil {
+			return nil, err
+		}
+
+		return asn1.EncodeInteger(big.NewInt(n)), nil
+	default:
+		// semi-constrained whole number
+		data, err := d.decodeOctets()
+		if err != nil {
+			return nil, err
+		}
+
+		n := new(big.Int).SetBytes(data)
+		return asn1.EncodeInteger(n.Add(n, big.NewInt(b.lb))), nil
+	}
+}
+
+// decodeEnumerated decodes an ENUMERATED (X.691 14).
+func (d *decoder) decodeEnumerated(t *asn1parser.ASNEnumerated) ([]byte, error) {
+	root, values, err := t.Numbers()
+	if err != nil {
+		return nil, err
+	}
+
+	extended := false
+	if t.Extensible || d.def.ExtensibilityImplied {
+		if extended, err = d.r.readBit(); err != nil {
+			return nil, err
+		}
+	}
+
+	if extended {
+		i, err := d.decodeSmall()
+		if err != nil {
+			return nil, err
+		}
+
+		if i >= int64(len(t.Additions)) {
+			return nil, fmt.Errorf("per: unknown enumeration addition %d", i)
+		}
+
+		return asn1.EncodeInteger(big.NewInt(values[t.Additions[i]])), nil
+	}
+
+	if len(root) == 0 {
+		return nil, fmt.Errorf("per: enumeration %s has no values", t.Name())
+	}
+
+	i, err := d.decodeConstrained(0, int64(len(root)-1))
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.EncodeInteger(big.NewInt(values[root[i]])), nil
+}
+
+// decodeSize decodes the number of elements of a value constrained by b.
+// Read is called to read the n elements, length is true when they follow a
+// length determinant, see decodeLength. The total number of elements is
+// returned.
+func (d *decoder) decodeSize(b bounds, read func(n int64, length bool) error) (int64, error) {
+	extended := false
+	if b.extensible {
+		var err error
+		if extended, err = d.r.readBit(); err != nil {
+			return 0, err
+		}
+	}
+
+	if !extended && b.hasUB && b.ub < 65536 {
+		if b.fixed() {
+			return b.ub, read(b.ub, false)
+		}
+
+		n, err := d.decodeConstrained(b.lb, b.ub)
+		if err != nil {
+			return 0, err
+		}
+
+		return n, read(n, true)
+	}
+
+	return d.decodeLength(func(n int64) error {
+		return read(n, true)
+	})
+}
+
+// decodeBitString decodes a BIT STRING (X.691 16).
+func (d *decoder) decodeBitString(t asn1parser.ASNType) ([]byte, error) {
+	b, err := d.sizeBounds(t)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []byte{0x00}
+	count := int64(0)
+
+	_, err = d.decodeSize(b, func(n int64, length bool) error {
+		if length && n > 0 || !length && n > 16 {
+			d.align()
+		}
+
+		if uint64(n) > uint64(d.r.remaining()) {
+			return errUnexpectedEnd
+		}
+
+		for i := int64(0); i < n; i++ {
+			bit, err := d.r.readBit()
+			if err != nil {
+				return err
+			}
+
+			if count%8 == 0 {
+				data = append(data, 0x00)
+			}
+
+			if bit {
+				data[len(data)-1] |= 0x80 >> uint(count%8)
+			}
+
+			count++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data[0] = byte((8 - count%8) % 8)
+	return data, nil
+}
+
+// decodeOctetString decodes an OCTET STRING (X.691 17).
+func (d *decoder) decodeOctetString(t asn1parser.ASNType) ([]byte, error) {
+	b, err := d.sizeBounds(t)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []byte{}
+
+	_, err = d.decodeSize(b, func(n int64, length bool) error {
+		if length && n > 0 || !length && n > 2 {
+			d.align()
+		}
+
+		octets, err := d.r.readBytes(uint64(n))
+		data = append(data, octets...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// decodeKnownMultiplier decodes a known-multiplier character string (X.691
+// 30.5).
+func (d *decoder) decodeKnownMultiplier(t asn1parser.ASNType, bits int, alphabet string) ([]byte, error) {
+	b, err := d.sizeBounds(t)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []byte{}
+
+	_, err = d.decodeSize(b, func(n int64, length bool) error {
+		if length && n > 0 || !length && n*int64(bits) > 16 {
+			d.align()
+		}
+
+		if uint64(n)*uint64(bits) > uint64(d.r.remaining()) {
+			return errUnexpectedEnd
+		}
+
+		for i := int64(0); i < n; i++ {
+			v, err := d.r.readBits(bits)
+			if err != nil {
+				return err
+			}
+
+			if alphabet != "" {
+				if v >= uint64(len(alphabet)) {
+					return fmt.Errorf("per: invalid character index %d", v)
+				}
+
+				v = uint64(alphabet[v])
+			}
+
+			data = append(data, byte(v))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// decodeSequenceOf decodes a SEQUENCE OF (X.691 20).
+func (d *decoder) decodeSequenceOf(t asn1parser.ASNType, s *asn1parser.ASNSequence) ([]byte, error) {
+	b, err := d.sizeBounds(t)
+	if err != nil {
+		return nil, err
+	}
+
+	element := d.def.ElementType(s)
+
+	content := []byte{}
+
+	_, err = d.decodeSize(b, func(n int64, length bool) error {
+		// every element is encoded in at least one bit, except for the
+		// elements of types without content such as NULL, which are
+		// limited to maxEmptyElements.
+		if uint64(n) > uint64(d.r.remaining()) && n > maxEmptyElements-*d.empty {
+			return fmt.Errorf("per: %d elements exceed the remaining %d bits", n, d.r.remaining())
+		}
+
+		for i := int64(0); i < n; i++ {
+			before := d.r.remaining()
+
+			value, err := d.decodeType(element)
+			if err != nil {
+				return err
+			}
+
+			if d.r.remaining() == before {
+				if *d.empty++; *d.empty > maxEmptyElements {
+					return fmt.Errorf("per: more than %d empty elements", maxEmptyElements)
+				}
+			}
+
+			data, err := value.Encode()
+			if err != nil {
+				return err
+			}
+
+			content = append(content, data...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// decodeSequence decodes a SEQUENCE or SET (X.691 19 and 21).
+func (d *decoder) decodeSequence(items []asn1parser.ASNItem, set bool) ([]byte, error) {
+	root, additions, extensible := d.def.Components(items)
+	if set {
+		d.def.SortByTag(items, root)
+	}
+
+	extended := false
+	if extensible {
+		var err error
+		if extended, err = d.r.readBit(); err != nil {
+			return nil, err
+		}
+	}
+
+	// preamble with the presence of OPTIONAL and DEFAULT components
+	present := map[int]bool{}
+	for _, i := range root {
+		if !items[i].Optional && items[i].Default == nil {
+			present[i] = true
+			continue
+		}
+
+		bit, err := d.r.readBit()
+		if err != nil {
+			return nil, err
+		}
+
+		present[i] = bit
+	}
+
+	values := map[int]*asn1.RawValue{}
+	for _, i := range root {
+		if !present[i] {
+			continue
+		}
+
+		value, err := d.decodeItem(items, i)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = value
+	}
+
+	if extended {
+		n, err := d.decodeSmall()
+		if err != nil {
+			return nil, err
+		}
+
+		bitmap := make([]bool, n+1)
+		for j := range bitmap {
+			if bitmap[j], err = d.r.readBit(); err != nil {
+				return nil, err
+			}
+		}
+
+		for j, bit := range bitmap {
+			if !bit {
+				continue
+			}
+
+			// additions unknown to the schema are skipped
+			if j >= len(additions) {
+				if _, err := d.decodeOctets(); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			i := additions[j]
+
+			value, err := d.decodeOpenType(func(sub *decoder) (*asn1.RawValue, error) {
+				return sub.decodeItem(items, i)
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			values[i] = value
+		}
+	}
+
+	// the components are in the order of the schema
+	content := []byte{}
+	for i := range items {
+		if values[i] == nil {
+			continue
+		}
+
+		data, err := values[i].Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		content = append(content, data...)
+	}
+
+	return content, nil
+}
+
+// decodeChoice decodes a CHOICE (X.691 23).
+func (d *decoder) decodeChoice(c *asn1parser.ASNChoice) (*asn1.RawValue, error) {
+	root, additions, extensible := d.def.Components(c.Items)
+	d.def.SortByTag(c.Items, root)
+	d.def.SortByTag(c.Items, additions)
+
+	extended := false
+	if extensible {
+		var err error
+		if extended, err = d.r.readBit(); err != nil {
+			return nil, err
+		}
+	}
+
+	if !extended {
+		if len(root) == 0 {
+			return nil, fmt.Errorf("per: choice %s has no alternatives", c.Name())
+		}
+
+		index, err := d.decodeConstrained(0, int64(len(root)-1))
+		if err != nil {
+			return nil, err
+		}
+
+		return d.decodeItem(c.Items, root[index])
+	}
+
+	index, err := d.decodeSmall()
+	if err != nil {
+		return nil, err
+	}
+
+	if index >= int64(len(additions)) {
+		return nil, fmt.Errorf("per: unknown alternative %d of %s", index, c.Name())
+	}
+
+	return d.decodeOpenType(func(sub *decoder) (*asn1.RawValue, error) {
+		return sub.decodeItem(c.Items, additions[index])
+	})
+}
@@ -0,0 +1,549 @@
+package asn1per
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// encoder writes the PER encoding of BER encoded values.
+type encoder struct {
+	*Codec
+
+	w bitWriter
+}
+
+// Bytes returns the complete encoding, an empty encoding is a single zero
+// octet (X.691 11.1).
+func (e *encoder) Bytes() []byte {
+	if len(e.w.Bytes()) == 0 {
+		return []byte{0x00}
+	}
+
+	return e.w.Bytes()
+}
+
+// align pads to an octet boundary in the ALIGNED variant.
+func (e *encoder) align() {
+	if e.aligned {
+		e.w.align()
+	}
+}
+
+// encodeConstrained encodes v as a constrained whole number in the range
+// lb..ub (X.691 11.5).
+func (e *encoder) encodeConstrained(v, lb, ub int64) error {
+	if v < lb || v > ub {
+		return fmt.Errorf("per: value %d is not within %d..%d", v, lb, ub)
+	}
+
+	rng := uint64(ub-lb) + 1
+	n := uint64(v - lb)
+
+	if rng == 1 {
+		return nil
+	}
+
+	if !e.aligned {
+		e.w.writeBits(n, bits.Len64(rng-1))
+		return nil
+	}
+
+	switch {
+	case rng <= 255:
+		e.w.writeBits(n, bits.Len64(rng-1))
+	case rng == 256:
+		e.w.align()
+		e.w.writeBits(n, 8)
+	case rng <= 65536:
+		e.w.align()
+		e.w.writeBits(n, 16)
+	default:
+		// the number of octets is encoded as a constrained whole number
+		octets := octetLen(n)
+		if err := e.encodeConstrained(int64(octets), 1, int64(octetLen(rng-1))); err != nil {
+			return err
+		}
+
+		e.w.align()
+		e.w.writeBits(n, octets*8)
+	}
+
+	return nil
+}
+
+// octetLen returns the minimum number of octets needed for n.
+func octetLen(n uint64) int {
+	if n == 0 {
+		return 1
+	}
+
+	return (bits.Len64(n) + 7) / 8
+}
+
+// encodeLength encodes n elements preceded by an unconstrained length
+// determinant (X.691 11.9.3.6 and 11.9.3.7), write is called to write the
+// elements from..to following a length determinant. Lengths of 16K and more
+// are encoded in fragments of 16K, 32K, 48K or 64K elements (X.691
+// 11.9.3.8), each preceded by its own length determinant.
+func (e *encoder) encodeLength(n int64, write func(from, to int64) error) error {
+	for from := int64(0); ; {
+		e.align()
+
+		switch rest := n - from; {
+		case rest < 128:
+			e.w.writeBits(uint64(rest), 8)
+			return write(from, n)
+		case rest < fragmentSize:
+			e.w.writeBits(0x8000|uint64(rest), 16)
+			return write(from, n)
+		default:
+			m := rest / fragmentSize
+			if m > 4 {
+				m = 4
+			}
+
+			e.w.writeBits(0xc0|uint64(m), 8)
+
+			if err := write(from, from+m*fragmentSize); err != nil {
+				return err
+			}
+
+			from += m * fragmentSize
+		}
+	}
+}
+
+// encodeOctets encodes data preceded by an unconstrained length.
+func (e *encoder) encodeOctets(data []byte) error {
+	return e.encodeLength(int64(len(data)), func(from, to int64) error {
+		e.align()
+		e.w.writeBytes(data[from:to])
+		return nil
+	})
+}
+
+// encodeSmall encodes a normally small non-negative whole number (X.691
+// 11.6).
+func (e *encoder) encodeSmall(n int64) error {
+	if n <= 63 {
+		e.w.writeBit(false)
+		e.w.writeBits(uint64(n), 6)
+		return nil
+	}
+
+	e.w.writeBit(true)
+	return e.encodeOctets(big.NewInt(n).Bytes())
+}
+
+// encodeOpenType encodes the complete encoding of a value as an open type
+// (X.691 11.2), f writes the value.
+func (e *encoder) encodeOpenType(f func(sub *encoder) error) error {
+	sub := &encoder{
+		Codec: e.Codec,
+	}
+
+	if err := f(sub); err != nil {
+		return err
+	}
+
+	return e.encodeOctets(sub.Bytes())
+}
+
+// encodeItem encodes raw, the value of the i-th item of a SEQUENCE, SET or
+// CHOICE.
+func (e *encoder) encodeItem(items []asn1parser.ASNItem, i int, raw *asn1.RawValue) error {
+	raw, err := e.def.UntagItem(items, i, raw)
+	if err != nil {
+		return fmt.Errorf("per: %s", err)
+	}
+
+	return e.encodeBuiltin(items[i].Type, raw)
+}
+
+// encodeType encodes raw, a value of type t.
+func (e *encoder) encodeType(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	raw, err := e.def.UntagType(t, raw)
+	if err != nil {
+		return fmt.Errorf("per: %s", err)
+	}
+
+	return e.encodeBuiltin(t, raw)
+}
+
+// encodeBuiltin encodes raw, a value of type t with the tags of t removed.
+func (e *encoder) encodeBuiltin(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	builtin := e.def.Resolve(t)
+
+	switch v := builtin.(type) {
+	case *asn1parser.ASNSequence:
+		if v.Of != "" {
+			return e.encodeSequenceOf(t, v, raw)
+		}
+
+		return e.encodeSequence(v.Items, raw, false)
+	case *asn1parser.ASNSet:
+		return e.encodeSequence(v.Items, raw, true)
+	case *asn1parser.ASNChoice:
+		return e.encodeChoice(v, raw)
+	case *asn1parser.ASNInteger:
+		return e.encodeInteger(t, raw)
+	case *asn1parser.ASNEnumerated:
+		return e.encodeEnumerated(v, raw)
+	case *asn1parser.ASNBitString:
+		return e.encodeBitString(t, raw)
+	case *asn1parser.ASNOctetString:
+		return e.encodeOctetString(t, raw)
+	case *asn1parser.ASNObjectIdentifier, *asn1parser.ASNObjectDescriptor, *asn1parser.ASNGraphicString,
+		*asn1parser.ASNGeneralString, *asn1parser.ASNT61String:
+		return e.encodeOctets(raw.Content)
+	case *asn1parser.ASNCustom:
+		switch v.Type {
+		case "BOOLEAN":
+			if len(raw.Content) != 1 {
+				return fmt.Errorf("per: invalid BOOLEAN length: %d", len(raw.Content))
+			}
+
+			e.w.writeBit(raw.Content[0] != 0x00)
+			return nil
+		case "NULL":
+			return nil
+		case "REAL", "UTF8String":
+			return e.encodeOctets(raw.Content)
+		}
+	}
+
+	if bits, alphabet, ok := e.knownMultiplier(builtin); ok {
+		return e.encodeKnownMultiplier(t, raw, bits, alphabet)
+	}
+
+	return fmt.Errorf("per: unsupported type %s", builtin.Name())
+}
+
+// encodeInteger encodes an INTEGER (X.691 13).
+func (e *encoder) encodeInteger(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	_, rng := e.def.Constraints(t)
+
+	b, err := e.bounds(rng)
+	if err != nil {
+		return err
+	}
+
+	n, err := asn1.ParseInteger(raw.Content)
+	if err != nil {
+		return err
+	}
+
+	inRoot := n.IsInt64() && b.contains(n.Int64())
+
+	if b.extensible {
+		e.w.writeBit(!inRoot)
+	} else if !inRoot {
+		return fmt.Errorf("per: value %s is not within the constraint", n)
+	}
+
+	switch {
+	case !inRoot || !b.hasLB:
+		return e.encodeOctets(asn1.EncodeInteger(n))
+	case b.hasUB:
+		return e.encodeConstrained(n.Int64(), b.lb, b.ub)
+	default:
+		// semi-constrained whole number
+		offset := new(big.Int).Sub(n, big.NewInt(b.lb))
+		if offset.Sign() == 0 {
+			return e.encodeOctets([]byte{0x00})
+		}
+
+		return e.encodeOctets(offset.Bytes())
+	}
+}
+
+// encodeEnumerated encodes an ENUMERATED (X.691 14).
+func (e *encoder) encodeEnumerated(t *asn1parser.ASNEnumerated, raw *asn1.RawValue) error {
+	root, values, err := t.Numbers()
+	if err != nil {
+		return err
+	}
+
+	n, err := asn1.ParseInteger(raw.Content)
+	if err != nil {
+		return err
+	}
+
+	extensible := t.Extensible || e.def.ExtensibilityImplied
+
+	for i, name := range root {
+		if n.IsInt64() && values[name] == n.Int64() {
+			if extensible {
+				e.w.writeBit(false)
+			}
+
+			return e.encodeConstrained(int64(i), 0, int64(len(root)-1))
+		}
+	}
+
+	for i, name := range t.Additions {
+		if n.IsInt64() && values[name] == n.Int64() {
+			e.w.writeBit(true)
+			return e.encodeSmall(int64(i))
+		}
+	}
+
+	return fmt.Errorf("per: unknown enumeration value %s", n)
+}
+
+// encodeSize encodes the number of elements n of a value constrained by b,
+// the extension bit is written for extensible constraints. Write is called
+// to write the elements from..to, length is true when they follow a length
+// determinant, see encodeLength.
+func (e *encoder) encodeSize(n int64, b bounds, write func(from, to int64, length bool) error) error {
+	inRoot := b.contains(n)
+
+	if b.extensible {
+		e.w.writeBit(!inRoot)
+	} else if !inRoot {
+		return fmt.Errorf("per: size %d is not within the constraint", n)
+	}
+
+	if inRoot && b.hasUB && b.ub < 65536 {
+		if b.fixed() {
+			return write(0, n, false)
+		}
+
+		if err := e.encodeConstrained(n, b.lb, b.ub); err != nil {
+			return err
+		}
+
+		return write(0, n, true)
+	}
+
+	return e.encodeLength(n, func(from, to int64) error {
+		return write(from, to, true)
+	})
+}
+
+// encodeBitString encodes a BIT STRING (X.691 16).
+func (e *encoder) encodeBitString(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	b, err := e.sizeBounds(t)
+	if err != nil {
+		return err
+	}
+
+	if len(raw.Content) == 0 {
+		return fmt.Errorf("per: zero length BIT STRING")
+	}
+
+	n := int64(len(raw.Content)-1)*8 - int64(raw.Content[0])
+	data := raw.Content[1:]
+
+	return e.encodeSize(n, b, func(from, to int64, length bool) error {
+		if length && to > from || !length && n > 16 {
+			e.align()
+		}
+
+		for i := from; i < to; i++ {
+			e.w.writeBit(data[i/8]&(0x80>>uint(i%8)) != 0)
+		}
+
+		return nil
+	})
+}
+
+// encodeOctetString encodes an OCTET STRING (X.691 17).
+func (e *encoder) encodeOctetString(t asn1parser.ASNType, raw *asn1.RawValue) error {
+	b, err := e.sizeBounds(t)
+	if err != nil {
+		return err
+	}
+
+	n := int64(len(raw.Content))
+
+	return e.encodeSize(n, b, func(from, to int64, length bool) error {
+		if length && to > from || !length && n > 2 {
+			e.align()
+		}
+
+		e.w.writeBytes(raw.Content[from:to])
+		return nil
+	})
+}
+
+// encodeKnownMultiplier encodes a known-multiplier character string (X.691
+// 30.5).
+func (e *encoder) encodeKnownMultiplier(t asn1parser.ASNType, raw *asn1.RawValue, bits int, alphabet string) error {
+	b, err := e.sizeBounds(t)
+	if err != nil {
+		return err
+	}
+
+	n := int64(len(raw.Content))
+
+	return e.encodeSize(n, b, func(from, to int64, length bool) error {
+		if length && to > from || !length && n*int64(bits) > 16 {
+			e.align()
+		}
+
+		for _, ch := range raw.Content[from:to] {
+			v := uint64(ch)
+
+			if alphabet != "" {
+				i := strings.IndexByte(alphabet, ch)
+				if i < 0 {
+					return fmt.Errorf("per: invalid character %q", ch)
+				}
+
+				v = uint64(i)
+			} else if ch > 0x7f {
+				return fmt.Errorf("per: invalid character %q", ch)
+			}
+
+			e.w.writeBits(v, bits)
+		}
+
+		return nil
+	})
+}
+
+// encodeSequenceOf encodes a SEQUENCE OF (X.691 20).
+func (e *encoder) encodeSequenceOf(t asn1parser.ASNType, s *asn1parser.ASNSequence, raw *asn1.RawValue) error {
+	b, err := e.sizeBounds(t)
+	if err != nil {
+		return err
+	}
+
+	values, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	element := e.def.ElementType(s)
+
+	return e.encodeSize(int64(len(values)), b, func(from, to int64, length bool) error {
+		for _, value := range values[from:to] {
+			if err := e.encodeType(element, value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// encodeSequence encodes a SEQUENCE or SET (X.691 19 and 21).
+func (e *encoder) encodeSequence(items []asn1parser.ASNItem, raw *asn1.RawValue, set bool) error {
+	values, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	present, err := e.def.MatchItems(items, values, set)
+	if err != nil {
+		return fmt.Errorf("per: %s", err)
+	}
+
+	root, additions, extensible := e.def.Components(items)
+	if set {
+		e.def.SortByTag(items, root)
+	}
+
+	extended := false
+	for _, i := range additions {
+		extended = extended || present[i] != nil
+	}
+
+	if extensible {
+		e.w.writeBit(extended)
+	}
+
+	// preamble with the presence of OPTIONAL and DEFAULT components
+	for _, i := range root {
+		if items[i].Optional || items[i].Default != nil {
+			e.w.writeBit(present[i] != nil)
+		}
+	}
+
+	for _, i := range root {
+		if present[i] == nil {
+			continue
+		}
+
+		if err := e.encodeItem(items, i, present[i]); err != nil {
+			return err
+		}
+	}
+
+	if !extended {
+		return nil
+	}
+
+	if err := e.encodeSmall(int64(len(additions) - 1)); err != nil {
+		return err
+	}
+
+	for _, i := range additions {
+		e.w.writeBit(present[i] != nil)
+	}
+
+	for _, i := range additions {
+		if present[i] == nil {
+			continue
+		}
+
+		i := i
+		if err := e.encodeOpenType(func(sub *encoder) error {
+			return sub.encodeItem(items, i, present[i])
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeChoice encodes a CHOICE (X.691 23).
+func (e *encoder) encodeChoice(c *asn1parser.ASNChoice, raw *asn1.RawValue) error {
+	root, additions, extensible := e.def.Components(c.Items)
+	e.def.SortByTag(c.Items, root)
+	e.def.SortByTag(c.Items, additions)
+
+	i, ok := e.def.MatchAlternative(c.Items, raw.Tag)
+	if !ok {
+		return fmt.Errorf("per: no alternative of %s matches %s", c.Name(), raw.Tag)
+	}
+
+	for index := range root {
+		if root[index] != i {
+			continue
+		}
+
+		if extensible {
+			e.w.writeBit(false)
+		}
+
+		if err := e.encodeConstrained(int64(index), 0, int64(len(root)-1)); err != nil {
+			return err
+		}
+
+		return e.encodeItem(c.Items, i, raw)
+	}
+
+	e.w.writeBit(true)
+
+	for index := range additions {
+		if additions[index] == i {
+			if err := e.encodeSmall(int64(index)); err != nil {
+				return err
+			}
+
+			break
+		}
+	}
+
+	return e.encodeOpenType(func(sub *encoder) error {
+		return sub.encodeItem(c.Items, i, raw)
+	})
+}
@@ -0,0 +1,196 @@
+// Package asn1per implements the Packed Encoding Rules (X.691). PER
+// encodings can only be decoded with knowledge of the schema, the codec
+// transcodes between PER and the BER encoded RawValue used by the asn1
+// package, using the types of a parsed asn1parser definition.
+package asn1per
+
+import (
+	"bytes"
+	"fmt"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// Variant is the variant of the Packed Encoding Rules.
+type Variant int
+
+const (
+	// Aligned is the ALIGNED variant of PER, fields are padded to octet
+	// boundaries.
+	Aligned Variant = iota
+	// Unaligned is the UNALIGNED variant of PER, fields use the minimum
+	// number of bits.
+	Unaligned
+)
+
+// fragmentSize is the unit of the fragments in which lengths of 16K and
+// more are encoded (X.691 11.9.3.8).
+const fragmentSize = 16384
+
+// maxEmptyElements is the maximum number of elements of SEQUENCE OF values
+// that are encoded without bits.
+const maxEmptyElements = 1 << 16
+
+// Codec encodes and decodes values of the types of a definition.
+type Codec struct {
+	def     *asn1parser.ASNDefinition
+	aligned bool
+}
+
+// NewCodec returns a new Codec for the types of def.
+func NewCodec(def *asn1parser.ASNDefinition, variant Variant) *Codec {
+	return &Codec{
+		def:     def,
+		aligned: variant == Aligned,
+	}
+}
+
+// Decode decodes data, the complete PER encoding of a value of the type
+// typeName, into the BER encoded value.
+func (c *Codec) Decode(typeName string, data []byte) (*asn1.RawValue, error) {
+	t := c.def.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("per: unknown type %s", typeName)
+	}
+
+	d := &decoder{
+		Codec: c,
+		r: bitReader{
+			data: data,
+		},
+		empty: new(int64),
+	}
+
+	raw, err := d.decodeType(t)
+	if err != nil {
+		return nil, err
+	}
+
+	d.r.align()
+
+	// X.691 11.1, an empty encoding is encoded as a single zero octet
+	if d.r.n == 0 && bytes.Equal(data, []byte{0x00}) {
+		return raw, nil
+	}
+
+	if rest := d.r.remaining() / 8; rest > 0 {
+		return nil, fmt.Errorf("per: %d trailing octets", rest)
+	}
+
+	return raw, nil
+}
+
+// Encode returns the complete PER encoding of raw, a BER encoded value of
+// the type typeName.
+func (c *Codec) Encode(typeName string, raw *asn1.RawValue) ([]byte, error) {
+	t := c.def.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("per: unknown type %s", typeName)
+	}
+
+	// reassemble segmented strings and indefinite lengths
+	raw, err := asn1.ToDER(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &encoder{
+		Codec: c,
+	}
+
+	if err := e.encodeType(t, raw); err != nil {
+		return nil, err
+	}
+
+	return e.Bytes(), nil
+}
+
+// bounds is a parsed value range or SIZE constraint.
+type bounds struct {
+	lb, ub       int64
+	hasLB, hasUB bool
+
+	extensible bool
+}
+
+// fixed returns true when the constraint allows a single value only.
+func (b bounds) fixed() bool {
+	return b.hasLB && b.hasUB && b.lb == b.ub
+}
+
+// contains returns true when v is within the bounds.
+func (b bounds) contains(v int64) bool {
+	return (!b.hasLB || v >= b.lb) && (!b.hasUB || v <= b.ub)
+}
+
+// bounds parses a constraint, a nil constraint is unbounded.
+func (c *Codec) bounds(r *asn1parser.ASNRange) (bounds, error) {
+	b := bounds{}
+	if r == nil {
+		return b, nil
+	}
+
+	b.extensible = r.Extensible
+
+	if r.From != "" && r.From != "MIN" {
+		v, ok := c.def.Value(r.From)
+		if !ok {
+			return b, fmt.Errorf("per: unknown value %s", r.From)
+		}
+
+		b.lb, b.hasLB = v, true
+	}
+
+	if r.To != "" && r.To != "MAX" {
+		v, ok := c.def.Value(r.To)
+		if !ok {
+			return b, fmt.Errorf("per: unknown value %s", r.To)
+		}
+
+		b.ub, b.hasUB = v, true
+	}
+
+	return b, nil
+}
+
+// sizeBounds returns the SIZE constraint of t, sizes have a lower bound of
+// zero.
+func (c *Codec) sizeBounds(t asn1parser.ASNType) (bounds, error) {
+	size, _ := c.def.Constraints(t)
+
+	b, err := c.bounds(size)
+	if err != nil {
+		return b, err
+	}
+
+	if !b.hasLB {
+		b.lb, b.hasLB = 0, true
+	}
+
+	return b, nil
+}
+
+// knownMultiplier returns the number of bits per character of the known
+// multiplier character string types and the alphabet of types that encode
+// characters as an index into their alphabet.
+func (c *Codec) knownMultiplier(t asn1parser.ASNType) (bits int, alphabet string, ok bool) {
+	switch v := t.(type) {
+	case *asn1parser.ASNNumericString:
+		return 4, " 0123456789", true
+	case *asn1parser.ASNPrintableString, *asn1parser.ASNVisibleString, *asn1parser.ASNGeneralizedTime, *asn1parser.ASNUTCTime:
+	case *asn1parser.ASNCustom:
+		if v.Type != "IA5String" {
+			return 0, "", false
+		}
+	default:
+		return 0, "", false
+	}
+
+	// X.691 30.5.3, the aligned variant rounds up to a power of two
+	if c.aligned {
+		return 8, "", true
+	}
+
+	return 7, "", true
+}
@@ -0,0 +1,283 @@
+package asn1per_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+	"github.com/dutchsec/asn1/per"
+)
+
+const scheme = `
+Test DEFINITIONS AUTOMATIC TAGS ::=
+BEGIN
+
+Byte ::= INTEGER (0..255)
+Small ::= INTEGER (0..7)
+Large ::= INTEGER (0..100000)
+Positive ::= INTEGER (1..MAX)
+Number ::= INTEGER
+Flag ::= BOOLEAN
+Nibble ::= BIT STRING (SIZE (4))
+Data ::= OCTET STRING
+Bits ::= BIT STRING
+Name ::= IA5String (SIZE (1..8))
+Digits ::= NumericString (SIZE (3))
+Color ::= ENUMERATED { red, green, blue }
+Shade ::= ENUMERATED { red, ..., yellow }
+
+Record ::= SEQUENCE {
+	enabled BOOLEAN,
+	level Small OPTIONAL
+}
+
+Extended ::= SEQUENCE {
+	level Small,
+	...,
+	enabled BOOLEAN
+}
+
+Choice ::= CHOICE {
+	level INTEGER (0..3),
+	enabled BOOLEAN
+}
+
+List ::= SEQUENCE (SIZE (1..4)) OF Level
+Levels ::= SEQUENCE OF Level
+
+Nulls ::= SEQUENCE (SIZE (0..65535)) OF NULL
+
+Nested ::= SEQUENCE (SIZE (2)) OF Nulls
+
+Level ::= INTEGER (0..3)
+
+END
+`
+
+func TestCodec(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var tests = []struct {
+		typ       string
+		ber       string
+		aligned   string
+		unaligned string
+	}{
+		{typ: "Byte", ber: "020105", aligned: "05", unaligned: "05"},
+		{typ: "Small", ber: "020105", aligned: "a0", unaligned: "a0"},
+		{typ: "Large", ber: "020101", aligned: "0001", unaligned: "000080"},
+		{typ: "Positive", ber: "02020100", aligned: "01ff", unaligned: "01ff"},
+		{typ: "Number", ber: "0201ff", aligned: "01ff", unaligned: "01ff"},
+		{typ: "Flag", ber: "0101ff", aligned: "80", unaligned: "80"},
+		{typ: "Nibble", ber: "030204a0", aligned: "a0", unaligned: "a0"},
+		{typ: "Data", ber: "04020102", aligned: "020102", unaligned: "020102"},
+		{typ: "Name", ber: "16026162", aligned: "206162", unaligned: "387100"},
+		{typ: "Digits", ber: "1203303139", aligned: "12a0", unaligned: "12a0"},
+		{typ: "Color", ber: "0a0101", aligned: "40", unaligned: "40"},
+		{typ: "Shade", ber: "0a0101", aligned: "80", unaligned: "80"},
+		{typ: "Record", ber: "30068001ff810103", aligned: "d8", unaligned: "d8"},
+		{typ: "Record", ber: "30038001ff", aligned: "40", unaligned: "40"},
+		{typ: "Extended", ber: "30068001018101ff", aligned: "90100180", unaligned: "90101800"},
+		{typ: "Choice", ber: "8101ff", aligned: "c0", unaligned: "c0"},
+		{typ: "List", ber: "3006020101020102", aligned: "58", unaligned: "58"},
+	}
+
+	for i, tt := range tests {
+		ber, _ := hex.DecodeString(tt.ber)
+
+		raw, err := asn1.DecodeRawValue(bytes.NewReader(ber))
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		for _, variant := range []struct {
+			variant asn1per.Variant
+			per     string
+		}{
+			{asn1per.Aligned, tt.aligned},
+			{asn1per.Unaligned, tt.unaligned},
+		} {
+			codec := asn1per.NewCodec(def, variant.variant)
+
+			data, err := codec.Encode(tt.typ, raw)
+			if err != nil {
+				t.Errorf("%d. %s: unexpected error: %s", i, tt.typ, err)
+				continue
+			}
+
+			if hex.EncodeToString(data) != variant.per {
+				t.Errorf("%d. %s: encoding mismatch: exp=%s got=%x", i, tt.typ, variant.per, data)
+			}
+
+			data, _ = hex.DecodeString(variant.per)
+
+			value, err := codec.Decode(tt.typ, data)
+			if err != nil {
+				t.Errorf("%d. %s: unexpected error: %s", i, tt.typ, err)
+				continue
+			}
+
+			out, err := value.Encode()
+			if err != nil {
+				t.Fatalf("%d. unexpected error: %s", i, err)
+			}
+
+			if hex.EncodeToString(out) != tt.ber {
+				t.Errorf("%d. %s: decoding mismatch: exp=%s got=%x", i, tt.typ, tt.ber, out)
+			}
+		}
+	}
+}
+
+func TestCodec_ExplicitTags(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(`
+Test DEFINITIONS ::=
+BEGIN
+
+Record ::= SEQUENCE {
+	enabled [0] BOOLEAN,
+	level [1] IMPLICIT INTEGER (0..7)
+}
+
+END
+`)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	codec := asn1per.NewCodec(def, asn1per.Aligned)
+
+	value, err := codec.Decode("Record", []byte{0xe0})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	out, err := value.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if exp := "3008a0030101ff810106"; hex.EncodeToString(out) != exp {
+		t.Errorf("decoding mismatch: exp=%s got=%x", exp, out)
+	}
+
+	data, err := codec.Encode("Record", value)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(data, []byte{0xe0}) {
+		t.Errorf("encoding mismatch: exp=e0 got=%x", data)
+	}
+}
+
+func TestCodec_UnknownExtension(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	codec := asn1per.NewCodec(def, asn1per.Aligned)
+
+	// an extension addition [2] that is not in the schema is left out
+	ber, _ := hex.DecodeString("30098001018101ff820100")
+
+	raw, err := asn1.DecodeRawValue(bytes.NewReader(ber))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := codec.Encode("Extended", raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if exp := "90100180"; hex.EncodeToString(data) != exp {
+		t.Errorf("encoding mismatch: exp=%s got=%x", exp, data)
+	}
+}
+
+func TestCodec_Fragments(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	codec := asn1per.NewCodec(def, asn1per.Aligned)
+
+	levels := bytes.Repeat([]byte{0x02, 0x01, 0x03}, 16400)
+
+	var tests = []struct {
+		typ     string
+		value   *asn1.RawValue
+		headers map[int]string
+		size    int
+	}{
+		{"Data", &asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagOctetString), Content: bytes.Repeat([]byte{0xab}, 16384)}, map[int]string{0: "c1", 16385: "00"}, 16386},
+		{"Data", &asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagOctetString), Content: bytes.Repeat([]byte{0xab}, 70000)}, map[int]string{0: "c4", 65537: "9170"}, 70003},
+		{"Bits", &asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagBitString), Content: append([]byte{0x04}, bytes.Repeat([]byte{0xf0}, 2050)...)}, map[int]string{0: "c1", 2049: "0c"}, 2052},
+		{"Levels", &asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagSequence), Constructed: true, Content: levels}, map[int]string{0: "c1", 4097: "10"}, 4102},
+	}
+
+	for i, tt := range tests {
+		data, err := codec.Encode(tt.typ, tt.value)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if len(data) != tt.size {
+			t.Errorf("%d. %s: size mismatch: exp=%d got=%d", i, tt.typ, tt.size, len(data))
+			continue
+		}
+
+		for offset, header := range tt.headers {
+			if got := hex.EncodeToString(data[offset : offset+len(header)/2]); got != header {
+				t.Errorf("%d. %s: length mismatch at %d: exp=%s got=%s", i, tt.typ, offset, header, got)
+			}
+		}
+
+		value, err := codec.Decode(tt.typ, data)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if !bytes.Equal(value.Content, tt.value.Content) {
+			t.Errorf("%d. %s: decoding mismatch", i, tt.typ)
+		}
+	}
+}
+
+func TestCodec_Errors(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	codec := asn1per.NewCodec(def, asn1per.Unaligned)
+
+	if _, err := codec.Encode("Small", &asn1.RawValue{Tag: asn1.Tag(asn1.ClassUniversal, asn1.TagInteger), Content: []byte{0x08}}); err == nil {
+		t.Errorf("expected error for value outside of the constraint")
+	}
+
+	if _, err := codec.Decode("Byte", []byte{}); err == nil {
+		t.Errorf("expected error for truncated data")
+	}
+
+	if _, err := codec.Decode("Levels", []byte{0xc4}); err == nil {
+		t.Errorf("expected error for more elements than remaining bits")
+	}
+
+	if _, err := codec.Decode("Nested", []byte{0xff, 0xff, 0xff, 0xff}); err == nil {
+		t.Errorf("expected error for too many empty elements")
+	}
+
+	if _, err := codec.Decode("Unknown", []byte{0x00}); err == nil {
+		t.Errorf("expected error for unknown type")
+	}
+}
@@ -0,0 +1,421 @@
+package asn1
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Real is a REAL value (X.690 8.5). Finite values are held as a big.Float, so
+// binary encoded values are decoded without rounding. The zero Real is plus
+// zero.
+type Real struct {
+	f   *big.Float
+	nan bool
+}
+
+// NewReal returns the Real of f. The special values infinity, NaN and minus
+// zero map to PLUS-INFINITY, MINUS-INFINITY, NOT-A-NUMBER and minus zero.
+func NewReal(f float64) Real {
+	if math.IsNaN(f) {
+		return Real{nan: true}
+	}
+
+	return Real{f: big.NewFloat(f)}
+}
+
+// NewBigReal returns the Real of f.
+func NewBigReal(f *big.Float) Real {
+	return Real{f: new(big.Float).Copy(f)}
+}
+
+// Float64 returns the float64 nearest to the value. Values too large for a
+// float64 are returned as an infinity.
+func (s Real) Float64() float64 {
+	if s.nan {
+		return math.NaN()
+	}
+
+	if s.f == nil {
+		return 0
+	}
+
+	f, _ := s.f.Float64()
+	return f
+}
+
+// BigFloat returns the value as a big.Float, nil for NOT-A-NUMBER.
+func (s Real) BigFloat() *big.Float {
+	if s.nan {
+		return nil
+	}
+
+	if s.f == nil {
+		return new(big.Float)
+	}
+
+	return new(big.Float).Copy(s.f)
+}
+
+// IsNaN returns true for the value NOT-A-NUMBER.
+func (s Real) IsNaN() bool {
+	return s.nan
+}
+
+// String returns the value in decimal notation, or the name of the special
+// value.
+func (s Real) String() string {
+	switch {
+	case s.nan:
+		return "NOT-A-NUMBER"
+	case s.f == nil:
+		return "0"
+	case s.f.IsInf() && s.f.Signbit():
+		return "MINUS-INFINITY"
+	case s.f.IsInf():
+		return "PLUS-INFINITY"
+	}
+
+	return s.f.Text('g', -1)
+}
+
+func (s *Real) UnmarshalRawValue(rv *RawValue) error {
+	r, err := decodeReal(rv.Content)
+	if err != nil {
+		return err
+	}
+
+	*s = r
+	return nil
+}
+
+// MarshalRawValue returns the REAL value, finite values other than zero use
+// the binary encoding with base 2 that is also valid CER and DER.
+func (s Real) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagReal),
+		Content: encodeReal(s),
+	}, nil
+}
+
+// FloatingPoint is a REAL value that is decoded into a float64.
+type FloatingPoint struct {
+	float64
+}
+
+// NewFloatingPoint returns the FloatingPoint of f.
+func NewFloatingPoint(f float64) FloatingPoint {
+	return FloatingPoint{f}
+}
+
+// Float64 returns the value.
+func (s FloatingPoint) Float64() float64 {
+	return s.float64
+}
+
+// UnmarshalRawValue decodes the REAL into the nearest float64, a
+// *ParseError is returned for values too large for a float64.
+func (s *FloatingPoint) UnmarshalRawValue(rv *RawValue) error {
+	f, err := decodeFloat64(rv.Content)
+	if err != nil {
+		return err
+	}
+
+	*s = FloatingPoint{f}
+	return nil
+}
+
+func (s FloatingPoint) MarshalRawValue() (*RawValue, error) {
+	return NewReal(s.float64).MarshalRawValue()
+}
+
+// Special REAL values (X.690 8.5.9).
+const (
+	realPlusInfinity  = 0x40
+	realMinusInfinity = 0x41
+	realNotANumber    = 0x42
+	realMinusZero     = 0x43
+)
+
+// decodeFloat64 decodes the content octets of a REAL into the nearest
+// float64.
+func decodeFloat64(data []byte) (float64, error) {
+	r, err := decodeReal(data)
+	if err != nil {
+		return 0, err
+	}
+
+	f := r.Float64()
+	if math.IsInf(f, 0) && !r.f.IsInf() {
+		return 0, kindError(ErrConstraint, "REAL %s overflows float64", r)
+	}
+
+	return f, nil
+}
+
+// decodeReal decodes the content octets of a REAL.
+func decodeReal(data []byte) (Real, error) {
+	if len(data) == 0 {
+		// X.690 8.5.2
+		return Real{}, nil
+	}
+
+	switch {
+	case data[0]&0x80 != 0:
+		return decodeBinaryReal(data)
+	case data[0]&0x40 != 0:
+		if len(data) != 1 {
+			return Real{}, kindError(ErrInvalidLength, "invalid special REAL length: %d", len(data))
+		}
+
+		switch data[0] {
+		case realPlusInfinity:
+			return Real{f: new(big.Float).SetInf(false)}, nil
+		case realMinusInfinity:
+			return Real{f: new(big.Float).SetInf(true)}, nil
+		case realNotANumber:
+			return Real{nan: true}, nil
+		case realMinusZero:
+			return Real{f: new(big.Float).Neg(new(big.Float))}, nil
+		}
+
+		return Real{}, parseError("invalid special REAL value: %x", data[0])
+	}
+
+	f, err := parseDecimal(data[0]&0x3f, string(data[1:]))
+	if err != nil {
+		return Real{}, err
+	}
+
+	return Real{f: f}, nil
+}
+
+// decodeBinaryReal decodes the binary encoding of a REAL (X.690 8.5.7), the
+// value is S × N × 2^F × B^E.
+func decodeBinaryReal(data []byte) (Real, error) {
+	first := data[0]
+
+	// bits per digit of the base
+	var bits int64
+	switch first >> 4 & 0x03 {
+	case 0:
+		bits = 1
+	case 1:
+		bits = 3
+	case 2:
+		bits = 4
+	default:
+		return Real{}, parseError("reserved REAL base")
+	}
+
+	scale := int64(first >> 2 & 0x03)
+
+	rest := data[1:]
+
+	n := int(first&0x03) + 1
+	if n == 4 {
+		if len(rest) == 0 || rest[0] == 0 {
+			return Real{}, kindError(ErrInvalidLength, "missing REAL exponent length")
+		}
+
+		n, rest = int(rest[0]), rest[1:]
+	}
+
+	if len(rest) <= n {
+		return Real{}, kindError(ErrInvalidLength, "REAL too short for exponent and mantissa")
+	}
+
+	exponent := parseBigInt(rest[:n])
+	if !exponent.IsInt64() || exponent.Int64() > math.MaxInt32 || exponent.Int64() < math.MinInt32 {
+		return Real{}, kindError(ErrConstraint, "REAL exponent %s out of range", exponent)
+	}
+
+	mantissa := new(big.Int).SetBytes(rest[n:])
+	exp := exponent.Int64()*bits + scale
+
+	f := new(big.Float).SetInt(mantissa)
+	if mantissa.Sign() != 0 {
+		if e := int64(mantissa.BitLen()) + exp; e > big.MaxExp || e < big.MinExp {
+			return Real{}, kindError(ErrConstraint, "REAL exponent %s out of range", exponent)
+		}
+
+		f.SetMantExp(f, int(exp))
+	}
+
+	if first&0x40 != 0 {
+		f.Neg(f)
+	}
+
+	return Real{f: f}, nil
+}
+
+// parseDecimal parses s, an ISO 6093 number in the NR1, NR2 or NR3 form
+// (X.690 8.5.8).
+func parseDecimal(form byte, s string) (*big.Float, error) {
+	if form < 1 || form > 3 {
+		return nil, parseError("invalid decimal REAL form: %d", form)
+	}
+
+	s = strings.TrimLeft(s, " ")
+
+	mantissa, exponent := s, "0"
+	if i := strings.IndexAny(s, "Ee"); i >= 0 {
+		mantissa, exponent = s[:i], s[i+1:]
+	}
+
+	if (form == 3) != (mantissa != s) {
+		return nil, parseError("invalid NR%d value %q", form, s)
+	}
+
+	sign := ""
+	if mantissa != "" && (mantissa[0] == '+' || mantissa[0] == '-') {
+		sign, mantissa = mantissa[:1], mantissa[1:]
+	}
+
+	digits, fraction := mantissa, ""
+	if i := strings.IndexAny(mantissa, ".,"); i >= 0 {
+		digits, fraction = mantissa[:i], mantissa[i+1:]
+	}
+
+	if form == 1 && digits != mantissa || form == 2 && digits == mantissa {
+		return nil, parseError("invalid NR%d value %q", form, s)
+	}
+
+	digits += fraction
+	if !isDigits(digits) {
+		return nil, parseError("invalid NR%d value %q", form, s)
+	}
+
+	if exponent != "" && (exponent[0] == '+' || exponent[0] == '-') && isDigits(exponent[1:]) {
+		exponent = strings.TrimPrefix(exponent, "+")
+	} else if !isDigits(exponent) {
+		return nil, parseError("invalid NR%d value %q", form, s)
+	}
+
+	exp, err := strconv.ParseInt(exponent, 10, 32)
+	if err != nil {
+		return nil, kindError(ErrConstraint, "REAL exponent %s out of range", exponent)
+	}
+
+	// the digits are an integer, scaled by the decimal exponent
+	prec := uint(4 * len(digits))
+	if prec < 64 {
+		prec = 64
+	}
+
+	f, ok := new(big.Float).SetPrec(prec).SetString(sign + digits + "e" + strconv.FormatInt(exp-int64(len(fraction)), 10))
+	if !ok || f.IsInf() {
+		return nil, kindError(ErrConstraint, "REAL %q out of range", s)
+	}
+
+	return f, nil
+}
+
+// isDigits returns true when s consists of one or more decimal digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// encodeReal returns the content octets of s. Finite values are encoded
+// with base 2 and an odd mantissa (X.690 11.3.1).
+func encodeReal(s Real) []byte {
+	switch {
+	case s.nan:
+		return []byte{realNotANumber}
+	case s.f == nil || s.f.Sign() == 0 && !s.f.Signbit():
+		return []byte{}
+	case s.f.Sign() == 0:
+		return []byte{realMinusZero}
+	case s.f.IsInf() && s.f.Signbit():
+		return []byte{realMinusInfinity}
+	case s.f.IsInf():
+		return []byte{realPlusInfinity}
+	}
+
+	x := new(big.Float).Abs(s.f)
+
+	// x = mantissa × 2^exp with 0.5 <= mantissa < 1, and prec significant
+	// bits in the mantissa
+	exp := x.MantExp(nil)
+	prec := int(x.MinPrec())
+
+	n, _ := new(big.Float).SetMantExp(x, prec-exp).Int(nil)
+	exponent := encodeInt64(int64(exp - prec))
+
+	first := byte(0x80)
+	if s.f.Signbit() {
+		first |= 0x40
+	}
+
+	data := []byte{first}
+	if len(exponent) <= 3 {
+		data[0] |= byte(len(exponent) - 1)
+	} else {
+		data[0] |= 0x03
+		data = append(data, byte(len(exponent)))
+	}
+
+	data = append(data, exponent...)
+	return append(data, n.Bytes()...)
+}
+
+// checkReal returns the violated rule when data is not a CER and DER
+// encoded REAL (X.690 11.3).
+func checkReal(data []byte) (string, string) {
+	if len(data) == 0 {
+		return "", ""
+	}
+
+	first := data[0]
+	switch {
+	case first&0x80 != 0:
+		if first&0x3c != 0 {
+			return "11.3.1", "does not use base 2 without scaling factor"
+		}
+
+		if data[len(data)-1]&0x01 == 0 {
+			return "11.3.1", "mantissa is not odd"
+		}
+	case first&0x40 != 0:
+		return "", ""
+	default:
+		if first != 0x03 || !isCanonicalNR3(string(data[1:])) {
+			return "11.3.2", "is not a canonical NR3 decimal"
+		}
+	}
+
+	return "", ""
+}
+
+// isCanonicalNR3 returns true when s is an NR3 number in the form required
+// by X.690 11.3.2, eg. "-12.E+0" or "1.E-5".
+func isCanonicalNR3(s string) bool {
+	i := strings.Index(s, ".E")
+	if i < 0 {
+		return false
+	}
+
+	mantissa, exponent := strings.TrimPrefix(s[:i], "-"), s[i+2:]
+
+	if !isDigits(mantissa) || mantissa[0] == '0' || mantissa[len(mantissa)-1] == '0' {
+		return false
+	}
+
+	if exponent == "+0" {
+		return true
+	}
+
+	exponent = strings.TrimPrefix(exponent, "-")
+	return isDigits(exponent) && exponent[0] != '0'
+}
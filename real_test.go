@@ -0,0 +1,197 @@
+package asn1_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestReal(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out string
+		f   float64
+		// canonical is set when the encoding of f equals in
+		canonical bool
+	}{
+		{in: "", out: "0", f: 0, canonical: true},
+		{in: "40", out: "PLUS-INFINITY", f: math.Inf(1), canonical: true},
+		{in: "41", out: "MINUS-INFINITY", f: math.Inf(-1), canonical: true},
+		{in: "42", out: "NOT-A-NUMBER", f: math.NaN(), canonical: true},
+		{in: "43", out: "-0", f: math.Copysign(0, -1), canonical: true},
+		{in: "800001", out: "1", f: 1, canonical: true},
+		{in: "80ff01", out: "0.5", f: 0.5, canonical: true},
+		{in: "c00003", out: "-3", f: -3, canonical: true},
+		{in: "800105", out: "10", f: 10, canonical: true},
+		{in: "8103e801", out: "1.071508607186267321e+301", f: math.Pow(2, 1000), canonical: true},
+		{in: "80000a", out: "10", f: 10},
+		{in: "900001", out: "1", f: 1},
+		{in: "900101", out: "8", f: 8},
+		{in: "a40101", out: "32", f: 32},
+		{in: "83010001", out: "1", f: 1},
+		{in: "01" + hex.EncodeToString([]byte("  123")), out: "123", f: 123},
+		{in: "02" + hex.EncodeToString([]byte("-1,5")), out: "-1.5", f: -1.5},
+		{in: "03" + hex.EncodeToString([]byte("15.E-1")), out: "1.5", f: 1.5},
+		{in: "03" + hex.EncodeToString([]byte("+2.5e2")), out: "250", f: 250},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString("09" + hex.EncodeToString([]byte{byte(len(tt.in) / 2)}) + tt.in)
+
+		var r asn1.Real
+		if err := asn1.Unmarshal(data, &r); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if r.String() != tt.out {
+			t.Errorf("%d. value mismatch: exp=%s got=%s", i, tt.out, r)
+		}
+
+		if f := r.Float64(); f != tt.f && !(math.IsNaN(f) && math.IsNaN(tt.f)) || math.Signbit(f) != math.Signbit(tt.f) {
+			t.Errorf("%d. float64 mismatch: exp=%g got=%g", i, tt.f, f)
+		}
+
+		if !tt.canonical {
+			continue
+		}
+
+		out, err := asn1.Marshal(asn1.NewReal(tt.f))
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+			t.Errorf("%d. encoding mismatch: exp=%x got=%x", i, data, out)
+		}
+
+		if err := asn1.UnmarshalDER(data, &r); err != nil {
+			t.Errorf("%d. unexpected DER error: %s", i, err)
+		}
+	}
+}
+
+func TestReal_Big(t *testing.T) {
+	// 3 × 2^-100000, far outside the range of a float64
+	data, _ := hex.DecodeString(stripSpaces("0905 82fe7960 03"))
+
+	var r asn1.Real
+	if err := asn1.Unmarshal(data, &r); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := new(big.Float).SetMantExp(big.NewFloat(3), -100000)
+	if r.BigFloat().Cmp(exp) != 0 {
+		t.Errorf("value mismatch: exp=%s got=%s", exp, r)
+	}
+
+	out, err := asn1.Marshal(asn1.NewBigReal(exp))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+		t.Errorf("encoding mismatch: exp=%x got=%x", data, out)
+	}
+
+	var f float64
+	if err := asn1.Unmarshal(data, &f); err != nil || f != 0 {
+		t.Errorf("expected underflow to zero, got %g (%v)", f, err)
+	}
+}
+
+func TestReal_Errors(t *testing.T) {
+	var tests = []struct {
+		in   string
+		kind error
+	}{
+		{in: "80", kind: asn1.ErrInvalidLength},
+		{in: "8000", kind: asn1.ErrInvalidLength},
+		{in: "8300", kind: asn1.ErrInvalidLength},
+		{in: "b00001"},
+		{in: "4000", kind: asn1.ErrInvalidLength},
+		{in: "44"},
+		{in: "04" + hex.EncodeToString([]byte("1"))},
+		{in: "01" + hex.EncodeToString([]byte("1.5"))},
+		{in: "02" + hex.EncodeToString([]byte("15"))},
+		{in: "03" + hex.EncodeToString([]byte("1.5"))},
+		{in: "03" + hex.EncodeToString([]byte("1.5E"))},
+		{in: "03" + hex.EncodeToString([]byte("1.5E99999999999"))},
+		{in: "83057fffffffff01", kind: asn1.ErrConstraint},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString("09" + hex.EncodeToString([]byte{byte(len(tt.in) / 2)}) + tt.in)
+
+		var r asn1.Real
+		err := asn1.Unmarshal(data, &r)
+		if _, ok := err.(*asn1.ParseError); !ok {
+			t.Errorf("%d. expected *ParseError, got %v", i, err)
+		} else if tt.kind != nil && !errors.Is(err, tt.kind) {
+			t.Errorf("%d. expected error of kind %v, got %v", i, tt.kind, err)
+		}
+	}
+}
+
+func TestReal_DER(t *testing.T) {
+	var tests = []struct {
+		in    string
+		valid bool
+	}{
+		{in: "800003", valid: true},
+		{in: "800002"},
+		{in: "900001"},
+		{in: "840001"},
+		{in: "03" + hex.EncodeToString([]byte("1.E+0")), valid: true},
+		{in: "03" + hex.EncodeToString([]byte("-15.E-3")), valid: true},
+		{in: "03" + hex.EncodeToString([]byte("10.E+0"))},
+		{in: "03" + hex.EncodeToString([]byte("1.E0"))},
+		{in: "03" + hex.EncodeToString([]byte("1.E-0"))},
+		{in: "03" + hex.EncodeToString([]byte("1.5E+1"))},
+		{in: "01" + hex.EncodeToString([]byte("1"))},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString("09" + hex.EncodeToString([]byte{byte(len(tt.in) / 2)}) + tt.in)
+
+		var r asn1.Real
+		err := asn1.UnmarshalDER(data, &r)
+		if tt.valid && err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if _, ok := err.(*asn1.DERError); !tt.valid && !ok {
+			t.Errorf("%d. expected *DERError, got %v", i, err)
+		}
+	}
+}
+
+func TestReal_Float(t *testing.T) {
+	type values struct {
+		F32 float32
+		F64 float64
+		FP  asn1.FloatingPoint
+	}
+
+	in := values{F32: -0.25, F64: 1e300, FP: asn1.NewFloatingPoint(3)}
+
+	data, err := asn1.Marshal(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var out values
+	if err := asn1.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if out != in {
+		t.Errorf("round trip mismatch: exp=%v got=%v", in, out)
+	}
+
+	// 2^1000 overflows a float32
+	data, _ = hex.DecodeString("09048103e801")
+
+	var f float32
+	if err := asn1.Unmarshal(data, &f); !errors.Is(err, asn1.ErrConstraint) {
+		t.Errorf("expected error of kind ErrConstraint, got %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package asn1
+
+import (
+	"unicode/utf8"
+)
+
+// t61Supplementary contains the characters of the supplementary set of T.61
+// that are encoded in a single octet.
+var t61Supplementary = map[byte]rune{
+	0xa1: '¡', 0xa2: '¢', 0xa3: '£', 0xa4: '$', 0xa5: '¥', 0xa6: '#', 0xa7: '§', 0xa8: '¤',
+	0xab: '«', 0xb0: '°', 0xb1: '±', 0xb2: '²', 0xb3: '³', 0xb4: '×', 0xb5: 'µ', 0xb6: '¶',
+	0xb7: '·', 0xb8: '÷', 0xbb: '»', 0xbc: '¼', 0xbd: '½', 0xbe: '¾', 0xbf: '¿',
+	0xe0: 'Ω', 0xe1: 'Æ', 0xe2: 'Đ', 0xe3: 'ª', 0xe4: 'Ħ', 0xe6: 'Ĳ', 0xe7: 'Ŀ', 0xe8: 'Ł',
+	0xe9: 'Ø', 0xea: 'Œ', 0xeb: 'º', 0xec: 'Þ', 0xed: 'Ŧ', 0xee: 'Ŋ', 0xef: 'ŉ',
+	0xf0: 'ĸ', 0xf1: 'æ', 0xf2: 'đ', 0xf3: 'ð', 0xf4: 'ħ', 0xf5: 'ı', 0xf6: 'ĳ', 0xf7: 'ŀ',
+	0xf8: 'ł', 0xf9: 'ø', 0xfa: 'œ', 0xfb: 'ß', 0xfc: 'þ', 0xfd: 'ŧ', 0xfe: 'ŋ',
+}
+
+// t61Diacritics contains the non-spacing diacritical marks of T.61, that
+// precede the letter they are combined with, and the characters they form.
+// A diacritical mark followed by a space is the spacing mark.
+var t61Diacritics = []struct {
+	mark     byte
+	letters  string
+	combined string
+}{
+	{0xc1, " AEIOUaeiou", "`ÀÈÌÒÙàèìòù"},
+	{0xc2, " AEIOUYaeiouyCcGgLlNnRrSsZz", "´ÁÉÍÓÚÝáéíóúýĆćǴǵĹĺŃńŔŕŚśŹź"},
+	{0xc3, " AEIOUaeiouCcGgHhJjSsWwYy", "^ÂÊÎÔÛâêîôûĈĉĜĝĤĥĴĵŜŝŴŵŶŷ"},
+	{0xc4, " ANOanoIiUu", "~ÃÑÕãñõĨĩŨũ"},
+	{0xc5, " AEIOUaeiou", "¯ĀĒĪŌŪāēīōū"},
+	{0xc6, " AGUagu", "˘ĂĞŬăğŭ"},
+	{0xc7, " CEGIZcegz", "˙ĊĖĠİŻċėġż"},
+	{0xc8, " AEIOUYaeiouy", "¨ÄËÏÖÜŸäëïöüÿ"},
+	{0xca, " AUau", "˚ÅŮåů"},
+	{0xcb, " CGKLNRSTcgklnrst", "¸ÇĢĶĻŅŖŞŢçģķļņŗşţ"},
+	{0xcd, " OUou", "˝ŐŰőű"},
+	{0xce, " AEIUaeiu", "˛ĄĘĮŲąęįų"},
+	{0xcf, " CDELNRSTZcdelnrstz", "ˇČĎĚĽŇŘŠŤŽčďěľňřšťž"},
+}
+
+var (
+	// t61Runes maps the single octet characters of T.61 to their runes,
+	// zero for octets that are not a character.
+	t61Runes [256]rune
+	// t61Combined maps a diacritical mark and letter to their rune.
+	t61Combined = map[[2]byte]rune{}
+	// t61Octets maps the runes of T.61 to their one or two octet encoding.
+	t61Octets = map[rune][]byte{}
+)
+
+func init() {
+	for _, b := range []byte{'\n', '\f', '\r'} {
+		t61Runes[b] = rune(b)
+	}
+
+	// the primary set is ASCII without the characters in the supplementary
+	// set, or that are spacing diacritical marks
+	for b := byte(0x20); b < 0x7f; b++ {
+		switch b {
+		case '#', '$', '\\', '^', '`', '{', '}', '~':
+		default:
+			t61Runes[b] = rune(b)
+		}
+	}
+
+	for b, r := range t61Supplementary {
+		t61Runes[b] = r
+	}
+
+	for b, r := range t61Runes {
+		if r != 0 {
+			t61Octets[r] = []byte{byte(b)}
+		}
+	}
+
+	for _, d := range t61Diacritics {
+		combined := []rune(d.combined)
+		if len(combined) != len(d.letters) {
+			panic("asn1: invalid T.61 diacritic table")
+		}
+
+		for i := range combined {
+			t61Combined[[2]byte{d.mark, d.letters[i]}] = combined[i]
+			t61Octets[combined[i]] = []byte{d.mark, d.letters[i]}
+		}
+	}
+}
+
+// decodeT61 transcodes the T.61 (Teletex) string data to UTF-8. Invalid
+// characters are replaced by U+FFFD when lax is set.
+func decodeT61(data []byte, lax bool) (string, error) {
+	buf := make([]byte, 0, len(data))
+
+	for i := 0; i < len(data); i++ {
+		r := t61Runes[data[i]]
+
+		if data[i] >= 0xc1 && data[i] <= 0xcf {
+			if i+1 < len(data) {
+				r = t61Combined[[2]byte{data[i], data[i+1]}]
+			}
+
+			if r == 0 && !lax {
+				return "", kindError(ErrConstraint, "invalid diacritical mark %#x in T61String", data[i])
+			}
+
+			if r != 0 {
+				i++
+			}
+		} else if r == 0 && !lax {
+			return "", kindError(ErrConstraint, "invalid character %#x in T61String", data[i])
+		}
+
+		if r == 0 {
+			r = utf8.RuneError
+		}
+
+		buf = append(buf, string(r)...)
+	}
+
+	return string(buf), nil
+}
+
+// encodeT61 transcodes s to T.61 (Teletex). The rune that cannot be encoded
+// is returned when ok is false.
+func encodeT61(s string) (data []byte, invalid rune, ok bool) {
+	data = make([]byte, 0, len(s))
+
+	for len(s) > 0 {
+		r, n := utf8.DecodeRuneInString(s)
+		s = s[n:]
+
+		octets, ok := t61Octets[r]
+		if !ok {
+			return nil, r, false
+		}
+
+		data = append(data, octets...)
+	}
+
+	return data, 0, true
+}
@@ -1,8 +1,31 @@
 package asn1
 
+import "fmt"
+
 func Tag(class ASNClass, value ASNValue) ASNTag {
 	return ASNTag{
 		Class: class,
 		Value: value,
 	}
 }
+
+// String returns the ASN.1 notation of the tag, eg. [APPLICATION 1].
+func (t ASNTag) String() string {
+	return fmt.Sprintf("[%s %d]", t.Class, t.Value)
+}
+
+// String returns the ASN.1 name of the class.
+func (c ASNClass) String() string {
+	switch c {
+	case ClassUniversal:
+		return "UNIVERSAL"
+	case ClassApplication:
+		return "APPLICATION"
+	case ClassContextSpecific:
+		return "CONTEXT"
+	case ClassPrivate:
+		return "PRIVATE"
+	}
+
+	return fmt.Sprintf("CLASS(%d)", uint(c))
+}
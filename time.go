@@ -0,0 +1,261 @@
+package asn1
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultUTCTimePivot is the first year of the century the two-digit years
+// of UTCTime values are mapped onto, years 50 to 99 are 1950 to 1999 and
+// years 00 to 49 are 2000 to 2049 (RFC 5280 4.1.2.5.1).
+const DefaultUTCTimePivot = 1950
+
+// NewUTCTimeFrom returns the UTCTime of t in the form required by DER, t is
+// converted to UTC and encoded with seconds. An error is returned when the
+// year of t is not within the century starting at DefaultUTCTimePivot.
+func NewUTCTimeFrom(t time.Time) (UTCTime, error) {
+	t = t.UTC()
+	if t.Year() < DefaultUTCTimePivot || t.Year() >= DefaultUTCTimePivot+100 {
+		return UTCTime{}, kindError(ErrConstraint, "year %d cannot be represented as UTCTime", t.Year())
+	}
+
+	return UTCTime{t.Format("060102150405Z")}, nil
+}
+
+// Time returns the time of the UTCTime, two-digit years are mapped onto the
+// century starting at DefaultUTCTimePivot.
+func (s UTCTime) Time() (time.Time, error) {
+	return s.TimePivot(DefaultUTCTimePivot)
+}
+
+// TimePivot returns the time of the UTCTime, two-digit years are mapped onto
+// the century starting at the year pivot.
+func (s UTCTime) TimePivot(pivot int) (time.Time, error) {
+	return parseUTCTime(s.string, pivot)
+}
+
+// NewGeneralizedTimeFrom returns the GeneralizedTime of t in the form
+// required by DER, t is converted to UTC and encoded with seconds and the
+// fraction of the second without trailing zeros.
+func NewGeneralizedTimeFrom(t time.Time) (GeneralizedTime, error) {
+	t = t.UTC()
+	if t.Year() < 0 || t.Year() > 9999 {
+		return GeneralizedTime{}, kindError(ErrConstraint, "year %d cannot be represented as GeneralizedTime", t.Year())
+	}
+
+	return GeneralizedTime{t.Format("20060102150405.999999999Z")}, nil
+}
+
+// Time returns the time of the GeneralizedTime. Values without a time zone
+// are in local time and returned in time.Local.
+func (s GeneralizedTime) Time() (time.Time, error) {
+	return parseGeneralizedTime(s.string, time.Local)
+}
+
+// parseUTCTime parses s, a UTCTime of the form YYMMDDhhmm[ss] followed by Z
+// or a time difference ±hhmm (X.680 47.3).
+func parseUTCTime(s string, pivot int) (time.Time, error) {
+	invalid := func() (time.Time, error) {
+		return time.Time{}, parseError("invalid UTCTime %q", s)
+	}
+
+	var v [5]int
+
+	rest := s
+	for i := range v {
+		var ok bool
+		if v[i], rest, ok = timeDigits(rest, 2); !ok {
+			return invalid()
+		}
+	}
+
+	second, rest, _ := timeDigits(rest, 2)
+
+	loc, ok := timeZone(rest, false)
+	if !ok || loc == nil {
+		return invalid()
+	}
+
+	// the year in the century starting at pivot
+	year := pivot - pivot%100 + v[0]
+	if year < pivot {
+		year += 100
+	}
+
+	t, ok := timeOf(year, v[1], v[2], v[3], v[4], second, loc)
+	if !ok {
+		return invalid()
+	}
+
+	return t, nil
+}
+
+// parseGeneralizedTime parses s, a GeneralizedTime of the form
+// YYYYMMDDhh[mm[ss]] with an optional fraction of the last element and an
+// optional Z or time difference ±hh[mm] (X.680 46.2). Without time zone the
+// time is local time, in location local.
+func parseGeneralizedTime(s string, local *time.Location) (time.Time, error) {
+	invalid := func() (time.Time, error) {
+		return time.Time{}, parseError("invalid GeneralizedTime %q", s)
+	}
+
+	year, rest, ok := timeDigits(s, 4)
+	if !ok {
+		return invalid()
+	}
+
+	var v [3]int
+	for i := range v {
+		if v[i], rest, ok = timeDigits(rest, 2); !ok {
+			return invalid()
+		}
+	}
+
+	// the unit of the fraction is the last element that is present
+	unit := time.Hour
+
+	minute, rest, ok := timeDigits(rest, 2)
+	if ok {
+		unit = time.Minute
+	}
+
+	second, rest, ok := timeDigits(rest, 2)
+	if ok {
+		unit = time.Second
+	}
+
+	var fraction time.Duration
+	if rest != "" && (rest[0] == '.' || rest[0] == ',') {
+		n := 1
+		for n < len(rest) && rest[n] >= '0' && rest[n] <= '9' {
+			n++
+		}
+
+		if n == 1 {
+			return invalid()
+		}
+
+		// digits beyond the precision of time.Duration are dropped
+		for _, c := range rest[1:n] {
+			unit /= 10
+			fraction += time.Duration(c-'0') * unit
+		}
+
+		rest = rest[n:]
+	}
+
+	loc, ok := timeZone(rest, true)
+	if !ok {
+		return invalid()
+	}
+
+	if loc == nil {
+		loc = local
+	}
+
+	t, ok := timeOf(year, v[0], v[1], v[2], minute, second, loc)
+	if !ok {
+		return invalid()
+	}
+
+	return t.Add(fraction), nil
+}
+
+// timeDigits parses the first n digits of s and returns the value and the
+// remainder of s.
+func timeDigits(s string, n int) (int, string, bool) {
+	if len(s) < n || !isDigits(s[:n]) {
+		return 0, s, false
+	}
+
+	v := 0
+	for _, c := range s[:n] {
+		v = v*10 + int(c-'0')
+	}
+
+	return v, s[n:], true
+}
+
+// timeZone parses s, which is empty for local time, Z for UTC or a time
+// difference ±hhmm. The minutes of the time difference are optional when
+// short is set. The location is nil for local time.
+func timeZone(s string, short bool) (*time.Location, bool) {
+	switch {
+	case s == "":
+		return nil, true
+	case s == "Z":
+		return time.UTC, true
+	case s[0] != '+' && s[0] != '-':
+		return nil, false
+	}
+
+	hours, rest, ok := timeDigits(s[1:], 2)
+	if !ok || hours > 23 {
+		return nil, false
+	}
+
+	minutes := 0
+	if rest != "" || !short {
+		if minutes, rest, ok = timeDigits(rest, 2); !ok || minutes > 59 || rest != "" {
+			return nil, false
+		}
+	}
+
+	offset := hours*3600 + minutes*60
+	if s[0] == '-' {
+		offset = -offset
+	}
+
+	return time.FixedZone("", offset), true
+}
+
+// timeOf returns the time of the elements, ok is false when one of the
+// elements is out of range.
+func timeOf(year, month, day, hour, minute, second int, loc *time.Location) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 || hour > 23 || minute > 59 || second > 59 {
+		return time.Time{}, false
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, minute, second, 0, loc)
+	return t, t.Day() == day
+}
+
+// checkTime returns the violated rule when data is not a CER and DER encoded
+// UTCTime or GeneralizedTime (X.690 11.7 and 11.8).
+func checkTime(tag ASNValue, data []byte) (string, string) {
+	s := string(data)
+
+	if tag == TagUTCTime {
+		switch {
+		case !strings.HasSuffix(s, "Z"):
+			return "11.8.1", "is not in UTC"
+		case len(s) != len("YYMMDDhhmmssZ"):
+			return "11.8.2", "does not include seconds"
+		}
+
+		return "", ""
+	}
+
+	if !strings.HasSuffix(s, "Z") {
+		return "11.7.1", "is not in UTC"
+	}
+
+	s = s[:len(s)-1]
+
+	fraction := ""
+	if i := strings.IndexAny(s, ".,"); i >= 0 {
+		s, fraction = s[:i], s[i:]
+	}
+
+	switch {
+	case len(s) != len("YYYYMMDDhhmmss"):
+		return "11.7.2", "does not include seconds"
+	case fraction == "":
+	case fraction[0] != '.':
+		return "11.7.4", "does not use a full stop as decimal mark"
+	case strings.HasSuffix(fraction, "0") || fraction == ".":
+		return "11.7.3", "has trailing zeros in the fraction of seconds"
+	}
+
+	return "", ""
+}
@@ -0,0 +1,167 @@
+package asn1_test
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/dutchsec/asn1"
+)
+
+func TestUTCTime(t *testing.T) {
+	var tests = []struct {
+		in    string
+		pivot int
+		out   time.Time
+		err   bool
+	}{
+		{in: "9105150830Z", pivot: 1950, out: time.Date(1991, 5, 15, 8, 30, 0, 0, time.UTC)},
+		{in: "910515083015Z", pivot: 1950, out: time.Date(1991, 5, 15, 8, 30, 15, 0, time.UTC)},
+		{in: "491231235959Z", pivot: 1950, out: time.Date(2049, 12, 31, 23, 59, 59, 0, time.UTC)},
+		{in: "500101000000Z", pivot: 1950, out: time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{in: "500101000000Z", pivot: 2000, out: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{in: "9105150830-0500", pivot: 1950, out: time.Date(1991, 5, 15, 13, 30, 0, 0, time.UTC)},
+		{in: "910515083015+0130", pivot: 1950, out: time.Date(1991, 5, 15, 7, 0, 15, 0, time.UTC)},
+		{in: "9105150830", err: true},
+		{in: "9102300830Z", err: true},
+		{in: "9105152430Z", err: true},
+		{in: "910515083015.5Z", err: true},
+		{in: "9105150830+05", err: true},
+		{in: "9105150830Z0", err: true},
+	}
+
+	for i, tt := range tests {
+		out, err := asn1.NewUTCTime(tt.in).TimePivot(tt.pivot)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%d. %s: expected error", i, tt.in)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%d. %s: unexpected error: %s", i, tt.in, err)
+		} else if !out.Equal(tt.out) {
+			t.Errorf("%d. %s: time mismatch: exp=%s got=%s", i, tt.in, tt.out, out)
+		}
+	}
+}
+
+func TestGeneralizedTime(t *testing.T) {
+	var tests = []struct {
+		in  string
+		out time.Time
+		err bool
+	}{
+		{in: "19851106210627.3Z", out: time.Date(1985, 11, 6, 21, 6, 27, 300000000, time.UTC)},
+		{in: "19851106210627,3Z", out: time.Date(1985, 11, 6, 21, 6, 27, 300000000, time.UTC)},
+		{in: "19851106210627Z", out: time.Date(1985, 11, 6, 21, 6, 27, 0, time.UTC)},
+		{in: "198511062106Z", out: time.Date(1985, 11, 6, 21, 6, 0, 0, time.UTC)},
+		{in: "1985110621Z", out: time.Date(1985, 11, 6, 21, 0, 0, 0, time.UTC)},
+		{in: "1985110621.5Z", out: time.Date(1985, 11, 6, 21, 30, 0, 0, time.UTC)},
+		{in: "198511062106.25Z", out: time.Date(1985, 11, 6, 21, 6, 15, 0, time.UTC)},
+		{in: "19851106210627.3-0500", out: time.Date(1985, 11, 7, 2, 6, 27, 300000000, time.UTC)},
+		{in: "19851106210627+01", out: time.Date(1985, 11, 6, 20, 6, 27, 0, time.UTC)},
+		{in: "19851106210627.123456789123Z", out: time.Date(1985, 11, 6, 21, 6, 27, 123456789, time.UTC)},
+		{in: "19851106210627", out: time.Date(1985, 11, 6, 21, 6, 27, 0, time.Local)},
+		{in: "19851106", err: true},
+		{in: "19851106210627.Z", err: true},
+		{in: "19851306210627Z", err: true},
+		{in: "19851106216027Z", err: true},
+		{in: "19851106210627+2400", err: true},
+		{in: "19851106210627+010", err: true},
+	}
+
+	for i, tt := range tests {
+		out, err := asn1.NewGeneralizedTime(tt.in).Time()
+		if tt.err {
+			if err == nil {
+				t.Errorf("%d. %s: expected error", i, tt.in)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%d. %s: unexpected error: %s", i, tt.in, err)
+		} else if !out.Equal(tt.out) {
+			t.Errorf("%d. %s: time mismatch: exp=%s got=%s", i, tt.in, tt.out, out)
+		}
+	}
+}
+
+func TestTime_Encode(t *testing.T) {
+	in := time.Date(2019, 3, 1, 10, 20, 30, 250000000, time.FixedZone("", 3600))
+
+	u, err := asn1.NewUTCTimeFrom(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if exp := "190301092030Z"; u.String() != exp {
+		t.Errorf("UTCTime mismatch: exp=%s got=%s", exp, u.String())
+	}
+
+	g, err := asn1.NewGeneralizedTimeFrom(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if exp := "20190301092030.25Z"; g.String() != exp {
+		t.Errorf("GeneralizedTime mismatch: exp=%s got=%s", exp, g.String())
+	}
+
+	if g, _ := asn1.NewGeneralizedTimeFrom(in.Truncate(time.Second)); g.String() != "20190301092030Z" {
+		t.Errorf("GeneralizedTime mismatch: got=%s", g.String())
+	}
+
+	if _, err := asn1.NewUTCTimeFrom(time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Errorf("expected error for year 2050")
+	}
+
+	// the encoding is valid DER and decodes to the same time
+	data, err := asn1.Marshal(g)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var out asn1.GeneralizedTime
+	if err := asn1.UnmarshalDER(data, &out); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if tm, err := out.Time(); err != nil || !tm.Equal(in) {
+		t.Errorf("time mismatch: exp=%s got=%s (%v)", in, tm, err)
+	}
+}
+
+func TestTime_DER(t *testing.T) {
+	var tests = []struct {
+		in   string
+		rule string
+	}{
+		{in: "170d" + hex.EncodeToString([]byte("190301092030Z"))},
+		{in: "170b" + hex.EncodeToString([]byte("1903010920Z")), rule: "11.8.2"},
+		{in: "1711" + hex.EncodeToString([]byte("190301092030+0100")), rule: "11.8.1"},
+		{in: "1812" + hex.EncodeToString([]byte("20190301092030.25Z"))},
+		{in: "180f" + hex.EncodeToString([]byte("20190301092030Z"))},
+		{in: "180e" + hex.EncodeToString([]byte("20190301092030")), rule: "11.7.1"},
+		{in: "180d" + hex.EncodeToString([]byte("201903010920Z")), rule: "11.7.2"},
+		{in: "1813" + hex.EncodeToString([]byte("20190301092030.250Z")), rule: "11.7.3"},
+		{in: "1812" + hex.EncodeToString([]byte("20190301092030,25Z")), rule: "11.7.4"},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(tt.in)
+
+		var v asn1.RawValue
+		err := asn1.UnmarshalDER(data, &v)
+		if tt.rule == "" {
+			if err != nil {
+				t.Errorf("%d. unexpected error: %s", i, err)
+			}
+			continue
+		}
+
+		if derErr, ok := err.(*asn1.DERError); !ok {
+			t.Errorf("%d. expected *DERError, got %v", i, err)
+		} else if derErr.Rule != tt.rule {
+			t.Errorf("%d. rule mismatch: exp=%s got=%s", i, tt.rule, derErr.Rule)
+		}
+	}
+}
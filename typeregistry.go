@@ -0,0 +1,92 @@
+package asn1
+
+import (
+	"reflect"
+	"sync"
+)
+
+// TypeRegistry maps tags and object identifiers to the Go types their values
+// are unmarshaled into, where the Go type is not known from the value being
+// unmarshaled into: an interface field, or an open type identified by an
+// OBJECT IDENTIFIER (see the definedby struct tag option). A TypeRegistry is
+// safe for concurrent use.
+type TypeRegistry struct {
+	mu   sync.RWMutex
+	tags map[ASNTag]reflect.Type
+	oids map[ObjectIdentifier]reflect.Type
+}
+
+// NewTypeRegistry allocates an empty TypeRegistry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		tags: map[ASNTag]reflect.Type{},
+		oids: map[ObjectIdentifier]reflect.Type{},
+	}
+}
+
+// RegisterTag maps tag to the type of v, replacing a previous registration
+// of tag. Values with tag that are unmarshaled into an interface field are
+// decoded into a new value of the type, eg.
+//
+//	registry.RegisterTag(asn1.Tag(asn1.ClassApplication, 1), BindRequest{})
+func (r *TypeRegistry) RegisterTag(tag ASNTag, v interface{}) {
+	t := registeredType(v)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tags[tag] = t
+}
+
+// RegisterOid maps oid to the type of v, replacing a previous registration
+// of oid. Open types defined by oid are decoded into a new value of the
+// type.
+func (r *TypeRegistry) RegisterOid(oid ObjectIdentifier, v interface{}) {
+	t := registeredType(v)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.oids[oid] = t
+}
+
+// LookupTag returns the type registered for tag.
+func (r *TypeRegistry) LookupTag(tag ASNTag) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tags[tag]
+	return t, ok
+}
+
+// LookupOid returns the type registered for oid.
+func (r *TypeRegistry) LookupOid(oid ObjectIdentifier) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.oids[oid]
+	return t, ok
+}
+
+// registeredType returns the type of v, which must not be nil.
+func registeredType(v interface{}) reflect.Type {
+	if v == nil {
+		panic("asn1: cannot register the type of nil")
+	}
+
+	return reflect.TypeOf(v)
+}
+
+// DefaultTypeRegistry is the TypeRegistry used by contexts that have no
+// other registry set, it is empty until applications register their types.
+var DefaultTypeRegistry = NewTypeRegistry()
+
+// RegisterTagType maps tag to the type of v in DefaultTypeRegistry.
+func RegisterTagType(tag ASNTag, v interface{}) {
+	DefaultTypeRegistry.RegisterTag(tag, v)
+}
+
+// RegisterOidType maps oid to the type of v in DefaultTypeRegistry.
+func RegisterOidType(oid ObjectIdentifier, v interface{}) {
+	DefaultTypeRegistry.RegisterOid(oid, v)
+}
@@ -0,0 +1,162 @@
+package asn1_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/dutchsec/asn1"
+)
+
+// temperature is an application type, encoded as [APPLICATION 1] INTEGER.
+type temperature struct {
+	celsius int64
+}
+
+var (
+	_ asn1.Unmarshaler = (*temperature)(nil)
+	_ asn1.Marshaler   = temperature{}
+)
+
+func (t *temperature) UnmarshalRawValue(rv *asn1.RawValue) error {
+	if rv.Tag != asn1.Tag(asn1.ClassApplication, 1) {
+		return errors.New("unexpected tag")
+	}
+
+	var i asn1.Integer
+	if err := i.UnmarshalRawValue(rv); err != nil {
+		return err
+	}
+
+	t.celsius = i.Int64()
+	return nil
+}
+
+func (t temperature) MarshalRawValue() (*asn1.RawValue, error) {
+	raw, err := asn1.NewInteger(t.celsius).MarshalRawValue()
+	if err != nil {
+		return nil, err
+	}
+
+	raw.Tag = asn1.Tag(asn1.ClassApplication, 1)
+	return raw, nil
+}
+
+func TestTypeRegistry_Tag(t *testing.T) {
+	registry := asn1.NewTypeRegistry()
+	registry.RegisterTag(asn1.Tag(asn1.ClassApplication, 1), temperature{})
+
+	ctx := asn1.NewContext()
+	ctx.SetTypeRegistry(registry)
+
+	data, _ := hex.DecodeString("300602010141011e")
+
+	var values []interface{}
+	if err := ctx.Unmarshal(data, &values); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(values) != 2 {
+		t.Fatalf("unexpected values: %#v", values)
+	}
+
+	if _, ok := values[0].(asn1.RawValue); !ok {
+		t.Errorf("expected unregistered value to be a RawValue, got %#v", values[0])
+	}
+
+	if v, ok := values[1].(temperature); !ok || v.celsius != 30 {
+		t.Errorf("unexpected registered value: %#v", values[1])
+	}
+
+	out, err := ctx.Marshal(values)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+		t.Errorf("encoding mismatch: exp=%x got=%x", data, out)
+	}
+
+	// without registry the value remains undecoded
+	ctx.SetTypeRegistry(nil)
+	if err := ctx.Unmarshal(data, &values); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	} else if _, ok := values[1].(asn1.RawValue); !ok {
+		t.Errorf("expected RawValue without registry, got %#v", values[1])
+	}
+}
+
+type algorithmIdentifier struct {
+	Algorithm  asn1.ObjectIdentifier
+	Parameters interface{} `asn1:"optional,definedby:Algorithm"`
+}
+
+func TestTypeRegistry_OpenType(t *testing.T) {
+	registry := asn1.NewTypeRegistry()
+	registry.RegisterOid(asn1.NewObjectIdentifier(1, 2, 3), temperature{})
+	registry.RegisterOid(asn1.NewObjectIdentifier(1, 2, 4), asn1.Integer{})
+
+	ctx := asn1.NewContext()
+	ctx.SetTypeRegistry(registry)
+
+	var tests = []struct {
+		in    string
+		check func(v interface{}) bool
+	}{
+		{
+			in:    "3007 06022a03 410114",
+			check: func(v interface{}) bool { return v == temperature{20} },
+		},
+		{
+			in:    "3007 06022a04 020105",
+			check: func(v interface{}) bool { return v == asn1.NewInteger(5) },
+		},
+		{
+			in: "3006 06022a05 0500",
+			check: func(v interface{}) bool {
+				raw, ok := v.(asn1.RawValue)
+				return ok && raw.Tag == asn1.Tag(asn1.ClassUniversal, asn1.TagNull)
+			},
+		},
+		{
+			in:    "3004 06022a03",
+			check: func(v interface{}) bool { return v == nil },
+		},
+	}
+
+	for i, tt := range tests {
+		data, _ := hex.DecodeString(stripSpaces(tt.in))
+
+		var alg algorithmIdentifier
+		if err := ctx.Unmarshal(data, &alg); err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+			continue
+		}
+
+		if !tt.check(alg.Parameters) {
+			t.Errorf("%d. unexpected parameters: %#v", i, alg.Parameters)
+		}
+
+		out, err := ctx.Marshal(alg)
+		if err != nil {
+			t.Errorf("%d. unexpected error: %s", i, err)
+		} else if hex.EncodeToString(out) != hex.EncodeToString(data) {
+			t.Errorf("%d. encoding mismatch: exp=%x got=%x", i, data, out)
+		}
+	}
+
+	// the registered type must accept the value
+	data, _ := hex.DecodeString("300706022a03020114")
+	var alg algorithmIdentifier
+	if err := ctx.Unmarshal(data, &alg); err == nil {
+		t.Errorf("expected error for value of unexpected type")
+	}
+
+	var invalid struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters asn1.RawValue `asn1:"definedby:Algorithm"`
+	}
+
+	data, _ = hex.DecodeString("300606022a030500")
+	if err := ctx.Unmarshal(data, &invalid); err == nil {
+		t.Errorf("expected error for open type field that is not an interface")
+	}
+}
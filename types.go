@@ -2,9 +2,7 @@ package asn1
 
 import (
 	"errors"
-	"fmt"
 	"math/big"
-	"strings"
 )
 
 // BIT STRING
@@ -50,17 +48,20 @@ func (b BitString) RightAlign() []byte {
 }
 
 func (s *BitString) UnmarshalRawValue(rv *RawValue) error {
-	data := rv.Content
+	data, err := joinBitStringSegments(rv)
+	if err != nil {
+		return err
+	}
 
 	if len(data) == 0 {
-		return fmt.Errorf("zero length BIT STRING")
+		return kindError(ErrInvalidLength, "zero length BIT STRING")
 	}
 
 	paddingBits := int(data[0])
 	if paddingBits > 7 ||
 		len(data) == 1 && paddingBits > 0 ||
 		data[len(data)-1]&((1<<data[0])-1) != 0 {
-		return fmt.Errorf("invalid padding bits in BIT STRING")
+		return parseError("invalid padding bits in BIT STRING")
 	}
 
 	var obj BitString
@@ -71,184 +72,117 @@ func (s *BitString) UnmarshalRawValue(rv *RawValue) error {
 	return nil
 }
 
-/*
-func (ctx *Context) encodeBitString(value reflect.Value) ([]byte, error) {
-	bitString, ok := value.Interface().(BitString)
-	if !ok {
-		return nil, wrongType(bitStringType.String(), value)
-	}
-
-	data := make([]byte, len(bitString.Bytes)+1)
+func (s BitString) MarshalRawValue() (*RawValue, error) {
+	data := make([]byte, len(s.Bytes)+1)
 	// As the first octet, we encode the number of unused bits at the end.
-	data[0] = byte(8 - (bitString.BitLength % 8))
-	copy(data[1:], bitString.Bytes)
-	return data, nil
-}
-
-func (ctx *Context) decodeBitString(data []byte, value reflect.Value) error {
-	// TODO check value type
-	if len(data) == 0 {
-		return syntaxError("zero length BIT STRING")
-	}
-	paddingBits := int(data[0])
-	if paddingBits > 7 ||
-		len(data) == 1 && paddingBits > 0 ||
-		data[len(data)-1]&((1<<data[0])-1) != 0 {
-		return syntaxError("invalid padding bits in BIT STRING")
-	}
-	var obj BitString
-	obj.BitLength = (len(data)-1)*8 - paddingBits
-	obj.Bytes = data[1:]
+	data[0] = byte((8 - s.BitLength%8) % 8)
+	copy(data[1:], s.Bytes)
 
-	value.Set(reflect.ValueOf(obj))
-	return nil
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagBitString),
+		Content: data,
+	}, nil
 }
-*/
 
-// Oid is used to encode and decode ASN.1 OBJECT IDENTIFIERs.
-type Oid []uint
+// Null is used to encode and decode ASN.1 NULLs.
+type Null struct{}
 
-// Cmp returns zero if both Oids are the same, a negative value if oid
-// lexicographically precedes other and a positive value otherwise.
-func (oid Oid) Cmp(other Oid) int {
-	for i, n := range oid {
-		if i >= len(other) {
-			return 1
-		}
-		if n != other[i] {
-			return int(n) - int(other[i])
-		}
-	}
-	return len(oid) - len(other)
+func (s *Null) UnmarshalRawValue(rv *RawValue) error {
+	return nil
 }
 
-// String returns the dotted representation of oid.
-func (oid Oid) String() string {
-	if len(oid) == 0 {
-		return ""
-	}
-	s := fmt.Sprintf(".%d", oid[0])
-	for i := 1; i < len(oid); i++ {
-		s += fmt.Sprintf(".%d", oid[i])
-	}
-	return s
+func (s Null) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagNull),
+		Content: []byte{},
+	}, nil
 }
 
-/*
-func (ctx *Context) encodeOid(value reflect.Value) ([]byte, error) {
-	// Check values
-	oid, ok := value.Interface().(Oid)
-	if !ok {
-		return nil, wrongType(oidType.String(), value)
-	}
+type ANY []byte
 
-	value1 := uint(0)
-	if len(oid) >= 1 {
-		value1 = oid[0]
-		if value1 > 2 {
-			return nil, parseError("invalid value for first element of OID: %d", value1)
-		}
-	}
+func (s *ANY) UnmarshalRawValue(rv *RawValue) error {
+	*s = rv.Content
+	return nil
+}
 
-	value2 := uint(0)
-	if len(oid) >= 2 {
-		value2 = oid[1]
-		if value2 > 39 {
-			return nil, parseError("invalid value for first element of OID: %d", value2)
-		}
-	}
+// MarshalRawValue always fails, as ANY only retains the content octets of
+// the decoded value. Use a RawValue to marshal open types.
+func (s ANY) MarshalRawValue() (*RawValue, error) {
+	return nil, syntaxError("cannot marshal ANY without tag, use RawValue instead")
+}
 
-	bytes := []byte{byte(40*value1 + value2)}
-	for i := 2; i < len(oid); i++ {
-		bytes = append(bytes, encodeMultiByteTag(oid[i])...)
-	}
-	return bytes, nil
+type ObjectDescriptor struct {
+	string
 }
 
-func (ctx *Context) decodeOid(data []byte, value reflect.Value) error {
-	// TODO check value type
-	if len(data) == 0 {
-		value.Set(reflect.ValueOf(Oid{}))
-		return nil
+func (s *ObjectDescriptor) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
 	}
 
-	value1 := uint(data[0] / 40)
-	value2 := uint(data[0]) - 40*value1
-	oid := Oid{value1, value2}
-
-	reader := bytes.NewBuffer(data[1:])
-	for reader.Len() > 0 {
-		valueN, err := decodeMultiByteTag(reader)
-		if err != nil {
-			return parseError("invalid value element in Object Identifier")
-		}
-		oid = append(oid, valueN)
+	*s = ObjectDescriptor{
+		string(data),
 	}
 
-	value.Set(reflect.ValueOf(oid))
 	return nil
 }
 
-*/
-
-// Null is used to encode and decode ASN.1 NULLs.
-type Null struct{}
-
-func (s *Null) UnmarshalRawValue(rv *RawValue) error {
-	return nil
+func NewObjectDescriptor(s string) ObjectDescriptor {
+	return ObjectDescriptor{s}
 }
 
-type Real struct {
-	string
+func (s ObjectDescriptor) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagObjectDescriptor),
+		Content: []byte(s.string),
+	}, nil
 }
 
-func (s *Real) UnmarshalRawValue(rv *RawValue) error {
-	*s = Real{
-		string(rv.Content),
-	}
-	return nil
+func (s *ObjectDescriptor) String() string {
+	return s.string
 }
 
-type FloatingPoint struct {
+type PrintableString struct {
 	string
 }
 
-func (s *FloatingPoint) UnmarshalRawValue(rv *RawValue) error {
-	*s = FloatingPoint{
-		string(rv.Content),
+func (s *PrintableString) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
 	}
-	return nil
-}
-
-type ANY []byte
-
-func (s *ANY) UnmarshalRawValue(rv *RawValue) error {
-	*s = rv.Content
-	return nil
-}
 
-type ObjectDescriptor struct {
-	string
-}
+	str, err := decodeString(TagPrintableString, data, false)
+	if err != nil {
+		return err
+	}
 
-func (s *ObjectDescriptor) UnmarshalRawValue(rv *RawValue) error {
-	*s = ObjectDescriptor{
-		string(rv.Content),
+	*s = PrintableString{
+		str,
 	}
 
 	return nil
 }
 
-type PrintableString struct {
-	string
+func NewPrintableString(s string) PrintableString {
+	return PrintableString{s}
 }
 
-func (s *PrintableString) UnmarshalRawValue(rv *RawValue) error {
-	*s = PrintableString{
-		string(rv.Content),
+func (s PrintableString) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeString(TagPrintableString, s.string)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagPrintableString),
+		Content: data,
+	}, nil
+}
+
+func (s *PrintableString) String() string {
+	return s.string
 }
 
 type GraphicString struct {
@@ -256,151 +190,454 @@ type GraphicString struct {
 }
 
 func (s *GraphicString) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
 	*s = GraphicString{
-		string(rv.Content),
+		string(data),
 	}
 
 	return nil
 }
 
+func NewGraphicString(s string) GraphicString {
+	return GraphicString{s}
+}
+
+func (s GraphicString) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagGraphicString),
+		Content: []byte(s.string),
+	}, nil
+}
+
+func (s *GraphicString) String() string {
+	return s.string
+}
+
 type GeneralString struct {
 	string
 }
 
 func (s *GeneralString) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
 	*s = GeneralString{
-		string(rv.Content),
+		string(data),
 	}
 
 	return nil
 }
 
+func NewGeneralString(s string) GeneralString {
+	return GeneralString{s}
+}
+
+func (s GeneralString) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagGeneralString),
+		Content: []byte(s.string),
+	}, nil
+}
+
+func (s *GeneralString) String() string {
+	return s.string
+}
+
 type T61String struct {
 	string
 }
 
 func (s *T61String) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
+	str, err := decodeString(TagT61String, data, false)
+	if err != nil {
+		return err
+	}
+
 	*s = T61String{
-		string(rv.Content),
+		str,
 	}
 
 	return nil
 }
 
+func NewT61String(s string) T61String {
+	return T61String{s}
+}
+
+func (s T61String) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeString(TagT61String, s.string)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagT61String),
+		Content: data,
+	}, nil
+}
+
+func (s *T61String) String() string {
+	return s.string
+}
+
 type GeneralizedTime struct {
 	string
 }
 
 func (s *GeneralizedTime) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
 	*s = GeneralizedTime{
-		string(rv.Content),
+		string(data),
 	}
 
 	return nil
 }
 
+func NewGeneralizedTime(s string) GeneralizedTime {
+	return GeneralizedTime{s}
+}
+
+func (s GeneralizedTime) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagGeneralizedTime),
+		Content: []byte(s.string),
+	}, nil
+}
+
+func (s *GeneralizedTime) String() string {
+	return s.string
+}
+
 type UTCTime struct {
 	string
 }
 
 func (s *UTCTime) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
 	*s = UTCTime{
-		string(rv.Content),
+		string(data),
 	}
 
 	return nil
 }
 
+func NewUTCTime(s string) UTCTime {
+	return UTCTime{s}
+}
+
+func (s UTCTime) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagUTCTime),
+		Content: []byte(s.string),
+	}, nil
+}
+
+func (s *UTCTime) String() string {
+	return s.string
+}
+
 type IA5String struct {
 	string
 }
 
 func (s *IA5String) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
+	str, err := decodeString(TagIA5String, data, false)
+	if err != nil {
+		return err
+	}
+
 	*s = IA5String{
-		string(rv.Content),
+		str,
 	}
 
 	return nil
 }
 
+func NewIA5String(s string) IA5String {
+	return IA5String{s}
+}
+
+func (s IA5String) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeString(TagIA5String, s.string)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagIA5String),
+		Content: data,
+	}, nil
+}
+
+func (s *IA5String) String() string {
+	return s.string
+}
+
 type OctetString struct {
 	string
 }
 
 func (s *OctetString) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
 	*s = OctetString{
-		string(rv.Content),
+		string(data),
 	}
 	return nil
 }
 
+func NewOctetString(s string) OctetString {
+	return OctetString{s}
+}
+
+func (s OctetString) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagOctetString),
+		Content: []byte(s.string),
+	}, nil
+}
+
 func (s *OctetString) String() string {
 	return s.string
 }
 
+func (s *OctetString) Bytes() []byte {
+	return []byte(s.string)
+}
+
 type UTF8String struct {
 	string
 }
 
 func (s *UTF8String) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
+	str, err := decodeString(TagUTF8String, data, false)
+	if err != nil {
+		return err
+	}
+
 	*s = UTF8String{
-		string(rv.Content),
+		str,
 	}
 	return nil
 }
 
-type ObjectIdentifier struct {
+func NewUTF8String(s string) UTF8String {
+	return UTF8String{s}
+}
+
+func (s UTF8String) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeString(TagUTF8String, s.string)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagUTF8String),
+		Content: data,
+	}, nil
+}
+
+func (s *UTF8String) String() string {
+	return s.string
+}
+
+type VisibleString struct {
 	string
 }
 
-func (s *ObjectIdentifier) UnmarshalRawValue(rv *RawValue) error {
-	if len(rv.Content) == 0 {
-		return fmt.Errorf("Not enough bytes for ObjectIdentifier")
+func (s *VisibleString) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
 	}
 
-	vals := make([]int, 2)
-	vals[0] = int(rv.Content[0]) / 40
-	vals[1] = int(rv.Content[0]) % 40
+	str, err := decodeString(TagVisibleString, data, false)
+	if err != nil {
+		return err
+	}
 
-	value := 0
-	for i := 1; i < len(rv.Content); i++ {
-		b := int(rv.Content[i])
+	*s = VisibleString{
+		str,
+	}
+	return nil
+}
+
+func NewVisibleString(s string) VisibleString {
+	return VisibleString{s}
+}
 
-		value = value<<7 + b&127
-		if b&128 == 128 {
-			continue
-		}
+func (s VisibleString) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeString(TagVisibleString, s.string)
+	if err != nil {
+		return nil, err
+	}
 
-		vals = append(vals, value)
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagVisibleString),
+		Content: data,
+	}, nil
+}
 
-		// reset
-		value = 0
+func (s *VisibleString) String() string {
+	return s.string
+}
+
+// BMPString is a string of characters of the Basic Multilingual Plane,
+// encoded in UCS-2.
+type BMPString struct {
+	string
+}
+
+func (s *BMPString) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
+	str, err := decodeString(TagBMPString, data, false)
+	if err != nil {
+		return err
+	}
+
+	*s = BMPString{
+		str,
 	}
+	return nil
+}
+
+func NewBMPString(s string) BMPString {
+	return BMPString{s}
+}
+
+func (s BMPString) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeString(TagBMPString, s.string)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagBMPString),
+		Content: data,
+	}, nil
+}
 
-	svals := make([]string, len(vals))
-	for i, _ := range vals {
-		svals[i] = fmt.Sprintf("%d", vals[i])
+func (s *BMPString) String() string {
+	return s.string
+}
+
+// UniversalString is a string of Unicode characters, encoded in UCS-4.
+type UniversalString struct {
+	string
+}
+
+func (s *UniversalString) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
 	}
 
-	*s = ObjectIdentifier{
-		strings.Join(svals, "."),
+	str, err := decodeString(TagUniversalString, data, false)
+	if err != nil {
+		return err
 	}
 
+	*s = UniversalString{
+		str,
+	}
 	return nil
 }
 
-type VisibleString struct {
+func NewUniversalString(s string) UniversalString {
+	return UniversalString{s}
+}
+
+func (s UniversalString) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeString(TagUniversalString, s.string)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagUniversalString),
+		Content: data,
+	}, nil
+}
+
+func (s *UniversalString) String() string {
+	return s.string
+}
+
+// NumericString is a string of digits and spaces.
+type NumericString struct {
 	string
 }
 
-func (s *VisibleString) UnmarshalRawValue(rv *RawValue) error {
-	*s = VisibleString{
-		string(rv.Content),
+func (s *NumericString) UnmarshalRawValue(rv *RawValue) error {
+	data, err := joinSegments(rv, TagOctetString)
+	if err != nil {
+		return err
+	}
+
+	str, err := decodeString(TagNumericString, data, false)
+	if err != nil {
+		return err
+	}
+
+	*s = NumericString{
+		str,
 	}
 	return nil
 }
 
-func (s *VisibleString) String() string {
+func NewNumericString(s string) NumericString {
+	return NumericString{s}
+}
+
+func (s NumericString) MarshalRawValue() (*RawValue, error) {
+	data, err := encodeString(TagNumericString, s.string)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagNumericString),
+		Content: data,
+	}, nil
+}
+
+func (s *NumericString) String() string {
 	return s.string
 }
 
@@ -413,38 +650,48 @@ var (
 	BoolFalse = Bool{false}
 )
 
+func (s Bool) Bool() bool {
+	return s.bool
+}
+
+func (s Bool) MarshalRawValue() (*RawValue, error) {
+	data := []byte{0x00}
+	if s.bool {
+		data[0] = 0xff
+	}
+
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagBoolean),
+		Content: data,
+	}, nil
+}
+
 func (s *Bool) UnmarshalRawValue(rv *RawValue) error {
 	data := rv.Content
 
-	if true /* der encoding */ {
-		boolValue := parseBigInt(data).Cmp(big.NewInt(0)) != 0
-		if boolValue {
-			*s = BoolTrue
-		} else {
-			*s = BoolFalse
-		}
-		return nil
+	if len(data) != 1 {
+		return kindError(ErrInvalidLength, "invalid BOOLEAN length: %d", len(data))
 	}
 
-	// DER is more restrict regarding valid booleans
-	if len(data) == 1 {
-		switch data[0] {
-		case 0x00:
-			*s = BoolFalse
-			return nil
-		case 0xff:
-			*s = BoolTrue
-			return nil
-		}
+	// Any non zero value is TRUE, DER is more restrict regarding valid
+	// booleans, see checkDER.
+	if data[0] != 0x00 {
+		*s = BoolTrue
+	} else {
+		*s = BoolFalse
 	}
 
-	return fmt.Errorf("Unexpected bool value: %d", data[0])
+	return nil
 }
 
 type Integer struct {
 	int64
 }
 
+func NewInteger(v int64) Integer {
+	return Integer{v}
+}
+
 func (s Integer) Int64() int64 {
 	return s.int64
 }
@@ -452,13 +699,17 @@ func (s Integer) Int64() int64 {
 func (s *Integer) UnmarshalRawValue(rv *RawValue) error {
 	data := rv.Content
 
+	if len(data) == 0 {
+		return kindError(ErrInvalidLength, "empty integer")
+	}
+
 	if len(data) > 8 {
-		return fmt.Errorf("integer too large for Go type 'int64'")
+		return kindError(ErrConstraint, "integer too large for Go type 'int64', use BigInteger")
 	}
 
 	// Sign extend the value
 	extensionByte := byte(0x00)
-	if len(data) > 0 && data[0]&0x80 != 0 {
+	if data[0]&0x80 != 0 {
 		extensionByte = byte(0xff)
 	}
 
@@ -479,4 +730,102 @@ func (s *Integer) UnmarshalRawValue(rv *RawValue) error {
 	return nil
 }
 
+func (s Integer) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagInteger),
+		Content: encodeInt64(s.int64),
+	}, nil
+}
+
+// Enumerated is an ENUMERATED value, encoded as an INTEGER (X.690 8.4).
+type Enumerated struct {
+	int64
+}
+
+func NewEnumerated(v int64) Enumerated {
+	return Enumerated{v}
+}
+
+func (s Enumerated) Int64() int64 {
+	return s.int64
+}
+
+func (s *Enumerated) UnmarshalRawValue(rv *RawValue) error {
+	if len(rv.Content) == 0 {
+		return kindError(ErrInvalidLength, "empty ENUMERATED")
+	}
+
+	var i Integer
+	if err := i.UnmarshalRawValue(rv); err != nil {
+		return err
+	}
+
+	*s = Enumerated{i.int64}
+	return nil
+}
+
+func (s Enumerated) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagEnumerated),
+		Content: encodeInt64(s.int64),
+	}, nil
+}
+
+// BigInteger is an INTEGER of arbitrary size, eg. the serial number of a
+// certificate or an SNMP Counter64. The zero BigInteger is zero.
+type BigInteger struct {
+	i *big.Int
+}
+
+// NewBigInteger returns the BigInteger of i.
+func NewBigInteger(i *big.Int) BigInteger {
+	return BigInteger{new(big.Int).Set(i)}
+}
+
+// NewUnsignedInteger returns the BigInteger of v.
+func NewUnsignedInteger(v uint64) BigInteger {
+	return BigInteger{new(big.Int).SetUint64(v)}
+}
+
+// BigInt returns the value as a big.Int.
+func (s BigInteger) BigInt() *big.Int {
+	if s.i == nil {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Set(s.i)
+}
+
+// Uint64 returns the value of unsigned types, a *ParseError is returned
+// when the value is negative or does not fit in a uint64.
+func (s BigInteger) Uint64() (uint64, error) {
+	i := s.BigInt()
+	if !i.IsUint64() {
+		return 0, kindError(ErrConstraint, "integer %s overflows uint64", i)
+	}
+
+	return i.Uint64(), nil
+}
+
+// String returns the value in decimal notation.
+func (s BigInteger) String() string {
+	return s.BigInt().String()
+}
+
+func (s *BigInteger) UnmarshalRawValue(rv *RawValue) error {
+	if len(rv.Content) == 0 {
+		return kindError(ErrInvalidLength, "empty integer")
+	}
+
+	*s = BigInteger{parseBigInt(rv.Content)}
+	return nil
+}
+
+func (s BigInteger) MarshalRawValue() (*RawValue, error) {
+	return &RawValue{
+		Tag:     Tag(ClassUniversal, TagInteger),
+		Content: encodeBigInt(s.BigInt()),
+	}, nil
+}
+
 var ErrUnparsedObjects = errors.New("Unparsed objects")
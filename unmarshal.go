@@ -0,0 +1,666 @@
+package asn1
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Unmarshaler is implemented by types that decode themselves from a
+// RawValue. Values with any tag are passed to UnmarshalRawValue unless the
+// struct tag of the field gives the tag, implementations check the tag
+// themselves when needed.
+type Unmarshaler interface {
+	UnmarshalRawValue(*RawValue) error
+}
+
+var (
+	rawValueType = reflect.TypeOf(RawValue{})
+	bigIntType   = reflect.TypeOf(new(big.Int))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// universalTags maps the types of this package to their universal tag.
+var universalTags = map[reflect.Type]ASNValue{
+	reflect.TypeOf(BitString{}):                TagBitString,
+	reflect.TypeOf(ObjectIdentifier{}):         TagOid,
+	reflect.TypeOf(RelativeObjectIdentifier{}): TagRelativeOid,
+	reflect.TypeOf(OidIri{}):                   TagOidIri,
+	reflect.TypeOf(External{}):                 TagExternal,
+	reflect.TypeOf(EmbeddedPDV{}):              TagEmbeddedPDV,
+	reflect.TypeOf(CharacterString{}):          TagCharacterString,
+	reflect.TypeOf(Null{}):                     TagNull,
+	reflect.TypeOf(Real{}):                     TagReal,
+	reflect.TypeOf(FloatingPoint{}):            TagReal,
+	reflect.TypeOf(ObjectDescriptor{}):         TagObjectDescriptor,
+	reflect.TypeOf(NumericString{}):            TagNumericString,
+	reflect.TypeOf(PrintableString{}):          TagPrintableString,
+	reflect.TypeOf(GraphicString{}):            TagGraphicString,
+	reflect.TypeOf(GeneralString{}):            TagGeneralString,
+	reflect.TypeOf(T61String{}):                TagT61String,
+	reflect.TypeOf(GeneralizedTime{}):          TagGeneralizedTime,
+	reflect.TypeOf(UTCTime{}):                  TagUTCTime,
+	reflect.TypeOf(Date{}):                     TagDate,
+	reflect.TypeOf(TimeOfDay{}):                TagTimeOfDay,
+	reflect.TypeOf(DateTime{}):                 TagDateTime,
+	reflect.TypeOf(Duration{}):                 TagDuration,
+	reflect.TypeOf(Time{}):                     TagTime,
+	reflect.TypeOf(IA5String{}):                TagIA5String,
+	reflect.TypeOf(OctetString{}):              TagOctetString,
+	reflect.TypeOf(UTF8String{}):               TagUTF8String,
+	reflect.TypeOf(VisibleString{}):            TagVisibleString,
+	reflect.TypeOf(BMPString{}):                TagBMPString,
+	reflect.TypeOf(UniversalString{}):          TagUniversalString,
+	reflect.TypeOf(Bool{}):                     TagBoolean,
+	reflect.TypeOf(Integer{}):                  TagInteger,
+	reflect.TypeOf(BigInteger{}):               TagInteger,
+	reflect.TypeOf(Enumerated{}):               TagEnumerated,
+	bigIntType:                                 TagInteger,
+}
+
+// stringTags contains the universal tags that can be decoded into a Go
+// string.
+var stringTags = map[ASNValue]bool{
+	TagUTF8String:       true,
+	TagNumericString:    true,
+	TagPrintableString:  true,
+	TagT61String:        true,
+	TagIA5String:        true,
+	TagVisibleString:    true,
+	TagGraphicString:    true,
+	TagGeneralString:    true,
+	TagObjectDescriptor: true,
+	TagBMPString:        true,
+	TagUniversalString:  true,
+}
+
+// decodeValue checks the tag of raw against the type of value and opts and
+// stores the decoded raw value into value. A *ParseError records the offset
+// of the value it occurred in.
+func (ctx *Context) decodeValue(raw *RawValue, value reflect.Value, opts *fieldOptions) error {
+	err := ctx.decodeTagged(raw, value, opts)
+	if raw.HeaderLength > 0 {
+		err = errorAt(err, raw.Offset)
+	}
+
+	return err
+}
+
+// decodeTagged decodes raw into value, see decodeValue.
+func (ctx *Context) decodeTagged(raw *RawValue, value reflect.Value, opts *fieldOptions) error {
+	if opts.tag != nil {
+		if raw.Tag != *opts.tag {
+			return kindError(ErrTagMismatch, "tag mismatch: expected %s, got %s", *opts.tag, raw.Tag)
+		}
+
+		if !opts.explicit {
+			// The universal type of implicitly tagged values is only known
+			// from the Go type.
+			if tag, ok := universalTagOf(value.Type(), opts); ok {
+				if err := ctx.checkRules(tag, raw); err != nil {
+					return err
+				}
+			}
+
+			return ctx.decodeContent(raw, value, opts)
+		}
+
+		if !raw.Constructed {
+			return kindError(ErrTagMismatch, "explicitly tagged value %s is not constructed", raw.Tag)
+		}
+
+		children, err := raw.ChildValues()
+		if err != nil {
+			return err
+		}
+
+		if len(children) != 1 {
+			return parseError("explicitly tagged value %s contains %d values", raw.Tag, len(children))
+		}
+
+		return ctx.decodeValue(children[0], value, &fieldOptions{set: opts.set})
+	}
+
+	if !matchesUniversalTag(raw.Tag, value.Type(), opts) {
+		return kindError(ErrTagMismatch, "unexpected tag %s for type %s", raw.Tag, value.Type())
+	}
+
+	return ctx.decodeContent(raw, value, opts)
+}
+
+// checkRules verifies that the implicitly tagged raw, of the universal type
+// tag, conforms to the decoding rules of ctx.
+func (ctx *Context) checkRules(tag ASNValue, raw *RawValue) error {
+	switch ctx.rules.decoding {
+	case CER:
+		return checkCER(tag, raw)
+	case DER:
+		return checkDER(tag, raw)
+	}
+
+	return nil
+}
+
+// matchesTag returns true when raw can be decoded into a value of type t.
+func matchesTag(raw *RawValue, t reflect.Type, opts *fieldOptions) bool {
+	if opts.tag != nil {
+		return raw.Tag == *opts.tag
+	}
+
+	return matchesUniversalTag(raw.Tag, t, opts)
+}
+
+// matchesUniversalTag returns true when tag is the universal tag of type t.
+func matchesUniversalTag(tag ASNTag, t reflect.Type, opts *fieldOptions) bool {
+	for t.Kind() == reflect.Ptr && t != bigIntType {
+		t = t.Elem()
+	}
+
+	if t == rawValueType || t == reflect.TypeOf(ANY{}) || t.Kind() == reflect.Interface {
+		return true
+	}
+
+	if t == timeType {
+		return tag == Tag(ClassUniversal, TagUTCTime) || tag == Tag(ClassUniversal, TagGeneralizedTime)
+	}
+
+	if value, ok := universalTags[t]; ok {
+		return tag == Tag(ClassUniversal, value)
+	}
+
+	if reflect.PtrTo(t).Implements(reflect.TypeOf((*Unmarshaler)(nil)).Elem()) {
+		// types outside this package decide for themselves
+		return true
+	}
+
+	if tag.Class != ClassUniversal {
+		return false
+	}
+
+	if t.Kind() == reflect.String {
+		return stringTags[tag.Value]
+	}
+
+	value, ok := universalTagOf(t, opts)
+	return ok && tag.Value == value
+}
+
+// universalTagOf returns the universal tag used to encode a value of type t.
+func universalTagOf(t reflect.Type, opts *fieldOptions) (ASNValue, bool) {
+	for t.Kind() == reflect.Ptr && t != bigIntType {
+		t = t.Elem()
+	}
+
+	if value, ok := universalTags[t]; ok {
+		return value, true
+	}
+
+	if t == timeType {
+		// implicitly tagged times are GeneralizedTime
+		return TagGeneralizedTime, true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return TagBoolean, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TagInteger, true
+	case reflect.Float32, reflect.Float64:
+		return TagReal, true
+	case reflect.String:
+		return TagUTF8String, true
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return TagOctetString, true
+		}
+
+		return sequenceTag(opts), true
+	case reflect.Struct:
+		return sequenceTag(opts), true
+	}
+
+	return 0, false
+}
+
+// decodeContent stores raw into value, the tag has already been checked.
+func (ctx *Context) decodeContent(raw *RawValue, value reflect.Value, opts *fieldOptions) error {
+	if value.Kind() == reflect.Ptr && value.Type() != bigIntType {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+
+		return ctx.decodeContent(raw, value.Elem(), opts)
+	}
+
+	switch value.Type() {
+	case rawValueType:
+		value.Set(reflect.ValueOf(*raw))
+		return nil
+	case bigIntType:
+		if len(raw.Content) == 0 {
+			return kindError(ErrInvalidLength, "empty integer")
+		}
+
+		value.Set(reflect.ValueOf(parseBigInt(raw.Content)))
+		return nil
+	case timeType:
+		t, err := ctx.decodeTime(raw)
+		if err != nil {
+			return err
+		}
+
+		value.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if value.CanAddr() {
+		if sv, ok := value.Addr().Interface().(stringValue); ok {
+			str, err := ctx.decodeString(universalTags[value.Type()], raw)
+			if err != nil {
+				return err
+			}
+
+			sv.setString(str)
+			return nil
+		}
+
+		if u, ok := value.Addr().Interface().(Unmarshaler); ok {
+			return u.UnmarshalRawValue(raw)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.Interface:
+		if opts.tag == nil && ctx.types != nil {
+			// the tag of implicitly tagged values does not identify the type
+			if t, ok := ctx.types.LookupTag(raw.Tag); ok && t.AssignableTo(value.Type()) {
+				return ctx.decodeRegistered(raw, value, t, opts)
+			}
+		}
+
+		if value.NumMethod() != 0 {
+			break
+		}
+
+		value.Set(reflect.ValueOf(*raw))
+		return nil
+	case reflect.Bool:
+		var b Bool
+		if err := b.UnmarshalRawValue(raw); err != nil {
+			return err
+		}
+
+		value.SetBool(b.bool)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i Integer
+		if err := i.UnmarshalRawValue(raw); err != nil {
+			return err
+		}
+
+		if value.OverflowInt(i.int64) {
+			return kindError(ErrConstraint, "integer %d overflows %s", i.int64, value.Type())
+		}
+
+		value.SetInt(i.int64)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if len(raw.Content) == 0 {
+			return kindError(ErrInvalidLength, "empty integer")
+		}
+
+		i := parseBigInt(raw.Content)
+		if i.Sign() < 0 || !i.IsUint64() || value.OverflowUint(i.Uint64()) {
+			return kindError(ErrConstraint, "integer %s overflows %s", i, value.Type())
+		}
+
+		value.SetUint(i.Uint64())
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := decodeFloat64(raw.Content)
+		if err != nil {
+			return err
+		}
+
+		if value.OverflowFloat(f) {
+			return kindError(ErrConstraint, "REAL %g overflows %s", f, value.Type())
+		}
+
+		value.SetFloat(f)
+		return nil
+	case reflect.String:
+		tag := raw.Tag.Value
+		if raw.Tag.Class != ClassUniversal {
+			// the character set of implicitly tagged strings is unknown
+			tag = TagOctetString
+		}
+
+		s, err := ctx.decodeString(tag, raw)
+		if err != nil {
+			return err
+		}
+
+		value.SetString(s)
+		return nil
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			data, err := joinSegments(raw, TagOctetString)
+			if err != nil {
+				return err
+			}
+
+			value.SetBytes(append([]byte{}, data...))
+			return nil
+		}
+
+		return ctx.decodeSlice(raw, value)
+	case reflect.Struct:
+		return ctx.decodeStruct(raw, value, opts)
+	}
+
+	return unsupportedType(value.Type())
+}
+
+// decodeString decodes the string raw of the universal type tag, using the
+// string validation of ctx.
+func (ctx *Context) decodeString(tag ASNValue, raw *RawValue) (string, error) {
+	data, err := joinSegments(raw, TagOctetString)
+	if err != nil {
+		return "", err
+	}
+
+	return decodeString(tag, data, ctx.laxStrings)
+}
+
+// decodeTime decodes the UTCTime or GeneralizedTime raw, using the time
+// settings of ctx. Values that are not tagged UTCTime are GeneralizedTime.
+func (ctx *Context) decodeTime(raw *RawValue) (time.Time, error) {
+	data, err := joinSegments(raw, TagOctetString)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var t time.Time
+	if raw.Tag == Tag(ClassUniversal, TagUTCTime) {
+		t, err = parseUTCTime(string(data), ctx.pivot)
+	} else {
+		local := time.Local
+		if ctx.location != nil {
+			local = ctx.location
+		}
+
+		t, err = parseGeneralizedTime(string(data), local)
+	}
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if ctx.location != nil {
+		t = t.In(ctx.location)
+	}
+
+	return t, nil
+}
+
+// decodeSlice decodes a SEQUENCE OF or SET OF into value.
+func (ctx *Context) decodeSlice(raw *RawValue, value reflect.Value) error {
+	children, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(value.Type(), len(children), len(children))
+	for i, child := range children {
+		if err := ctx.decodeValue(child, slice.Index(i), &fieldOptions{}); err != nil {
+			return errorPath(err, fmt.Sprintf("[%d]", i))
+		}
+	}
+
+	value.Set(slice)
+	return nil
+}
+
+// decodeStruct decodes a SEQUENCE or SET into value. The components of a SET
+// can be in any order.
+func (ctx *Context) decodeStruct(raw *RawValue, value reflect.Value, opts *fieldOptions) error {
+	children, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	used := make([]bool, len(children))
+	next := 0
+
+	// the field that keeps unknown components, see KeepExtensions
+	var extensions reflect.Value
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		fopts, err := parseFieldOptions(field.Tag.Get("asn1"))
+		if err != nil {
+			return err
+		}
+
+		if fopts.ignore {
+			continue
+		}
+
+		if fopts.extensions {
+			if field.Type != reflect.TypeOf([]RawValue{}) {
+				return syntaxError("extensions field %s must be a []RawValue", fieldName(field, fopts))
+			}
+
+			extensions = value.Field(i)
+			continue
+		}
+
+		index := -1
+		if opts.set {
+			for j, child := range children {
+				if !used[j] && matchesTag(child, field.Type, fopts) {
+					index = j
+					break
+				}
+			}
+		} else if next < len(children) && matchesTag(children[next], field.Type, fopts) {
+			index = next
+		}
+
+		if index >= 0 {
+			decode := ctx.decodeValue
+			if fopts.definedBy != "" {
+				t, err := ctx.openType(value, field, fopts.definedBy)
+				if err != nil {
+					return err
+				}
+
+				if t != nil {
+					decode = func(raw *RawValue, value reflect.Value, opts *fieldOptions) error {
+						return ctx.decodeRegistered(raw, value, t, opts)
+					}
+				}
+			}
+
+			if err := decode(children[index], value.Field(i), fopts); err != nil {
+				return errorPath(err, fieldName(field, fopts))
+			}
+
+			used[index] = true
+			next = index + 1
+			continue
+		}
+
+		if fopts.defaultValue != nil {
+			if err := setDefaultValue(value.Field(i), *fopts.defaultValue); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if fopts.optional {
+			continue
+		}
+
+		if next < len(children) && !opts.set {
+			return kindError(ErrTagMismatch, "unexpected tag %s for field %s", children[next].Tag, fieldName(field, fopts))
+		}
+
+		return parseError("missing value for field %s", fieldName(field, fopts))
+	}
+
+	var unknown []RawValue
+	for j, ok := range used {
+		if !ok {
+			unknown = append(unknown, *children[j])
+		}
+	}
+
+	switch {
+	case unknown == nil:
+	case ctx.extensions == RejectExtensions:
+		return ErrUnparsedObjects
+	case ctx.extensions == KeepExtensions && extensions.IsValid():
+		extensions.Set(reflect.ValueOf(unknown))
+	}
+
+	return nil
+}
+
+// openType returns the type registered for the OBJECT IDENTIFIER in the field
+// name of the struct value, that defines the open type field. A nil type is
+// returned when the object identifier is not registered.
+func (ctx *Context) openType(value reflect.Value, field reflect.StructField, name string) (reflect.Type, error) {
+	if field.Type.Kind() != reflect.Interface {
+		return nil, syntaxError("open type field %s must be an interface", field.Name)
+	}
+
+	defining := value.FieldByName(name)
+	if defining.IsValid() && defining.Kind() == reflect.Ptr {
+		if defining.IsNil() {
+			return nil, nil
+		}
+
+		defining = defining.Elem()
+	}
+
+	if !defining.IsValid() || defining.Type() != reflect.TypeOf(ObjectIdentifier{}) {
+		return nil, syntaxError("field %s defining open type %s is not an ObjectIdentifier", name, field.Name)
+	}
+
+	if ctx.types == nil {
+		return nil, nil
+	}
+
+	t, ok := ctx.types.LookupOid(defining.Interface().(ObjectIdentifier))
+	if !ok || !t.AssignableTo(field.Type) {
+		return nil, nil
+	}
+
+	return t, nil
+}
+
+// decodeRegistered decodes raw into a new value of the registered type t and
+// stores it in the interface value.
+func (ctx *Context) decodeRegistered(raw *RawValue, value reflect.Value, t reflect.Type, opts *fieldOptions) error {
+	v := reflect.New(t).Elem()
+	if err := ctx.decodeTagged(raw, v, opts); err != nil {
+		return err
+	}
+
+	value.Set(v)
+	return nil
+}
+
+// defaultValue parses the DEFAULT value s for a value of type t.
+func defaultValue(t reflect.Type, s string) (reflect.Value, error) {
+	value := reflect.New(t).Elem()
+
+	switch t {
+	case reflect.TypeOf(Integer{}):
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return value, syntaxError("invalid default value %q for %s", s, t)
+		}
+
+		value.Set(reflect.ValueOf(Integer{i}))
+		return value, nil
+	case reflect.TypeOf(BigInteger{}):
+		i, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return value, syntaxError("invalid default value %q for %s", s, t)
+		}
+
+		value.Set(reflect.ValueOf(BigInteger{i}))
+		return value, nil
+	case reflect.TypeOf(Bool{}):
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return value, syntaxError("invalid default value %q for %s", s, t)
+		}
+
+		value.Set(reflect.ValueOf(Bool{b}))
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return value, syntaxError("invalid default value %q for %s", s, t)
+		}
+
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return value, syntaxError("invalid default value %q for %s", s, t)
+		}
+
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return value, syntaxError("invalid default value %q for %s", s, t)
+		}
+
+		value.SetUint(i)
+	default:
+		return value, syntaxError("default values are not supported for %s", t)
+	}
+
+	return value, nil
+}
+
+// setDefaultValue stores the DEFAULT value s into value.
+func setDefaultValue(value reflect.Value, s string) error {
+	if value.Kind() == reflect.Ptr {
+		def, err := defaultValue(value.Type().Elem(), s)
+		if err != nil {
+			return err
+		}
+
+		ptr := reflect.New(value.Type().Elem())
+		ptr.Elem().Set(def)
+		value.Set(ptr)
+		return nil
+	}
+
+	def, err := defaultValue(value.Type(), s)
+	if err != nil {
+		return err
+	}
+
+	value.Set(def)
+	return nil
+}
@@ -0,0 +1,307 @@
+package asn1xer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// node is an element of a XER document.
+type node struct {
+	name     string
+	text     string
+	children []*node
+}
+
+// parseXML parses the root element of a XER document.
+func parseXML(data []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root *node
+	stack := []*node{}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("xer: %s", err)
+		}
+
+		switch v := token.(type) {
+		case xml.StartElement:
+			n := &node{
+				name: v.Name.Local,
+			}
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root != nil {
+				return nil, fmt.Errorf("xer: multiple root elements")
+			} else {
+				root = n
+			}
+
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(v)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("xer: no root element")
+	}
+
+	return root, nil
+}
+
+// decoder reads the elements of a XER document into BER encoded values.
+type decoder struct {
+	*Codec
+}
+
+// decodeItem decodes n, the element of the i-th item of a SEQUENCE, SET or
+// CHOICE.
+func (d *decoder) decodeItem(items []asn1parser.ASNItem, i int, n *node) (*asn1.RawValue, error) {
+	raw, err := d.decodeBuiltin(items[i].Type, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.def.TagItem(items, i, raw)
+}
+
+// decodeType decodes n, the element of a value of type t.
+func (d *decoder) decodeType(t asn1parser.ASNType, n *node) (*asn1.RawValue, error) {
+	raw, err := d.decodeBuiltin(t, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.def.TagType(t, raw)
+}
+
+// decodeBuiltin decodes n, the element of a value of the builtin type t
+// refers to.
+func (d *decoder) decodeBuiltin(t asn1parser.ASNType, n *node) (*asn1.RawValue, error) {
+	builtin := d.def.Resolve(t)
+
+	// the choice value is the value of the alternative
+	if v, ok := builtin.(*asn1parser.ASNChoice); ok {
+		if len(n.children) != 1 {
+			return nil, fmt.Errorf("xer: element %s contains %d alternatives", n.name, len(n.children))
+		}
+
+		for i, item := range v.Items {
+			if !item.TripleDot && item.Name == n.children[0].name {
+				return d.decodeItem(v.Items, i, n.children[0])
+			}
+		}
+
+		return nil, fmt.Errorf("xer: unknown alternative %s of %s", n.children[0].name, v.Name())
+	}
+
+	value, ok := asn1parser.UniversalTag(builtin)
+	if !ok {
+		return nil, fmt.Errorf("xer: unsupported type %s", builtin.Name())
+	}
+
+	raw := &asn1.RawValue{
+		Tag: asn1.Tag(asn1.ClassUniversal, value),
+	}
+
+	var err error
+
+	text := strings.TrimSpace(n.text)
+
+	switch v := builtin.(type) {
+	case *asn1parser.ASNSequence:
+		if v.Of != "" {
+			return d.decodeSequenceOf(v, n)
+		}
+
+		return d.decodeSequence(v.Items, n, raw.Tag)
+	case *asn1parser.ASNSet:
+		return d.decodeSequence(v.Items, n, raw.Tag)
+	case *asn1parser.ASNInteger:
+		raw.Content, err = d.decodeNumber(&v.ASNEnum, n, text)
+	case *asn1parser.ASNEnumerated:
+		raw.Content, err = d.decodeNumber(&v.ASNEnum, n, text)
+	case *asn1parser.ASNBitString:
+		raw.Content, err = decodeBits(text)
+	case *asn1parser.ASNOctetString:
+		raw.Content, err = hex.DecodeString(strings.Join(strings.Fields(text), ""))
+	case *asn1parser.ASNObjectIdentifier:
+		var oid asn1.ObjectIdentifier
+		if oid, err = asn1.ParseObjectIdentifier(text); err == nil {
+			var rv *asn1.RawValue
+			if rv, err = oid.MarshalRawValue(); err == nil {
+				raw.Content = rv.Content
+			}
+		}
+	default:
+		switch value {
+		case asn1.TagBoolean:
+			switch d.valueName(n) {
+			case "true":
+				raw.Content = []byte{0xff}
+			case "false":
+				raw.Content = []byte{0x00}
+			default:
+				err = fmt.Errorf("xer: invalid BOOLEAN value %s", d.valueName(n))
+			}
+		case asn1.TagNull:
+			raw.Content = []byte{}
+		case asn1.TagReal:
+			err = fmt.Errorf("xer: unsupported type %s", builtin.Name())
+		default:
+			raw.Content = []byte(n.text)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return raw, nil
+}
+
+// valueName returns the name of the empty element of a value, eg. <true/>.
+// The textual form is accepted as well.
+func (d *decoder) valueName(n *node) string {
+	if len(n.children) == 1 {
+		return n.children[0].name
+	}
+
+	return strings.TrimSpace(n.text)
+}
+
+// decodeNumber decodes an INTEGER or ENUMERATED value, which is either a
+// number or the name of a value.
+func (d *decoder) decodeNumber(e *asn1parser.ASNEnum, n *node, text string) ([]byte, error) {
+	if v, ok := new(big.Int).SetString(text, 10); ok && len(n.children) == 0 {
+		return asn1.EncodeInteger(v), nil
+	}
+
+	_, values, err := e.Numbers()
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := values[d.valueName(n)]
+	if !ok {
+		return nil, fmt.Errorf("xer: invalid value %s of %s", d.valueName(n), n.name)
+	}
+
+	return asn1.EncodeInteger(big.NewInt(v)), nil
+}
+
+// decodeBits decodes the content octets of a BIT STRING from the bits in
+// text.
+func decodeBits(text string) ([]byte, error) {
+	bits := strings.Join(strings.Fields(text), "")
+
+	data := make([]byte, 1+(len(bits)+7)/8)
+	data[0] = byte((8 - len(bits)%8) % 8)
+
+	for i, ch := range bits {
+		switch ch {
+		case '0':
+		case '1':
+			data[1+i/8] |= 0x80 >> uint(i%8)
+		default:
+			return nil, fmt.Errorf("xer: invalid bit %q", ch)
+		}
+	}
+
+	return data, nil
+}
+
+// decodeSequence decodes the components of a SEQUENCE or SET.
+func (d *decoder) decodeSequence(items []asn1parser.ASNItem, n *node, tag asn1.ASNTag) (*asn1.RawValue, error) {
+	values := map[int]*asn1.RawValue{}
+
+	for _, child := range n.children {
+		found := false
+
+		for i, item := range items {
+			if item.TripleDot || item.Name != child.name {
+				continue
+			}
+
+			if values[i] != nil {
+				return nil, fmt.Errorf("xer: duplicate component %s", child.name)
+			}
+
+			value, err := d.decodeItem(items, i, child)
+			if err != nil {
+				return nil, err
+			}
+
+			values[i] = value
+			found = true
+			break
+		}
+
+		if !found {
+			return nil, fmt.Errorf("xer: unknown component %s of %s", child.name, n.name)
+		}
+	}
+
+	root, _, _ := d.def.Components(items)
+	for _, i := range root {
+		if values[i] == nil && !items[i].Optional && items[i].Default == nil {
+			return nil, fmt.Errorf("xer: missing component %s of %s", items[i].Name, n.name)
+		}
+	}
+
+	b := asn1.NewBuilder(tag)
+	for i := range items {
+		if values[i] != nil {
+			b.Add(values[i])
+		}
+	}
+
+	return b.RawValue()
+}
+
+// decodeSequenceOf decodes the components of a SEQUENCE OF.
+func (d *decoder) decodeSequenceOf(s *asn1parser.ASNSequence, n *node) (*asn1.RawValue, error) {
+	element := d.def.ElementType(s)
+	valueList := isValueList(d.def.Resolve(element))
+
+	b := asn1.NewBuilder(asn1.Tag(asn1.ClassUniversal, asn1.TagSequence))
+
+	for _, child := range n.children {
+		if valueList {
+			// the element is the value itself
+			child = &node{
+				name:     n.name,
+				children: []*node{child},
+			}
+		} else if child.name != elementName(s) {
+			return nil, fmt.Errorf("xer: found element %s, expected %s", child.name, elementName(s))
+		}
+
+		value, err := d.decodeType(element, child)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Add(value)
+	}
+
+	return b.RawValue()
+}
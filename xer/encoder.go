@@ -0,0 +1,235 @@
+package asn1xer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// encoder writes the XER encoding of BER encoded values.
+type encoder struct {
+	*Codec
+
+	buf bytes.Buffer
+}
+
+// newline starts a new line indented for depth in BASIC-XER.
+func (e *encoder) newline(depth int) {
+	if !e.canonical {
+		e.buf.WriteString("\n" + strings.Repeat("  ", depth))
+	}
+}
+
+// encodeElement writes raw, a value of type t with the tags of t removed, as
+// the element name.
+func (e *encoder) encodeElement(name string, t asn1parser.ASNType, raw *asn1.RawValue, depth int) error {
+	content := &encoder{
+		Codec: e.Codec,
+	}
+
+	nested, err := content.encodeContent(t, raw, depth)
+	if err != nil {
+		return err
+	}
+
+	if content.buf.Len() == 0 {
+		e.buf.WriteString("<" + name + "/>")
+		return nil
+	}
+
+	e.buf.WriteString("<" + name + ">")
+	e.buf.Write(content.buf.Bytes())
+
+	if nested {
+		e.newline(depth)
+	}
+
+	e.buf.WriteString("</" + name + ">")
+	return nil
+}
+
+// encodeItem writes raw, the value of the i-th item of a SEQUENCE, SET or
+// CHOICE.
+func (e *encoder) encodeItem(items []asn1parser.ASNItem, i int, raw *asn1.RawValue, depth int) error {
+	raw, err := e.def.UntagItem(items, i, raw)
+	if err != nil {
+		return fmt.Errorf("xer: %s", err)
+	}
+
+	return e.encodeElement(items[i].Name, items[i].Type, raw, depth)
+}
+
+// encodeContent writes the content of the element of raw, a value of type t
+// with the tags of t removed. Nested is set when the content consists of
+// child elements.
+func (e *encoder) encodeContent(t asn1parser.ASNType, raw *asn1.RawValue, depth int) (nested bool, err error) {
+	builtin := e.def.Resolve(t)
+
+	switch v := builtin.(type) {
+	case *asn1parser.ASNSequence:
+		if v.Of != "" {
+			return true, e.encodeSequenceOf(v, raw, depth)
+		}
+
+		return true, e.encodeSequence(v.Items, raw, false, depth)
+	case *asn1parser.ASNSet:
+		return true, e.encodeSequence(v.Items, raw, true, depth)
+	case *asn1parser.ASNChoice:
+		i, ok := e.def.MatchAlternative(v.Items, raw.Tag)
+		if !ok {
+			return false, fmt.Errorf("xer: no alternative of %s matches %s", v.Name(), raw.Tag)
+		}
+
+		e.newline(depth + 1)
+		return true, e.encodeItem(v.Items, i, raw, depth+1)
+	case *asn1parser.ASNInteger:
+		n, err := asn1.ParseInteger(raw.Content)
+		if err != nil {
+			return false, err
+		}
+
+		e.buf.WriteString(n.String())
+	case *asn1parser.ASNEnumerated:
+		n, err := asn1.ParseInteger(raw.Content)
+		if err != nil {
+			return false, err
+		}
+
+		name, ok, err := v.NameOf(n.Int64())
+		if err != nil {
+			return false, err
+		}
+
+		if !ok || !n.IsInt64() {
+			return false, fmt.Errorf("xer: unknown enumeration value %s", n)
+		}
+
+		e.buf.WriteString("<" + name + "/>")
+	case *asn1parser.ASNBitString:
+		if len(raw.Content) == 0 {
+			return false, fmt.Errorf("xer: zero length BIT STRING")
+		}
+
+		n := (len(raw.Content)-1)*8 - int(raw.Content[0])
+		for i := 0; i < n; i++ {
+			if raw.Content[1+i/8]&(0x80>>uint(i%8)) != 0 {
+				e.buf.WriteByte('1')
+			} else {
+				e.buf.WriteByte('0')
+			}
+		}
+	case *asn1parser.ASNOctetString:
+		e.buf.WriteString(strings.ToUpper(hex.EncodeToString(raw.Content)))
+	case *asn1parser.ASNObjectIdentifier:
+		oid := asn1.ObjectIdentifier{}
+		if err := oid.UnmarshalRawValue(raw); err != nil {
+			return false, err
+		}
+
+		e.buf.WriteString(oid.String())
+	case *asn1parser.ASNCustom:
+		switch v.Type {
+		case "BOOLEAN":
+			if len(raw.Content) != 1 {
+				return false, fmt.Errorf("xer: invalid BOOLEAN length: %d", len(raw.Content))
+			}
+
+			if raw.Content[0] != 0x00 {
+				e.buf.WriteString("<true/>")
+			} else {
+				e.buf.WriteString("<false/>")
+			}
+		case "NULL":
+		case "UTF8String", "IA5String":
+			return false, xml.EscapeText(&e.buf, raw.Content)
+		default:
+			return false, fmt.Errorf("xer: unsupported type %s", builtin.Name())
+		}
+	case *asn1parser.ASNPrintableString, *asn1parser.ASNNumericString, *asn1parser.ASNVisibleString,
+		*asn1parser.ASNGraphicString, *asn1parser.ASNGeneralString, *asn1parser.ASNT61String,
+		*asn1parser.ASNObjectDescriptor, *asn1parser.ASNUTCTime, *asn1parser.ASNGeneralizedTime:
+		return false, xml.EscapeText(&e.buf, raw.Content)
+	default:
+		return false, fmt.Errorf("xer: unsupported type %s", builtin.Name())
+	}
+
+	return false, nil
+}
+
+// encodeSequence writes the components of a SEQUENCE or SET.
+func (e *encoder) encodeSequence(items []asn1parser.ASNItem, raw *asn1.RawValue, set bool, depth int) error {
+	values, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	present, err := e.def.MatchItems(items, values, set)
+	if err != nil {
+		return fmt.Errorf("xer: %s", err)
+	}
+
+	order := []int{}
+	for i := range items {
+		if present[i] != nil {
+			order = append(order, i)
+		}
+	}
+
+	// CANONICAL-XER orders the components of a SET by their tags
+	if set && e.canonical {
+		e.def.SortByTag(items, order)
+	}
+
+	for _, i := range order {
+		e.newline(depth + 1)
+
+		if err := e.encodeItem(items, i, present[i], depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeSequenceOf writes the components of a SEQUENCE OF.
+func (e *encoder) encodeSequenceOf(s *asn1parser.ASNSequence, raw *asn1.RawValue, depth int) error {
+	values, err := raw.ChildValues()
+	if err != nil {
+		return err
+	}
+
+	element := e.def.ElementType(s)
+
+	for _, value := range values {
+		value, err := e.def.UntagType(element, value)
+		if err != nil {
+			return fmt.Errorf("xer: %s", err)
+		}
+
+		if !isValueList(e.def.Resolve(element)) {
+			e.newline(depth + 1)
+
+			if err := e.encodeElement(elementName(s), element, value, depth+1); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		// the content of a choice starts on a new line already
+		if _, ok := e.def.Resolve(element).(*asn1parser.ASNChoice); !ok {
+			e.newline(depth + 1)
+		}
+
+		if _, err := e.encodeContent(element, value, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
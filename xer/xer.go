@@ -0,0 +1,120 @@
+// Package asn1xer implements the XML Encoding Rules (X.693). The codec
+// transcodes between XER documents and the BER encoded RawValue used by the
+// asn1 package, using the types of a parsed asn1parser definition for the
+// element names.
+package asn1xer
+
+import (
+	"fmt"
+	"strings"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+)
+
+// Variant is the variant of the XML Encoding Rules.
+type Variant int
+
+const (
+	// Basic is BASIC-XER, the document is indented for readability.
+	Basic Variant = iota
+	// Canonical is CANONICAL-XER, the document contains no white space
+	// and DEFAULT values are omitted.
+	Canonical
+)
+
+// Codec encodes and decodes values of the types of a definition.
+type Codec struct {
+	def       *asn1parser.ASNDefinition
+	canonical bool
+}
+
+// NewCodec returns a new Codec for the types of def.
+func NewCodec(def *asn1parser.ASNDefinition, variant Variant) *Codec {
+	return &Codec{
+		def:       def,
+		canonical: variant == Canonical,
+	}
+}
+
+// Encode returns the XER document of raw, a BER encoded value of the type
+// typeName.
+func (c *Codec) Encode(typeName string, raw *asn1.RawValue) ([]byte, error) {
+	t := c.def.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("xer: unknown type %s", typeName)
+	}
+
+	var err error
+	if c.canonical {
+		raw, err = c.def.ToDER(typeName, raw)
+	} else {
+		raw, err = asn1.ToDER(raw)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err = c.def.UntagType(t, raw)
+	if err != nil {
+		return nil, fmt.Errorf("xer: %s", err)
+	}
+
+	e := &encoder{
+		Codec: c,
+	}
+
+	if err := e.encodeElement(typeName, t, raw, 0); err != nil {
+		return nil, err
+	}
+
+	if !c.canonical {
+		e.buf.WriteString("\n")
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// Decode decodes data, a XER document with a value of the type typeName,
+// into the BER encoded value.
+func (c *Codec) Decode(typeName string, data []byte) (*asn1.RawValue, error) {
+	t := c.def.Lookup(typeName)
+	if t == nil {
+		return nil, fmt.Errorf("xer: unknown type %s", typeName)
+	}
+
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if root.name != typeName {
+		return nil, fmt.Errorf("xer: found element %s, expected %s", root.name, typeName)
+	}
+
+	d := &decoder{
+		Codec: c,
+	}
+
+	return d.decodeType(t, root)
+}
+
+// isValueList returns true when the values of the builtin type t are
+// encoded without delimiting element in a SEQUENCE OF, see X.680 25.
+func isValueList(t asn1parser.ASNType) bool {
+	switch v := t.(type) {
+	case *asn1parser.ASNEnumerated, *asn1parser.ASNChoice:
+		return true
+	case *asn1parser.ASNCustom:
+		return v.Type == "BOOLEAN"
+	}
+
+	return false
+}
+
+// elementName returns the name of the elements delimiting the components of
+// a SEQUENCE OF, this is the name of the component type.
+func elementName(s *asn1parser.ASNSequence) string {
+	return strings.Replace(s.Of, " ", "_", -1)
+}
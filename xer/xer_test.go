@@ -0,0 +1,150 @@
+package asn1xer_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	asn1 "github.com/dutchsec/asn1"
+	"github.com/dutchsec/asn1/parser"
+	"github.com/dutchsec/asn1/xer"
+)
+
+const scheme = `
+Test DEFINITIONS AUTOMATIC TAGS ::=
+BEGIN
+
+Record ::= SEQUENCE {
+	id INTEGER,
+	name UTF8String,
+	enabled BOOLEAN DEFAULT TRUE,
+	color ENUMERATED { red, green },
+	data OCTET STRING OPTIONAL,
+	flags BIT STRING,
+	tags SEQUENCE OF INTEGER,
+	choice CHOICE { a INTEGER, b NULL }
+}
+
+END
+`
+
+func TestCodec(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var tests = []struct {
+		variant asn1xer.Variant
+		ber     string
+		xer     string
+	}{
+		{
+			variant: asn1xer.Basic,
+			ber:     "302180010581026869820100830101840201ab850205a0a606020101020102a7028100",
+			xer: `<Record>
+  <id>5</id>
+  <name>hi</name>
+  <enabled><false/></enabled>
+  <color><green/></color>
+  <data>01AB</data>
+  <flags>101</flags>
+  <tags>
+    <INTEGER>1</INTEGER>
+    <INTEGER>2</INTEGER>
+  </tags>
+  <choice>
+    <b/>
+  </choice>
+</Record>
+`,
+		},
+		{
+			variant: asn1xer.Canonical,
+			ber:     "301680010581033c263e83010085020400a600a70380012a",
+			xer:     "<Record><id>5</id><name>&lt;&amp;&gt;</name><color><red/></color><flags>0000</flags><tags/><choice><a>42</a></choice></Record>",
+		},
+	}
+
+	for i, tt := range tests {
+		ber, _ := hex.DecodeString(tt.ber)
+
+		raw, err := asn1.DecodeRawValue(bytes.NewReader(ber))
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		codec := asn1xer.NewCodec(def, tt.variant)
+
+		data, err := codec.Encode("Record", raw)
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if string(data) != tt.xer {
+			t.Errorf("%d. encoding mismatch:\n  exp=%s\n  got=%s", i, tt.xer, data)
+		}
+
+		value, err := codec.Decode("Record", []byte(tt.xer))
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		out, err := value.Encode()
+		if err != nil {
+			t.Fatalf("%d. unexpected error: %s", i, err)
+		}
+
+		if !bytes.Equal(out, ber) {
+			t.Errorf("%d. decoding mismatch: exp=%x got=%x", i, ber, out)
+		}
+	}
+}
+
+func TestCodec_CanonicalDefaults(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// enabled has the DEFAULT value TRUE
+	ber, _ := hex.DecodeString("301580010581008201ff83010085020780a600a7028100")
+
+	raw, err := asn1.DecodeRawValue(bytes.NewReader(ber))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	data, err := asn1xer.NewCodec(def, asn1xer.Canonical).Encode("Record", raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if exp := "<Record><id>5</id><name/><color><red/></color><flags>1</flags><tags/><choice><b/></choice></Record>"; string(data) != exp {
+		t.Errorf("encoding mismatch:\n  exp=%s\n  got=%s", exp, data)
+	}
+}
+
+func TestCodec_DecodeErrors(t *testing.T) {
+	def, err := asn1parser.NewParser(strings.NewReader(scheme)).Parse()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	codec := asn1xer.NewCodec(def, asn1xer.Basic)
+
+	var tests = []string{
+		`<Other/>`,
+		`<Record><id>5</id></Record>`,
+		`<Record><id>x</id><name/><color><red/></color><flags/><tags/><choice><b/></choice></Record>`,
+		`<Record><unknown/></Record>`,
+		`<Record>`,
+	}
+
+	for i, tt := range tests {
+		if _, err := codec.Decode("Record", []byte(tt)); err == nil {
+			t.Errorf("%d. expected error for %s", i, tt)
+		}
+	}
+}